@@ -1,5 +1,135 @@
 package gologger
 
+import (
+	"context"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type (
+	Logger     = types.Logger
+	LogOptions = types.LogOptions
+	LogLevel   = types.LogLevel
+
+	Formatter     = types.Formatter
+	FormatterFunc = types.FormatterFunc
+)
+
+const (
+	TraceLevel = types.TraceLevel
+	DebugLevel = types.DebugLevel
+	InfoLevel  = types.InfoLevel
+	WarnLevel  = types.WarnLevel
+	ErrorLevel = types.ErrorLevel
+	FatalLevel = types.FatalLevel
+)
+
+// NewLogger creates a structured JSON logger.
+func NewLogger(options LogOptions) Logger {
+	return logger.NewLogger(options)
+}
+
+// NewLoggerChecked is NewLogger that returns an error describing invalid
+// options, such as an unknown level, instead of falling back to defaults.
+// It also rejects a Redactor with AnnotatePII set, which logs masked
+// values in clear, unless Environment is "development".
+func NewLoggerChecked(options LogOptions) (Logger, error) {
+	return logger.NewLoggerChecked(options)
+}
+
+// ECSFieldKeyMap returns a LogOptions.FieldKeyMap for the Elastic Common
+// Schema.
+func ECSFieldKeyMap() map[string]string {
+	return types.ECSFieldKeyMap()
+}
+
+// GELFFieldKeyMap returns a LogOptions.FieldKeyMap for Graylog's GELF.
+func GELFFieldKeyMap() map[string]string {
+	return types.GELFFieldKeyMap()
+}
+
+// NopLogger returns a logger that discards everything.
+func NopLogger() Logger {
+	return logger.NopLogger()
+}
+
+// NewContext returns a copy of ctx carrying log, bound to the correlation
+// ID stored in ctx.
+func NewContext(ctx context.Context, log Logger) context.Context {
+	return logger.NewContext(ctx, log)
+}
+
+// FromContext returns the logger stored in ctx, or a no-op logger when
+// none is present.
+func FromContext(ctx context.Context) Logger {
+	return logger.FromContext(ctx)
+}
+
+// SetCorrelationIDGenerator replaces the generator of the correlation IDs
+// assigned by the middlewares to requests without one. Pass UUIDv7 or
+// ULID for time-ordered IDs, or a custom function; nil restores UUIDv4,
+// the default.
+func SetCorrelationIDGenerator(gen func() string) {
+	logctx.SetCorrelationIDGenerator(gen)
+}
+
+// UUIDv4 generates random UUID v4 correlation IDs.
+func UUIDv4() string {
+	return logctx.UUIDv4()
+}
+
+// UUIDv7 generates time-ordered UUID v7 correlation IDs.
+func UUIDv7() string {
+	return logctx.UUIDv7()
+}
+
+// ULID generates time-ordered ULID correlation IDs.
+func ULID() string {
+	return logctx.ULID()
+}
+
+// Writer is an io.Writer logging every line written to it.
+type Writer = logger.Writer
+
+// NewLoggerWriter returns an io.Writer that logs each line written to it
+// at level, e.g. to capture the logs of http.Server.ErrorLog:
+//
+//	ErrorLog: log.New(gologger.NewLoggerWriter(l, gologger.ErrorLevel), "", 0)
+//
+// Close it to log a last line written without a trailing newline.
+func NewLoggerWriter(log Logger, level LogLevel) *Writer {
+	return logger.NewWriter(log, level)
+}
+
+// SugaredLogger offers zap-style printf (Infof) and key/value (Infow)
+// methods on top of a Logger.
+type SugaredLogger = logger.SugaredLogger
+
+// Sugar wraps log in a SugaredLogger.
+func Sugar(log Logger) *SugaredLogger {
+	return logger.Sugar(log)
+}
+
+// WatchLevelFile applies the level written in path to log whenever the
+// file changes. The returned function stops watching.
+func WatchLevelFile(path string, log Logger) func() {
+	return logger.WatchLevelFile(path, log)
+}
+
+// CycleLevelOnSignal advances log to the next level on every SIGUSR1.
+// The returned function stops listening.
+func CycleLevelOnSignal(log Logger) func() {
+	return logger.CycleLevelOnSignal(log)
+}
+
+// VerifySignedLines checks the signature chain of lines written by a logger
+// configured with LogOptions.SigningKey.
+func VerifySignedLines(key []byte, lines [][]byte) error {
+	return logger.VerifySignedLines(key, lines)
+}
+
 func GoLogger(name string) string {
 	result := "GoLogger " + name
 	return result
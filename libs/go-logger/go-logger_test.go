@@ -10,3 +10,10 @@ func TestGoLogger(t *testing.T) {
 		t.Error("Expected GoLogger to append 'works'")
 	}
 }
+
+func TestNopLogger(t *testing.T) {
+	log := NopLogger()
+	if log.WithFields(map[string]interface{}{"k": "v"}) != log || log.WithCorrelationID("cid") != log {
+		t.Error("Expected NopLogger children to be the logger itself")
+	}
+}
@@ -0,0 +1,132 @@
+// Package context stores and retrieves request correlation data from a
+// context.Context.
+package context
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	correlationIDKey struct{}
+	traceContextKey  struct{}
+)
+
+// traceContext identifies the trace and span a request belongs to.
+type traceContext struct {
+	traceID string
+	spanID  string
+}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// GetCorrelationID returns the correlation ID stored in ctx, or an empty
+// string when none is present.
+func GetCorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(correlationIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// MaxCorrelationIDLength is the longest correlation ID accepted from a
+// client.
+const MaxCorrelationIDLength = 128
+
+// SanitizeCorrelationID returns a correlation ID received from a client
+// without its surrounding whitespace, or "" when it is longer than
+// MaxCorrelationIDLength or holds control characters such as CR or LF, so
+// a forged header can neither inject lines into text logs nor bloat every
+// line of the request.
+func SanitizeCorrelationID(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) > MaxCorrelationIDLength {
+		return ""
+	}
+	for _, r := range id {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return ""
+		}
+	}
+	return id
+}
+
+// generator holds the func() string used by GenerateCorrelationID.
+var generator atomic.Value
+
+func init() {
+	generator.Store(UUIDv4)
+}
+
+// GenerateCorrelationID returns a new correlation ID from the generator set
+// with SetCorrelationIDGenerator, a random UUID v4 by default.
+func GenerateCorrelationID() string {
+	return generator.Load().(func() string)()
+}
+
+// SetCorrelationIDGenerator replaces the generator used by
+// GenerateCorrelationID, and so by every middleware, e.g. with UUIDv7 or
+// ULID for time-ordered IDs. A nil gen restores UUIDv4. It is safe to call
+// concurrently with GenerateCorrelationID.
+func SetCorrelationIDGenerator(gen func() string) {
+	if gen == nil {
+		gen = UUIDv4
+	}
+	generator.Store(gen)
+}
+
+// UUIDv4 returns a random UUID v4.
+func UUIDv4() string {
+	return uuid.New().String()
+}
+
+// UUIDv7 returns a UUID v7, ordered by creation time. It falls back to a
+// UUID v4 if the random source fails.
+func UUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return UUIDv4()
+	}
+	return id.String()
+}
+
+// ULID returns a ULID, a 26 character identifier ordered by creation time
+// and strictly increasing within the same millisecond.
+func ULID() string {
+	return ulid.Make().String()
+}
+
+// WithTraceContext returns a copy of ctx carrying the given trace and span
+// IDs, for tracers other than OpenTelemetry or IDs received out of band.
+func WithTraceContext(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext{traceID: traceID, spanID: spanID})
+}
+
+// GetTraceContext returns the trace and span IDs stored in ctx with
+// WithTraceContext or, failing that, those of the OpenTelemetry span in
+// ctx. Both are empty when ctx carries no trace information.
+func GetTraceContext(ctx context.Context) (traceID, spanID string) {
+	if ctx == nil {
+		return "", ""
+	}
+	if tc, ok := ctx.Value(traceContextKey{}).(traceContext); ok {
+		return tc.traceID, tc.spanID
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String(), sc.SpanID().String()
+	}
+	return "", ""
+}
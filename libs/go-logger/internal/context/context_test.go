@@ -0,0 +1,121 @@
+package context
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	ctx := WithCorrelationID(context.Background(), "abc-123")
+	if got := GetCorrelationID(ctx); got != "abc-123" {
+		t.Errorf("Expected correlation ID 'abc-123', got '%s'", got)
+	}
+}
+
+func TestSanitizeCorrelationID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"valid", "abc-123", "abc-123"},
+		{"trimmed", "  abc-123\t", "abc-123"},
+		{"CRLF", "abc\r\nlevel=error msg=forged", ""},
+		{"control", "abc\x00", ""},
+		{"invalid UTF-8", "abc\xff", ""},
+		{"max length", strings.Repeat("a", MaxCorrelationIDLength), strings.Repeat("a", MaxCorrelationIDLength)},
+		{"oversized", strings.Repeat("a", MaxCorrelationIDLength+1), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeCorrelationID(tt.id); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetCorrelationIDMissing(t *testing.T) {
+	if got := GetCorrelationID(context.Background()); got != "" {
+		t.Errorf("Expected empty correlation ID, got '%s'", got)
+	}
+	if got := GetCorrelationID(nil); got != "" {
+		t.Errorf("Expected empty correlation ID for nil context, got '%s'", got)
+	}
+}
+
+func TestGenerateCorrelationID(t *testing.T) {
+	first := GenerateCorrelationID()
+	second := GenerateCorrelationID()
+	if first == "" || second == "" {
+		t.Fatal("Expected non-empty correlation IDs")
+	}
+	if first == second {
+		t.Error("Expected generated correlation IDs to be unique")
+	}
+}
+
+func TestSetCorrelationIDGenerator(t *testing.T) {
+	defer SetCorrelationIDGenerator(nil)
+
+	SetCorrelationIDGenerator(func() string { return "custom" })
+	if got := GenerateCorrelationID(); got != "custom" {
+		t.Errorf("Expected the custom generator to be used, got '%s'", got)
+	}
+
+	SetCorrelationIDGenerator(UUIDv7)
+	if id, err := uuid.Parse(GenerateCorrelationID()); err != nil || id.Version() != 7 {
+		t.Errorf("Expected a UUID v7, got %v (%v)", id, err)
+	}
+
+	SetCorrelationIDGenerator(ULID)
+	first, second := GenerateCorrelationID(), GenerateCorrelationID()
+	if len(first) != 26 || first >= second {
+		t.Errorf("Expected increasing ULIDs, got '%s' then '%s'", first, second)
+	}
+
+	SetCorrelationIDGenerator(nil)
+	if id, err := uuid.Parse(GenerateCorrelationID()); err != nil || id.Version() != 4 {
+		t.Errorf("Expected the default UUID v4 back, got %v (%v)", id, err)
+	}
+}
+
+func TestWithTraceContext(t *testing.T) {
+	ctx := WithTraceContext(context.Background(), "trace-1", "span-1")
+	traceID, spanID := GetTraceContext(ctx)
+	if traceID != "trace-1" || spanID != "span-1" {
+		t.Errorf("Expected 'trace-1'/'span-1', got '%s'/'%s'", traceID, spanID)
+	}
+}
+
+func TestGetTraceContextFromOpenTelemetry(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	traceID, spanID := GetTraceContext(ctx)
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || spanID != "00f067aa0ba902b7" {
+		t.Errorf("Expected OpenTelemetry IDs, got '%s'/'%s'", traceID, spanID)
+	}
+
+	traceID, _ = GetTraceContext(WithTraceContext(ctx, "explicit", "span"))
+	if traceID != "explicit" {
+		t.Errorf("Expected explicit trace context to win, got '%s'", traceID)
+	}
+}
+
+func TestGetTraceContextMissing(t *testing.T) {
+	if traceID, spanID := GetTraceContext(context.Background()); traceID != "" || spanID != "" {
+		t.Errorf("Expected empty trace context, got '%s'/'%s'", traceID, spanID)
+	}
+	if traceID, spanID := GetTraceContext(nil); traceID != "" || spanID != "" {
+		t.Errorf("Expected empty trace context for nil context, got '%s'/'%s'", traceID, spanID)
+	}
+}
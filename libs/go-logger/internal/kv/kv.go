@@ -0,0 +1,49 @@
+// Package kv implements the slog-style key/value variant of WithFields
+// shared by the Logger implementations.
+package kv
+
+import (
+	"fmt"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// DanglingKey is the field reporting a trailing key given without a value.
+const DanglingKey = "with.dangling"
+
+// With returns log.WithFields with the fields given as alternating keys
+// and values. Keys that are not strings are converted with fmt.Sprint. A
+// trailing key without a value is dropped and reported with a warning
+// on log instead of panicking.
+func With(log types.Logger, args []interface{}) types.Logger {
+	fields, dangling, ok := Fields(args)
+	if ok {
+		log.Warn("With called with an odd number of arguments, ignoring the last one", map[string]interface{}{
+			DanglingKey: dangling,
+		})
+	}
+	return log.WithFields(fields)
+}
+
+// Fields converts alternating keys and values to a field map, nil when
+// there are none. Keys that are not strings are converted with fmt.Sprint.
+// A trailing key without a value is left out and returned as dangling,
+// with ok set.
+func Fields(args []interface{}) (fields map[string]interface{}, dangling interface{}, ok bool) {
+	if len(args)%2 != 0 {
+		dangling, ok = args[len(args)-1], true
+		args = args[:len(args)-1]
+	}
+	if len(args) == 0 {
+		return nil, dangling, ok
+	}
+	fields = make(map[string]interface{}, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		key, isString := args[i].(string)
+		if !isString {
+			key = fmt.Sprint(args[i])
+		}
+		fields[key] = args[i+1]
+	}
+	return fields, dangling, ok
+}
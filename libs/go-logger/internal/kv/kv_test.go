@@ -0,0 +1,50 @@
+package kv_test
+
+import (
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/internal/kv"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/testlogger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestWith(t *testing.T) {
+	rec, log := testlogger.New()
+
+	kv.With(log, []interface{}{"orderId", 42, 7, "seven"}).Info("done")
+
+	entry, _ := rec.LastEntry()
+	if entry.Fields["orderId"] != 42 {
+		t.Errorf("Expected orderId 42, got %v", entry.Fields["orderId"])
+	}
+	if entry.Fields["7"] != "seven" {
+		t.Errorf("Expected non-string key to be stringified, got %v", entry.Fields)
+	}
+}
+
+func TestWithOddArguments(t *testing.T) {
+	rec, log := testlogger.New()
+
+	kv.With(log, []interface{}{"orderId", 42, "status"}).Info("done")
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected a warning and the line, got %d entries", len(entries))
+	}
+	if entries[0].Level != types.WarnLevel || entries[0].Fields["with.dangling"] != "status" {
+		t.Errorf("Expected a warning naming the dangling argument, got %+v", entries[0])
+	}
+	if _, ok := entries[1].Fields["status"]; ok || entries[1].Fields["orderId"] != 42 {
+		t.Errorf("Expected only the complete pair, got %v", entries[1].Fields)
+	}
+}
+
+func TestWithNoArguments(t *testing.T) {
+	_, log := testlogger.New()
+
+	child := kv.With(log, nil)
+	child.SetLevel(types.ErrorLevel)
+	if child == log || log.GetLevel() != types.TraceLevel {
+		t.Errorf("Expected a derived logger leaving the parent level alone, got '%s'", log.GetLevel())
+	}
+}
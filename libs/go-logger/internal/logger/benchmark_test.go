@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type benchPayload struct {
+	ID       int    `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// newBenchLogger returns a logger that discards its output.
+func newBenchLogger(options types.LogOptions) *logger {
+	options.Output = io.Discard
+	return NewLogger(options).(*logger)
+}
+
+func BenchmarkLogger(b *testing.B) {
+	l := newBenchLogger(types.LogOptions{Service: "bench"})
+	fields := map[string]interface{}{"orderId": 42, "status": "paid"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("order processed", fields)
+	}
+}
+
+func BenchmarkLoggerJSONBackend(b *testing.B) {
+	l := newBenchLogger(types.LogOptions{Service: "bench", Backend: types.JSONBackend})
+	fields := map[string]interface{}{"orderId": 42, "status": "paid"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("order processed", fields)
+	}
+}
+
+func BenchmarkLoggerDisableRedaction(b *testing.B) {
+	l := newBenchLogger(types.LogOptions{Service: "bench", DisableRedaction: true})
+	fields := map[string]interface{}{"orderId": 42, "status": "paid"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("order processed", fields)
+	}
+}
+
+func BenchmarkLoggerWithRedaction(b *testing.B) {
+	l := newBenchLogger(types.LogOptions{Service: "bench"})
+	fields := map[string]interface{}{
+		"user": benchPayload{ID: 1, Email: "joao@example.com", Password: "hunter2"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("user logged in", fields)
+	}
+}
+
+func BenchmarkLoggerWithRedactionJSONBackend(b *testing.B) {
+	l := newBenchLogger(types.LogOptions{Service: "bench", Backend: types.JSONBackend})
+	fields := map[string]interface{}{
+		"user": benchPayload{ID: 1, Email: "joao@example.com", Password: "hunter2"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("user logged in", fields)
+	}
+}
+
+func BenchmarkLoggerDisabledLevel(b *testing.B) {
+	l := newBenchLogger(types.LogOptions{Service: "bench", Level: types.InfoLevel})
+	fields := map[string]interface{}{"orderId": 42, "status": "paid"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Trace("order processed", fields)
+	}
+}
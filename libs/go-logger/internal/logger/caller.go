@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"runtime"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// callerSkip is the number of frames between runtime.Callers and the user
+// code: runtime.Callers, callerScope, logger.write, logger.log and the
+// public logging method (Info, LogExternalCall, ...).
+const callerSkip = 5
+
+// callerScope describes the code that called the public logging method,
+// or its skip-th caller to leave out wrappers such as SugaredLogger.
+func callerScope(skip int) *types.LogScope {
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(callerSkip+skip, pcs) == 0 {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	return &types.LogScope{
+		MethodName: frame.Function,
+		File:       frame.File,
+		Line:       frame.Line,
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestLoggerIncludeCaller(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{IncludeCaller: true})
+
+	_, file, line, _ := runtime.Caller(0)
+	l.WithFields(map[string]interface{}{"k": "v"}).Info("here")
+	l.LogExternalCall("crm", "get", 200, time.Millisecond, nil)
+
+	lines := decodeLines(t, buf)
+	for i, want := range []int{line + 1, line + 2} {
+		scope, ok := lines[i]["scope"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected scope field, got %v", lines[i])
+		}
+		if scope["file"] != file || scope["line"] != float64(want) {
+			t.Errorf("Expected caller %s:%d, got %v:%v", file, want, scope["file"], scope["line"])
+		}
+		if method, _ := scope["methodName"].(string); !strings.HasSuffix(method, ".TestLoggerIncludeCaller") {
+			t.Errorf("Expected methodName of the test, got %v", scope["methodName"])
+		}
+	}
+}
+
+func TestLoggerIncludeCallerCtx(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{IncludeCaller: true, Level: types.DebugLevel})
+	ctx := context.Background()
+
+	_, file, line, _ := runtime.Caller(0)
+	l.DebugCtx(ctx, "debug")
+	l.InfoCtx(ctx, "info")
+	l.WarnCtx(ctx, "warn")
+	l.ErrorCtx(ctx, "error")
+
+	for i, got := range decodeLines(t, buf) {
+		scope, _ := got["scope"].(map[string]interface{})
+		if scope["file"] != file || scope["line"] != float64(line+1+i) {
+			t.Errorf("Expected caller %s:%d, got %v", file, line+1+i, scope)
+		}
+	}
+}
+
+func TestLoggerIncludeCallerToSink(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{IncludeCaller: true, Sink: sink})
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Warn("here")
+
+	scope := sink.entries[0].Scope
+	if scope.File != file || scope.Line != line+1 {
+		t.Errorf("Expected caller %s:%d, got %s:%d", file, line+1, scope.File, scope.Line)
+	}
+}
+
+func TestLoggerCallerOffByDefault(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.Info("here")
+
+	if _, ok := lastLine(t, buf)["scope"]; ok {
+		t.Error("Expected no scope field unless IncludeCaller is set")
+	}
+}
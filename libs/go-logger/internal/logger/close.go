@@ -0,0 +1,27 @@
+package logger
+
+import "sync"
+
+// closer runs the shutdown of a logger tree once and remembers its result.
+type closer struct {
+	once sync.Once
+	err  error
+}
+
+// flusher is implemented by buffered outputs such as bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+func (l *logger) Close() error {
+	l.closer.once.Do(func() {
+		if l.options.Sink != nil {
+			l.closer.err = l.options.Sink.Close()
+			return
+		}
+		if f, ok := l.options.Output.(flusher); ok {
+			l.closer.err = f.Flush()
+		}
+	})
+	return l.closer.err
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/sinks"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type closingSink struct {
+	captureSink
+	closed int
+	err    error
+}
+
+func (s *closingSink) Close() error {
+	s.closed++
+	return s.err
+}
+
+func TestLoggerCloseClosesSinkOnce(t *testing.T) {
+	sink := &closingSink{err: errors.New("flush failed")}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink})
+	child := l.WithFields(map[string]interface{}{"k": "v"})
+
+	if err := child.Close(); err != sink.err {
+		t.Errorf("Expected the sink error, got %v", err)
+	}
+	if err := l.Close(); err != sink.err || sink.closed != 1 {
+		t.Errorf("Expected the sink to be closed once, got %d closes and %v", sink.closed, err)
+	}
+}
+
+func TestLoggerCloseFlushesOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := bufio.NewWriter(buf)
+	l := NewLogger(types.LogOptions{Output: out})
+
+	l.Info("buffered")
+	if buf.Len() != 0 {
+		t.Fatal("Expected the line to be buffered")
+	}
+	if err := l.Close(); err != nil || buf.Len() == 0 {
+		t.Errorf("Expected Close to flush the output, got %v and %q", err, buf.String())
+	}
+	if err := NewLogger(types.LogOptions{Output: &bytes.Buffer{}}).Close(); err != nil {
+		t.Errorf("Expected no error for a plain output, got %v", err)
+	}
+}
+
+func TestLoggerFatalDrainsSinkBeforeExit(t *testing.T) {
+	inner := &captureSink{}
+	sink := sinks.NewAsyncSink(inner, types.SinkOptions{BufferSize: 100, FlushInterval: time.Hour})
+	l, _ := newTestLogger(types.LogOptions{Sink: sink})
+	var delivered int
+	l.entry.Logger.ExitFunc = func(code int) {
+		delivered = len(inner.entries)
+		if code != 1 {
+			t.Errorf("Expected exit code 1, got %d", code)
+		}
+	}
+
+	l.WithFields(map[string]interface{}{"k": "v"}).Fatal("shutting down")
+
+	if delivered != 1 {
+		t.Errorf("Expected the fatal entry delivered before exiting, got %d entries", delivered)
+	}
+}
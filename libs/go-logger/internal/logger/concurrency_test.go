@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestLoggerConcurrentChildren(t *testing.T) {
+	base, buf := newTestLogger(types.LogOptions{
+		Service:          "api",
+		RedactionSummary: true,
+		SigningKey:       []byte("key"),
+	})
+	shared := base.WithFields(map[string]interface{}{"component": "worker"}).WithPrefix("job")
+
+	const goroutines, iterations = 16, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				child := shared.WithFields(map[string]interface{}{"worker": g, "password": "secret"})
+				child.WithCorrelationID(fmt.Sprintf("cid-%d", g)).
+					WithError(errors.New("retry")).
+					WithPrefix("step").
+					Info("tick", map[string]interface{}{"i": i})
+				base.Info("base", map[string]interface{}{"worker": g})
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2*goroutines*iterations {
+		t.Fatalf("Expected %d lines, got %d", 2*goroutines*iterations, len(lines))
+	}
+	for _, line := range lines {
+		if line["message"] == "base" {
+			if _, ok := line["job.worker"]; ok {
+				t.Fatalf("Expected base logger to be unaffected by children, got %v", line)
+			}
+			continue
+		}
+		if line["job.password"] != "***" || line["component"] != "worker" {
+			t.Fatalf("Expected child fields, got %v", line)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying log. The stored logger is bound
+// to the correlation ID found in ctx, so handlers retrieving it with
+// FromContext log with the request's ID.
+func NewContext(ctx context.Context, log types.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log.WithContext(ctx))
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or a logger
+// that discards everything when none is present.
+func FromContext(ctx context.Context) types.Logger {
+	if ctx != nil {
+		if log, ok := ctx.Value(loggerKey{}).(types.Logger); ok {
+			return log
+		}
+	}
+	return nopLogger{}
+}
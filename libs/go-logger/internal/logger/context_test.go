@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestNewContextRoundTrip(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-42")
+	ctx = NewContext(ctx, l.WithFields(map[string]interface{}{"handler": "orders"}))
+
+	FromContext(ctx).Info("handled")
+
+	line := lastLine(t, buf)
+	if line["correlationId"] != "cid-42" {
+		t.Errorf("Expected correlationId 'cid-42', got %v", line["correlationId"])
+	}
+	if line["handler"] != "orders" {
+		t.Errorf("Expected handler field, got %v", line["handler"])
+	}
+}
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	log := FromContext(context.Background())
+	if log == nil {
+		t.Fatal("Expected a no-op logger, got nil")
+	}
+	if _, ok := log.(nopLogger); !ok {
+		t.Errorf("Expected nopLogger, got %T", log)
+	}
+
+	// Must not panic.
+	log.WithFields(map[string]interface{}{"a": 1}).Info("ignored")
+	if FromContext(nil) == nil {
+		t.Error("Expected a no-op logger for a nil context")
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// newLogError describes err as a types.LogError, with the errors it wraps
+// as its causes. The stack is taken from a StackTrace method when err has
+// one, as errors from github.com/pkg/errors do; a cause repeating the
+// stack of the error wrapping it leaves it out.
+func newLogError(err error) *types.LogError {
+	out := &types.LogError{
+		Name:    fmt.Sprintf("%T", err),
+		Message: err.Error(),
+		Stack:   stackTrace(err),
+	}
+	last := out
+	for i := 0; i < types.MaxErrorCauses; i++ {
+		if err = errors.Unwrap(err); err == nil {
+			break
+		}
+		cause := &types.LogError{Name: fmt.Sprintf("%T", err), Message: err.Error()}
+		if stack := stackTrace(err); stack != last.Stack {
+			cause.Stack = stack
+		}
+		last.Cause, last = cause, cause
+	}
+	return out
+}
+
+// stackTrace renders the result of err's StackTrace method, if any. The
+// method is looked up by name because its return type differs between
+// error packages.
+func stackTrace(err error) string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+	trace := method.Call(nil)[0].Interface()
+	if s, ok := trace.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%+v", trace)
+}
+
+// redactError returns a copy of the attached error with the name, message
+// and stack of it and its causes passed through the redactor, or nil when
+// no error is attached.
+// Error strings often embed query parameters or URLs with credentials, so
+// they get the same pattern rules as field values.
+func (l *logger) redactError() *types.LogError {
+	if l.err == nil {
+		return nil
+	}
+	return l.redactLogError(l.err)
+}
+
+// redactLogError returns a redacted copy of e and of its causes.
+func (l *logger) redactLogError(e *types.LogError) *types.LogError {
+	out := *e
+	out.Name = l.redactText(out.Name)
+	out.Message = l.redactText(out.Message)
+	out.Stack = l.redactText(out.Stack)
+	if out.Cause != nil {
+		out.Cause = l.redactLogError(out.Cause)
+	}
+	return &out
+}
+
+// redactText redacts a single string, keeping the original type when the
+// redactor returns something else.
+func (l *logger) redactText(s string) string {
+	if s == "" || l.skipRedaction {
+		return s
+	}
+	if redacted, ok := l.options.Redactor.Redact(s).(string); ok {
+		return redacted
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type tracedError struct{}
+
+func (tracedError) Error() string      { return "traced" }
+func (tracedError) StackTrace() string { return "main.go:10" }
+
+type frames []string
+
+type framedError struct{}
+
+func (framedError) Error() string      { return "framed" }
+func (framedError) StackTrace() frames { return frames{"a.go:1", "b.go:2"} }
+
+func TestNewLogError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want types.LogError
+	}{
+		{"plain", errors.New("boom"), types.LogError{Name: "*errors.errorString", Message: "boom"}},
+		{"string stack", tracedError{}, types.LogError{Name: "logger.tracedError", Message: "traced", Stack: "main.go:10"}},
+		{"typed stack", framedError{}, types.LogError{Name: "logger.framedError", Message: "framed", Stack: "[a.go:1 b.go:2]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLogError(tt.err); *got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, *got)
+			}
+		})
+	}
+}
+
+func TestNewLogErrorCauses(t *testing.T) {
+	err := fmt.Errorf("placing order: %w", fmt.Errorf("charging card: %w", tracedError{}))
+
+	got := newLogError(err)
+
+	want := []types.LogError{
+		{Name: "*fmt.wrapError", Message: "placing order: charging card: traced"},
+		{Name: "*fmt.wrapError", Message: "charging card: traced"},
+		{Name: "logger.tracedError", Message: "traced", Stack: "main.go:10"},
+	}
+	for i, w := range want {
+		if got == nil {
+			t.Fatalf("Expected cause %d, got none", i)
+		}
+		if got.Name != w.Name || got.Message != w.Message || got.Stack != w.Stack {
+			t.Errorf("Expected cause %d to be %+v, got %+v", i, w, *got)
+		}
+		got = got.Cause
+	}
+	if got != nil {
+		t.Errorf("Expected the chain to end, got %+v", *got)
+	}
+}
+
+func TestNewLogErrorBoundsCauses(t *testing.T) {
+	err := errors.New("root")
+	for i := 0; i < 2*types.MaxErrorCauses; i++ {
+		err = fmt.Errorf("wrap: %w", err)
+	}
+
+	depth := 0
+	for e := newLogError(err).Cause; e != nil; e = e.Cause {
+		depth++
+	}
+	if depth != types.MaxErrorCauses {
+		t.Errorf("Expected %d causes, got %d", types.MaxErrorCauses, depth)
+	}
+}
+
+func TestLoggerWithErrorCauses(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithError(fmt.Errorf("notifying: %w", errors.New("no route to joao@example.com"))).Error("failed")
+
+	logErr := lastLine(t, buf)["error"].(map[string]interface{})
+	cause, ok := logErr["cause"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a nested cause, got %v", logErr)
+	}
+	if cause["name"] != "*errors.errorString" || cause["message"] != "no route to ***" {
+		t.Errorf("Expected the redacted cause, got %v", cause)
+	}
+	if _, ok := cause["cause"]; ok {
+		t.Errorf("Expected the chain to end, got %v", cause)
+	}
+}
+
+func TestLoggerWithError(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithError(errors.New("cannot notify joao@example.com")).Error("failed")
+	l.WithError(nil).Info("no error")
+
+	lines := decodeLines(t, buf)
+	logErr, ok := lines[0]["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected structured error, got %v", lines[0]["error"])
+	}
+	if logErr["name"] != "*errors.errorString" {
+		t.Errorf("Expected name '*errors.errorString', got %v", logErr["name"])
+	}
+	if logErr["message"] != "cannot notify ***" {
+		t.Errorf("Expected redacted message, got %v", logErr["message"])
+	}
+	if _, ok := lines[1]["error"]; ok {
+		t.Error("Expected no error field for WithError(nil)")
+	}
+}
+
+func TestLoggerWithErrorToSink(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink})
+
+	l.WithError(tracedError{}).Error("failed")
+
+	if len(sink.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(sink.entries))
+	}
+	want := types.LogError{Name: "logger.tracedError", Message: "traced", Stack: "main.go:10"}
+	if got := sink.entries[0].Error; got == nil || *got != want {
+		t.Errorf("Expected entry error %+v, got %+v", want, got)
+	}
+}
+
+type upperRedactor struct{}
+
+func (upperRedactor) Redact(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToUpper(s)
+	}
+	return value
+}
+
+func TestLoggerRedactsErrorNameAndStack(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink, Redactor: upperRedactor{}})
+
+	l.WithError(tracedError{}).Error("failed")
+
+	want := types.LogError{Name: "LOGGER.TRACEDERROR", Message: "TRACED", Stack: "MAIN.GO:10"}
+	if got := sink.entries[0].Error; got == nil || *got != want {
+		t.Errorf("Expected entry error %+v, got %+v", want, got)
+	}
+}
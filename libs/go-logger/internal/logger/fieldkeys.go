@@ -0,0 +1,58 @@
+package logger
+
+// lineKeys are the names of the keys every line written to Output has.
+type lineKeys struct {
+	timestamp, level, message string
+}
+
+var defaultLineKeys = lineKeys{timestamp: "timestamp", level: "level", message: "message"}
+
+// newLineKeys returns the line keys renamed by keyMap, see
+// types.LogOptions.FieldKeyMap.
+func newLineKeys(keyMap map[string]string) lineKeys {
+	keys := defaultLineKeys
+	if name := keyMap["timestamp"]; name != "" {
+		keys.timestamp = name
+	}
+	if name := keyMap["level"]; name != "" {
+		keys.level = name
+	}
+	if name := keyMap["message"]; name != "" {
+		keys.message = name
+	}
+	return keys
+}
+
+// orDefault returns k, or the default keys for the zero value.
+func (k lineKeys) orDefault() lineKeys {
+	if k == (lineKeys{}) {
+		return defaultLineKeys
+	}
+	return k
+}
+
+// all returns the keys in the order they are written.
+func (k lineKeys) all() [3]string {
+	return [3]string{k.timestamp, k.level, k.message}
+}
+
+// renameKeys renames the keys of fields found in keyMap, in place. Every
+// key is renamed at most once, whatever the order of keyMap, so chains
+// such as a to b and b to c move a to b and b to c.
+func renameKeys(fields map[string]interface{}, keyMap map[string]string) {
+	var moved map[string]interface{}
+	for from, to := range keyMap {
+		v, ok := fields[from]
+		if !ok || to == "" || to == from {
+			continue
+		}
+		if moved == nil {
+			moved = make(map[string]interface{}, len(keyMap))
+		}
+		moved[to] = v
+		delete(fields, from)
+	}
+	for k, v := range moved {
+		fields[k] = v
+	}
+}
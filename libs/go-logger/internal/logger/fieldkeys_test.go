@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestLoggerFieldKeyMap(t *testing.T) {
+	backends := []struct {
+		name    string
+		options types.LogOptions
+	}{
+		{"logrus", types.LogOptions{}},
+		{"sorted", types.LogOptions{SortFields: true}},
+		{"json", types.LogOptions{Backend: types.JSONBackend}},
+	}
+
+	for _, tt := range backends {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.Service = "orders"
+			options.FieldKeyMap = types.ECSFieldKeyMap()
+			log, buf := newTestLogger(options)
+
+			log.WithCorrelationID("cid-1").Info("placed", map[string]interface{}{
+				"password": "hunter2",
+				"orderId":  "42",
+			})
+
+			line := lastLine(t, buf)
+			want := map[string]interface{}{
+				"log.level":             "info",
+				"message":               "placed",
+				"service.name":          "orders",
+				"labels.correlation_id": "cid-1",
+				"orderId":               "42",
+				"password":              "***",
+			}
+			for k, v := range want {
+				if line[k] != v {
+					t.Errorf("Expected %s=%v, got %v", k, v, line[k])
+				}
+			}
+			if _, ok := line["@timestamp"]; !ok {
+				t.Errorf("Expected @timestamp, got %v", line)
+			}
+			for _, k := range []string{"timestamp", "level", "service", "correlationId"} {
+				if _, ok := line[k]; ok {
+					t.Errorf("Expected %s to be renamed, got %v", k, line)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerFieldKeyMapToSink(t *testing.T) {
+	sink := &captureSink{}
+	log := NewLogger(types.LogOptions{Sink: sink, FieldKeyMap: types.GELFFieldKeyMap()})
+
+	ctx := logctx.WithTraceContext(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	log.WithContext(ctx).Info("hi")
+
+	fields := sink.entries[0].Fields
+	if fields["_trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || fields["_span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("Expected renamed trace fields, got %v", fields)
+	}
+	if _, ok := fields["trace_id"]; ok {
+		t.Errorf("Expected trace_id to be renamed, got %v", fields)
+	}
+}
+
+func TestRenameKeysChains(t *testing.T) {
+	fields := map[string]interface{}{"a": 1, "b": 2}
+
+	renameKeys(fields, map[string]string{"a": "b", "b": "c"})
+
+	if len(fields) != 2 || fields["b"] != 1 || fields["c"] != 2 {
+		t.Errorf("Expected a to move to b and b to c, got %v", fields)
+	}
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// orderedFormatter renders the same JSON as the logrus JSONFormatter used
+// by NewLogger, but with a stable key order: the timestamp, level and
+// message keys and service first, then the remaining fields
+// alphabetically. Identical lines therefore serialize to identical bytes,
+// which keeps line diffs and golden files stable.
+type orderedFormatter struct {
+	// keys names the line keys; the zero value uses the default names.
+	keys lineKeys
+}
+
+func (f orderedFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	keys := f.keys.orDefault()
+	reserved := keys.all()
+	leadingKeys := append(reserved[:], "service")
+	data := make(map[string]interface{}, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	// Like logrus, keep fields that clash with the line's own keys under
+	// a "fields." prefix.
+	for _, key := range reserved {
+		if v, ok := data[key]; ok {
+			data["fields."+key] = v
+		}
+	}
+	data[keys.timestamp] = entry.Time.Format(time.RFC3339Nano)
+	data[keys.level] = entry.Level.String()
+	data[keys.message] = entry.Message
+
+	others := make([]string, 0, len(data))
+	for k := range data {
+		if !contains(leadingKeys, k) {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	first := true
+	write := func(key string, value interface{}) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal field %q to JSON, %w", key, err)
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(encoded)
+		return nil
+	}
+	for _, key := range leadingKeys {
+		if v, ok := data[key]; ok {
+			if err := write(key, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, key := range others {
+		if err := write(key, data[key]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// entryFormatter adapts a types.Formatter to logrus, handing it the line
+// as a types.LogEntry shaped like the ones given to sinks, with its fields
+// renamed by keyMap.
+type entryFormatter struct {
+	formatter types.Formatter
+	keyMap    map[string]string
+}
+
+func (f entryFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	out := types.LogEntry{
+		Timestamp: entry.Time,
+		Level:     fromLogrusLevel(entry.Level),
+		Message:   entry.Message,
+		Fields:    make(map[string]interface{}, len(entry.Data)),
+	}
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case *types.LogError:
+			if k == "error" {
+				out.Error = v
+				continue
+			}
+		case *types.LogScope:
+			if k == "scope" {
+				out.Scope = *v
+				continue
+			}
+		}
+		out.Fields[k] = v
+	}
+	out.CorrelationID, _ = out.Fields["correlationId"].(string)
+	renameKeys(out.Fields, f.keyMap)
+
+	line, err := f.formatter.Format(out)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
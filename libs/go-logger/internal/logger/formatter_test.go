@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestOrderedFormatter(t *testing.T) {
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Level:   logrus.WarnLevel,
+		Message: "slow",
+		Data: logrus.Fields{
+			"zeta":    1,
+			"service": "api",
+			"alpha":   "a",
+			"err":     errors.New("boom"),
+			"message": "clash",
+		},
+	}
+
+	out, err := orderedFormatter{}.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `{"timestamp":"2024-01-02T03:04:05.000000006Z","level":"warning","message":"slow","service":"api",` +
+		`"alpha":"a","err":"boom","fields.message":"clash","zeta":1}` + "\n"
+	if string(out) != want {
+		t.Errorf("Expected %s, got %s", want, out)
+	}
+}
+
+func TestOrderedFormatterIsDeterministic(t *testing.T) {
+	entry := &logrus.Entry{Time: time.Unix(0, 0), Level: logrus.InfoLevel, Message: "m", Data: logrus.Fields{}}
+	for i := 0; i < 50; i++ {
+		entry.Data[string(rune('a'+i%26))+string(rune('a'+i/26))] = i
+	}
+
+	first, _ := orderedFormatter{}.Format(entry)
+	for i := 0; i < 20; i++ {
+		if next, _ := (orderedFormatter{}).Format(entry); string(next) != string(first) {
+			t.Fatalf("Expected identical output, got %s and %s", first, next)
+		}
+	}
+}
+
+func TestLoggerSortFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		options types.LogOptions
+	}{
+		{"option", types.LogOptions{SortFields: true, Service: "api"}},
+		{"production", types.LogOptions{Environment: "production", Service: "api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, buf := newTestLogger(tt.options)
+
+			l.Info("hello", map[string]interface{}{"b": 1, "a": 2})
+
+			line := buf.String()
+			if !strings.HasPrefix(line, `{"timestamp":`) {
+				t.Fatalf("Expected timestamp first, got %s", line)
+			}
+			lastLine(t, buf)
+			for _, pair := range [][2]string{{`"timestamp"`, `"level"`}, {`"level"`, `"message"`}, {`"message"`, `"service"`}, {`"service"`, `"a"`}, {`"a"`, `"b"`}} {
+				if strings.Index(line, pair[0]) > strings.Index(line, pair[1]) {
+					t.Errorf("Expected %s before %s in %s", pair[0], pair[1], line)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerCustomFormatter(t *testing.T) {
+	var got types.LogEntry
+	formatter := types.FormatterFunc(func(entry types.LogEntry) ([]byte, error) {
+		got = entry
+		return []byte(string(entry.Level) + " " + entry.Message), nil
+	})
+	l, buf := newTestLogger(types.LogOptions{Formatter: formatter, SortFields: true})
+
+	l.WithCorrelationID("cid-fmt").WithError(errors.New("boom")).Warn("custom", map[string]interface{}{"k": "v"})
+
+	if buf.String() != "warn custom\n" {
+		t.Errorf("Expected the custom formatter's line, got %q", buf.String())
+	}
+	if got.CorrelationID != "cid-fmt" || got.Fields["k"] != "v" || got.Error == nil || got.Error.Message != "boom" {
+		t.Errorf("Expected correlation ID, fields and error in the entry, got %+v", got)
+	}
+	if _, ok := got.Fields["error"]; ok {
+		t.Error("Expected the error to be moved out of the fields")
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// maxPooledBuffer caps the buffers kept for reuse so one huge line does
+// not pin its memory.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// jsonWriter is the JSON backend: it encodes lines straight to the output
+// with a pooled buffer, skipping logrus entries and formatters. Lines have
+// the same shape as those of the default JSONFormatter, or are logfmt when
+// logfmt is set.
+type jsonWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	keys   lineKeys
+	logfmt bool
+}
+
+// write encodes fields, which it takes ownership of, as one line.
+func (w *jsonWriter) write(level types.LogLevel, msg string, fields map[string]interface{}, now time.Time) {
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			fields[k] = err.Error()
+		}
+	}
+	// Like logrus, keep fields that clash with the line's own keys under
+	// a "fields." prefix.
+	keys := w.keys.orDefault()
+	for _, key := range keys.all() {
+		if v, ok := fields[key]; ok {
+			fields["fields."+key] = v
+		}
+	}
+	fields[keys.timestamp] = now.Format(time.RFC3339Nano)
+	fields[keys.level] = toLogrusLevel(level).String()
+	fields[keys.message] = msg
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}()
+	if w.logfmt {
+		encodeLogfmt(buf, keys, fields)
+	} else if err := json.NewEncoder(buf).Encode(fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal fields to JSON, %v\n", err)
+		return
+	}
+
+	w.mu.Lock()
+	_, err := w.out.Write(buf.Bytes())
+	w.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestJSONBackendMatchesLogrus(t *testing.T) {
+	write := func(backend types.Backend) map[string]interface{} {
+		l, buf := newTestLogger(types.LogOptions{Service: "api", Backend: backend})
+		l.WithCorrelationID("cid-json").WithError(errors.New("boom")).Warn("slow", map[string]interface{}{
+			"orderId":  42,
+			"password": "hunter2",
+			"level":    "clash",
+		})
+		return lastLine(t, buf)
+	}
+	want, got := write(types.LogrusBackend), write(types.JSONBackend)
+
+	delete(want, "timestamp")
+	if _, ok := got["timestamp"]; !ok {
+		t.Error("Expected a timestamp")
+	}
+	delete(got, "timestamp")
+	if len(got) != len(want) {
+		t.Errorf("Expected keys %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if k == "error" {
+			continue
+		}
+		if got[k] != v {
+			t.Errorf("Expected %s=%v, got %v", k, v, got[k])
+		}
+	}
+	if got["password"] == "hunter2" {
+		t.Error("Expected the JSON backend to redact")
+	}
+	if e, ok := got["error"].(map[string]interface{}); !ok || e["message"] != "boom" {
+		t.Errorf("Expected the error object, got %v", got["error"])
+	}
+}
+
+func TestJSONBackendLevelFiltering(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Level: types.WarnLevel, Backend: types.JSONBackend})
+
+	l.Info("hidden")
+	l.Error("shown")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || lines[0]["message"] != "shown" || lines[0]["level"] != "error" {
+		t.Errorf("Expected only the error line, got %v", lines)
+	}
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelVar holds the minimum level of a logger. Loggers derived with a
+// With* method get their own levelVar that follows the parent until
+// SetLevel is called on them: a subsystem logger can be raised to debug
+// without affecting the rest of the process, while changes made on the
+// root still reach every child that was not overridden.
+type levelVar struct {
+	parent *levelVar
+	level  atomic.Int32
+	set    atomic.Bool
+}
+
+func newLevelVar(parent *levelVar) *levelVar {
+	return &levelVar{parent: parent}
+}
+
+// get returns the level of the closest levelVar, starting at v, on which
+// a level was stored.
+func (v *levelVar) get() logrus.Level {
+	for current := v; current != nil; current = current.parent {
+		if current.set.Load() {
+			return logrus.Level(current.level.Load())
+		}
+	}
+	return logrus.InfoLevel
+}
+
+func (v *levelVar) store(level logrus.Level) {
+	v.level.Store(int32(level))
+	v.set.Store(true)
+}
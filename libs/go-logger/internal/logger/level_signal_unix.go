@@ -0,0 +1,40 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// CycleLevelOnSignal advances log to the next level (trace, debug, info,
+// warn, error, then back to trace) every time the process receives
+// SIGUSR1. The returned function stops listening.
+func CycleLevelOnSignal(log types.Logger) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				applyLevel(log, nextLevel(log.GetLevel()))
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package logger
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestCycleLevelOnSignal(t *testing.T) {
+	l, _ := newTestLogger(types.LogOptions{})
+	stop := CycleLevelOnSignal(l)
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	waitForLevel(t, l, types.WarnLevel)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	waitForLevel(t, l, types.ErrorLevel)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package logger
+
+import "github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+
+// CycleLevelOnSignal is a no-op on Windows, which has no SIGUSR1.
+func CycleLevelOnSignal(log types.Logger) func() {
+	return func() {}
+}
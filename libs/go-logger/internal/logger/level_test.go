@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestLoggerSetLevelPerInstance(t *testing.T) {
+	root, buf := newTestLogger(types.LogOptions{Level: types.InfoLevel})
+	payments := root.WithFields(map[string]interface{}{"subsystem": "payments"})
+	orders := root.WithFields(map[string]interface{}{"subsystem": "orders"})
+
+	payments.SetLevel(types.DebugLevel)
+	payments.Debug("payments debug")
+	orders.Debug("orders debug")
+	root.Debug("root debug")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || lines[0]["message"] != "payments debug" {
+		t.Fatalf("Expected only the payments debug line, got %v", lines)
+	}
+	if root.GetLevel() != types.InfoLevel || orders.GetLevel() != types.InfoLevel {
+		t.Errorf("Expected root and siblings to stay at info, got %s and %s", root.GetLevel(), orders.GetLevel())
+	}
+	if payments.GetLevel() != types.DebugLevel {
+		t.Errorf("Expected payments at debug, got %s", payments.GetLevel())
+	}
+}
+
+func TestLoggerChildrenFollowParentLevel(t *testing.T) {
+	root, buf := newTestLogger(types.LogOptions{Level: types.InfoLevel})
+	child := root.WithPrefix("cache")
+	grandchild := child.WithCorrelationID("cid-1")
+	override := root.WithFields(map[string]interface{}{"k": "v"})
+	override.SetLevel(types.ErrorLevel)
+
+	root.SetLevel(types.DebugLevel)
+
+	if grandchild.GetLevel() != types.DebugLevel {
+		t.Errorf("Expected descendants to follow the root level, got %s", grandchild.GetLevel())
+	}
+	if override.GetLevel() != types.ErrorLevel {
+		t.Errorf("Expected an overridden child to keep its level, got %s", override.GetLevel())
+	}
+
+	grandchild.Debug("visible")
+	override.Warn("hidden")
+	if lines := decodeLines(t, buf); len(lines) != 1 || lines[0]["message"] != "visible" {
+		t.Errorf("Expected only the grandchild debug line, got %v", lines)
+	}
+}
+
+func TestLoggersFromSeparateConstructorsAreIndependent(t *testing.T) {
+	first, _ := newTestLogger(types.LogOptions{})
+	second, _ := newTestLogger(types.LogOptions{})
+
+	first.SetLevel(types.TraceLevel)
+
+	if second.GetLevel() != types.InfoLevel {
+		t.Errorf("Expected second logger to stay at info, got %s", second.GetLevel())
+	}
+}
+
+func TestNamedLoggerLevels(t *testing.T) {
+	root, buf := newTestLogger(types.LogOptions{Level: types.InfoLevel})
+	db := root.Named("db")
+	query := db.Named("query")
+
+	db.SetLevel(types.DebugLevel)
+	root.SetLevel(types.WarnLevel)
+	query.Debug("select")
+	root.Info("suppressed")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the query line, got %d lines", len(lines))
+	}
+	if lines[0]["logger"] != "db.query" {
+		t.Errorf("Expected logger 'db.query', got %v", lines[0]["logger"])
+	}
+	if root.GetLevel() != types.WarnLevel || db.GetLevel() != types.DebugLevel {
+		t.Errorf("Expected root at warn and db at debug, got %v and %v", root.GetLevel(), db.GetLevel())
+	}
+}
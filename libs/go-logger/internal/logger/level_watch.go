@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// levelFilePollInterval is how often WatchLevelFile checks the file.
+var levelFilePollInterval = time.Second
+
+// WatchLevelFile polls path for a level name (e.g. "debug") and applies it
+// to log whenever the file changes. Unknown levels are reported and
+// ignored. The returned function stops watching.
+func WatchLevelFile(path string, log types.Logger) func() {
+	done := make(chan struct{})
+	var lastMod time.Time
+	check := func() {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(lastMod) {
+			return
+		}
+		lastMod = info.ModTime()
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		level, ok := parseLevel(string(content))
+		if !ok {
+			log.Warn("ignoring invalid log level", map[string]interface{}{"path": path, "level": string(content)})
+			return
+		}
+		applyLevel(log, level)
+	}
+
+	check()
+	go func() {
+		ticker := time.NewTicker(levelFilePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// levelCycle is the order CycleLevelOnSignal walks through.
+var levelCycle = []types.LogLevel{
+	types.TraceLevel,
+	types.DebugLevel,
+	types.InfoLevel,
+	types.WarnLevel,
+	types.ErrorLevel,
+}
+
+// nextLevel returns the level following current in levelCycle, wrapping
+// around after the last one.
+func nextLevel(current types.LogLevel) types.LogLevel {
+	for i, level := range levelCycle {
+		if level == current {
+			return levelCycle[(i+1)%len(levelCycle)]
+		}
+	}
+	return types.InfoLevel
+}
+
+func applyLevel(log types.Logger, level types.LogLevel) {
+	previous := log.GetLevel()
+	if previous == level {
+		return
+	}
+	// Announce the change under whichever of the two levels is more verbose
+	// so the line is not suppressed. As the levels differ, that is never
+	// fatal, the least verbose one.
+	fields := map[string]interface{}{"from": string(previous), "to": string(level)}
+	if toLogrusLevel(level) > toLogrusLevel(previous) {
+		log.SetLevel(level)
+		logAt(log, level, "log level changed", fields)
+		return
+	}
+	logAt(log, previous, "log level changed", fields)
+	log.SetLevel(level)
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// waitForLevel polls until l reaches want or the deadline passes.
+func waitForLevel(t *testing.T, l *logger, want types.LogLevel) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if l.GetLevel() == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected level '%s', got '%s'", want, l.GetLevel())
+}
+
+func TestWatchLevelFile(t *testing.T) {
+	previous := levelFilePollInterval
+	levelFilePollInterval = 10 * time.Millisecond
+	defer func() { levelFilePollInterval = previous }()
+
+	path := filepath.Join(t.TempDir(), "level")
+	if err := os.WriteFile(path, []byte("debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, buf := newTestLogger(types.LogOptions{})
+	stop := WatchLevelFile(path, l)
+	defer stop()
+
+	waitForLevel(t, l, types.DebugLevel)
+
+	// Ensure a distinct modification time on coarse-grained filesystems.
+	later := time.Now().Add(time.Second)
+	if err := os.WriteFile(path, []byte("warning"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	waitForLevel(t, l, types.WarnLevel)
+
+	line := decodeLines(t, buf)[0]
+	if line["message"] != "log level changed" || line["to"] != "debug" {
+		t.Errorf("Expected level change line, got %v", line)
+	}
+}
+
+func TestApplyLevelLogsAtTheMoreVerboseLevel(t *testing.T) {
+	tests := []struct {
+		from, to, want types.LogLevel
+	}{
+		{types.InfoLevel, types.DebugLevel, types.DebugLevel},
+		{types.DebugLevel, types.WarnLevel, types.DebugLevel},
+		{types.WarnLevel, types.ErrorLevel, types.WarnLevel},
+		{types.FatalLevel, types.ErrorLevel, types.ErrorLevel},
+		{types.ErrorLevel, types.FatalLevel, types.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"-"+string(tt.to), func(t *testing.T) {
+			sink := &captureSink{}
+			l := NewLogger(types.LogOptions{Level: tt.from, Sink: sink})
+
+			applyLevel(l, tt.to)
+
+			if len(sink.entries) != 1 || sink.entries[0].Level != tt.want {
+				t.Fatalf("Expected one %s line, got %+v", tt.want, sink.entries)
+			}
+			if l.GetLevel() != tt.to {
+				t.Errorf("Expected level '%s', got '%s'", tt.to, l.GetLevel())
+			}
+		})
+	}
+}
+
+func TestWatchLevelFileIgnoresInvalidLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "level")
+	if err := os.WriteFile(path, []byte("loud"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, buf := newTestLogger(types.LogOptions{})
+	stop := WatchLevelFile(path, l)
+	stop()
+	stop()
+
+	if l.GetLevel() != types.InfoLevel {
+		t.Errorf("Expected level to stay 'info', got '%s'", l.GetLevel())
+	}
+	if line := lastLine(t, buf); line["message"] != "ignoring invalid log level" {
+		t.Errorf("Expected invalid level warning, got %v", line)
+	}
+}
+
+func TestNextLevel(t *testing.T) {
+	tests := map[types.LogLevel]types.LogLevel{
+		types.TraceLevel: types.DebugLevel,
+		types.InfoLevel:  types.WarnLevel,
+		types.ErrorLevel: types.TraceLevel,
+		types.FatalLevel: types.InfoLevel,
+	}
+	for current, want := range tests {
+		if got := nextLevel(current); got != want {
+			t.Errorf("nextLevel(%s) = %s, want %s", current, got, want)
+		}
+	}
+}
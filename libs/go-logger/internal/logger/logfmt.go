@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logfmtFormatter renders lines as logfmt for the logrus backend, with the
+// same keys as the JSON lines.
+type logfmtFormatter struct {
+	keys lineKeys
+}
+
+func (f logfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	keys := f.keys.orDefault()
+	fields := make(map[string]interface{}, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+	for _, key := range keys.all() {
+		if v, ok := fields[key]; ok {
+			fields["fields."+key] = v
+		}
+	}
+	fields[keys.timestamp] = entry.Time.Format(time.RFC3339Nano)
+	fields[keys.level] = entry.Level.String()
+	fields[keys.message] = entry.Message
+
+	buf := entry.Buffer
+	if buf == nil {
+		buf = &bytes.Buffer{}
+	}
+	encodeLogfmt(buf, keys, fields)
+	return buf.Bytes(), nil
+}
+
+// encodeLogfmt writes fields as a logfmt line: the timestamp, level and
+// message first, then the other keys sorted, with nested objects
+// flattened into dotted keys.
+func encodeLogfmt(buf *bytes.Buffer, keys lineKeys, fields map[string]interface{}) {
+	first := keys.all()
+	for _, key := range first {
+		if v, ok := fields[key]; ok {
+			appendLogfmt(buf, key, v)
+		}
+	}
+	rest := make([]string, 0, len(fields))
+	for k := range fields {
+		if !contains(first[:], k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		appendLogfmt(buf, k, fields[k])
+	}
+	if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] == ' ' {
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('\n')
+}
+
+// appendLogfmt writes the pairs for key and v, each followed by a space.
+// Maps are flattened; other composite values are taken through their JSON
+// form, so structs such as the error become objects and slices stay JSON.
+func appendLogfmt(buf *bytes.Buffer, key string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			appendLogfmt(buf, key+"."+k, v[k])
+		}
+		return
+	case nil:
+		writeLogfmtPair(buf, key, "")
+		return
+	case string:
+		writeLogfmtPair(buf, key, v)
+		return
+	case bool:
+		writeLogfmtPair(buf, key, strconv.FormatBool(v))
+		return
+	case int:
+		writeLogfmtPair(buf, key, strconv.Itoa(v))
+		return
+	case float64:
+		writeLogfmtPair(buf, key, strconv.FormatFloat(v, 'f', -1, 64))
+		return
+	case error:
+		writeLogfmtPair(buf, key, v.Error())
+		return
+	case json.Number:
+		writeLogfmtPair(buf, key, v.String())
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeLogfmtPair(buf, key, fmt.Sprint(v))
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		writeLogfmtPair(buf, key, string(data))
+		return
+	}
+	switch decoded.(type) {
+	case []interface{}:
+		writeLogfmtPair(buf, key, string(data))
+	default:
+		appendLogfmt(buf, key, decoded)
+	}
+}
+
+// writeLogfmtPair writes key=value and a space. Characters that would
+// break the pair are replaced in the key, and the value is quoted when
+// needed.
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key))
+	buf.WriteByte('=')
+	if needsLogfmtQuote(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+	buf.WriteByte(' ')
+}
+
+// needsLogfmtQuote reports whether value must be quoted: when it is empty
+// or holds spaces, quotes, equal signs or unprintable characters.
+func needsLogfmtQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
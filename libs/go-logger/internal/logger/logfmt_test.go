@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestEncodeLogfmt(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	encodeLogfmt(buf, defaultLineKeys, map[string]interface{}{
+		"message":   "order placed",
+		"level":     "info",
+		"timestamp": "2024-05-01T12:00:00Z",
+		"quote":     `say "hi"`,
+		"empty":     "",
+		"multi":     "a\nb",
+		"count":     3,
+		"ratio":     0.5,
+		"ok":        true,
+		"nil":       nil,
+		"user":      map[string]interface{}{"id": "u-1", "address": map[string]interface{}{"city": "São Paulo"}},
+		"ids":       []interface{}{1, 2},
+		"bad key":   "x",
+	})
+
+	want := `timestamp=2024-05-01T12:00:00Z level=info message="order placed" bad_key=x count=3 empty="" ids=[1,2] multi="a\nb" nil="" ok=true quote="say \"hi\"" ratio=0.5 user.address.city="São Paulo" user.id=u-1` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestLoggerLogfmt(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, backend := range []types.Backend{types.LogrusBackend, types.JSONBackend} {
+		t.Run(string(backend), func(t *testing.T) {
+			l, buf := newTestLogger(types.LogOptions{
+				Service: "api",
+				Backend: backend,
+				Format:  types.LogfmtFormat,
+				Now:     func() time.Time { return now },
+			})
+
+			l.WithError(errors.New("card declined")).Warn("payment failed", map[string]interface{}{
+				"password": "hunter2",
+				"level":    "clash",
+			})
+
+			want := `timestamp=2024-05-01T12:00:00Z level=warning message="payment failed" error.message="card declined" error.name=*errors.errorString fields.level=clash password=*** service=api` + "\n"
+			if got := buf.String(); got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestLoggerTextFormat(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Backend: types.JSONBackend, Format: types.TextFormat})
+
+	l.Info("hello", map[string]interface{}{"orderId": "42"})
+
+	if got := buf.String(); !strings.Contains(got, "message=hello") || !strings.Contains(got, "orderId=42") {
+		t.Errorf("Expected a logrus text line, got %q", got)
+	}
+}
@@ -0,0 +1,672 @@
+// Package logger implements types.Logger on top of logrus.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/kv"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/stack"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/redactor"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// summaryRedactor is implemented by redactors able to report what they masked.
+type summaryRedactor interface {
+	RedactWithSummary(value interface{}) (interface{}, redactor.Summary)
+}
+
+const (
+	developmentEnvironment = "development"
+	productionEnvironment  = "production"
+)
+
+// logger is immutable once built: With* methods derive clones, and log
+// only reads entry.Data while merging it into a fresh map. This is what
+// makes a shared base logger safe to use from many goroutines; formatting
+// and writing are serialized by logrus.
+type logger struct {
+	entry         *logrus.Entry
+	options       types.LogOptions
+	correlationID string
+	traceID       string
+	spanID        string
+	// deadlineCtx is the context passed to WithContext, kept only when it
+	// has a deadline so lines can report the time remaining.
+	deadlineCtx context.Context
+	prefix      string
+	// namespace is the path, set by WithNamespace, under which fields
+	// added afterwards are nested.
+	namespace []string
+	// name is the dotted chain of names given to Named.
+	name string
+	err  *types.LogError
+	// stack is the stack captured by WithStack.
+	stack   string
+	level   *levelVar
+	limiter *rateLimiter
+	// json is set when the JSON backend renders lines instead of logrus.
+	json *jsonWriter
+	// closer is shared with every derived logger so Close runs once.
+	closer *closer
+	// skipRedaction is set when the redactor is redactor.NopRedactor.
+	skipRedaction bool
+	// static holds the runtime info and static fields, added to every
+	// line after redaction. It is never modified once built.
+	static map[string]interface{}
+	// renameOutput is set when writeOutput applies FieldKeyMap.
+	renameOutput bool
+	// callerSkip is the number of wrapper frames between the user code and
+	// the public logging method, left out of the caller scope.
+	callerSkip int
+}
+
+// NewLogger creates a JSON logger writing to options.Output, stdout by
+// default.
+func NewLogger(options types.LogOptions) types.Logger {
+	log := logrus.New()
+	if options.Output == nil {
+		options.Output = os.Stdout
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	if options.OnSinkError == nil {
+		options.OnSinkError = reportSinkError
+	}
+	log.SetOutput(options.Output)
+	keys := newLineKeys(options.FieldKeyMap)
+	fieldMap := logrus.FieldMap{
+		logrus.FieldKeyTime:  keys.timestamp,
+		logrus.FieldKeyLevel: keys.level,
+		logrus.FieldKeyMsg:   keys.message,
+	}
+	var formatter logrus.Formatter = &logrus.JSONFormatter{
+		TimestampFormat: time.RFC3339Nano,
+		FieldMap:        fieldMap,
+	}
+	switch {
+	case options.Formatter != nil:
+		formatter = entryFormatter{formatter: options.Formatter, keyMap: options.FieldKeyMap}
+	case options.Format == types.LogfmtFormat:
+		formatter = logfmtFormatter{keys: keys}
+	case options.Format == types.TextFormat:
+		formatter = &logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap:        fieldMap,
+		}
+	case options.SortFields || options.Environment == productionEnvironment:
+		formatter = orderedFormatter{keys: keys}
+	}
+	if len(options.SigningKey) > 0 {
+		formatter = newSigningFormatter(formatter, options.SigningKey)
+	}
+	log.SetFormatter(formatter)
+
+	// Filtering happens per logger instance (see levelVar); the shared
+	// logrus instance lets everything through.
+	log.SetLevel(logrus.TraceLevel)
+	level, err := logrus.ParseLevel(string(options.Level))
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	levelVar := newLevelVar(nil)
+	levelVar.store(level)
+
+	switch {
+	case options.DisableRedaction:
+		options.Redactor = redactor.NopRedactor()
+	case options.Redactor == nil:
+		options.Redactor = defaultRedactor(options.Environment)
+	}
+
+	base := logrus.Fields{}
+	if options.Service != "" {
+		base["service"] = options.Service
+	}
+	if options.Environment != "" {
+		base["environment"] = options.Environment
+	}
+	if options.Version != "" {
+		base["version"] = options.Version
+	}
+
+	l := &logger{
+		entry:   logrus.NewEntry(log).WithFields(base),
+		options: options,
+		level:   levelVar,
+		closer:  &closer{},
+		static:  staticFields(options),
+	}
+	if options.RateLimit.MaxPerInterval > 0 && options.RateLimit.Interval > 0 {
+		l.limiter = newRateLimiter(options.RateLimit)
+	}
+	// write builds a fresh field map per line, so it can be logged as is
+	// when the redactor would not change anything.
+	l.skipRedaction = options.Redactor == redactor.NopRedactor()
+	// The JSON backend cannot sign lines: fall back to logrus rather than
+	// silently dropping the signatures.
+	if options.Backend == types.JSONBackend && options.Format != types.TextFormat && len(options.SigningKey) == 0 {
+		l.json = &jsonWriter{out: options.Output, keys: keys, logfmt: options.Format == types.LogfmtFormat}
+	}
+	// A custom Formatter renames the fields itself, after extracting the
+	// error and scope; the JSON backend ignores it.
+	l.renameOutput = len(options.FieldKeyMap) > 0 && (l.json != nil || options.Formatter == nil)
+	return l
+}
+
+// staticFields returns the fields added to every line for
+// IncludeRuntimeInfo and StaticFields, or nil when there are none.
+func staticFields(options types.LogOptions) map[string]interface{} {
+	if !options.IncludeRuntimeInfo && len(options.StaticFields) == 0 {
+		return nil
+	}
+	static := make(map[string]interface{}, len(options.StaticFields)+3)
+	if options.IncludeRuntimeInfo {
+		if hostname, err := os.Hostname(); err == nil {
+			static["hostname"] = hostname
+		}
+		static["pid"] = os.Getpid()
+		static["goVersion"] = runtime.Version()
+	}
+	for k, v := range options.StaticFields {
+		static[k] = v
+	}
+	return static
+}
+
+// defaultRedactor masks with the default rules, except in development
+// where suspected PII is annotated instead so it stays readable locally.
+func defaultRedactor(environment string) types.Redactor {
+	opts := redactor.DefaultRedactorOptions()
+	opts.AnnotatePII = environment == developmentEnvironment
+	return redactor.NewRedactor(opts)
+}
+
+func (l *logger) Trace(msg string, fields ...map[string]interface{}) {
+	l.log(types.TraceLevel, msg, fields...)
+}
+
+func (l *logger) Debug(msg string, fields ...map[string]interface{}) {
+	l.log(types.DebugLevel, msg, fields...)
+}
+
+func (l *logger) Info(msg string, fields ...map[string]interface{}) {
+	l.log(types.InfoLevel, msg, fields...)
+}
+
+func (l *logger) Warn(msg string, fields ...map[string]interface{}) {
+	l.log(types.WarnLevel, msg, fields...)
+}
+
+func (l *logger) Error(msg string, fields ...map[string]interface{}) {
+	l.log(types.ErrorLevel, msg, fields...)
+}
+
+func (l *logger) Fatal(msg string, fields ...map[string]interface{}) {
+	l.log(types.FatalLevel, msg, fields...)
+	// The process is about to exit: drain the sink or flush the output so
+	// the fatal line is not lost in a buffer.
+	l.Close()
+	l.entry.Logger.Exit(1)
+}
+
+func (l *logger) DebugCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	if c := l.forCtx(ctx, types.DebugLevel); c != nil {
+		c.log(types.DebugLevel, msg, fields...)
+	}
+}
+
+func (l *logger) InfoCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	if c := l.forCtx(ctx, types.InfoLevel); c != nil {
+		c.log(types.InfoLevel, msg, fields...)
+	}
+}
+
+func (l *logger) WarnCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	if c := l.forCtx(ctx, types.WarnLevel); c != nil {
+		c.log(types.WarnLevel, msg, fields...)
+	}
+}
+
+func (l *logger) ErrorCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	if c := l.forCtx(ctx, types.ErrorLevel); c != nil {
+		c.log(types.ErrorLevel, msg, fields...)
+	}
+}
+
+// forCtx returns the logger writing one line at level with the values
+// carried by ctx, or nil when level is disabled. The contextual copy shares
+// l's level, so nothing is derived and disabled levels cost nothing. The
+// *Ctx methods call log themselves to keep the frame depth callerScope
+// expects.
+func (l *logger) forCtx(ctx context.Context, level types.LogLevel) *logger {
+	if !l.enabled(level) {
+		return nil
+	}
+	return l.contextual(ctx)
+}
+
+func (l *logger) WithFields(fields map[string]interface{}) types.Logger {
+	child := l.clone()
+	if len(fields) == 0 {
+		return child
+	}
+	fields = l.prefixFields(fields)
+	if len(l.namespace) > 0 {
+		key, nested := l.nest(l.entry.Data, fields)
+		fields = map[string]interface{}{key: nested}
+	}
+	child.entry = l.entry.WithFields(fields)
+	return child
+}
+
+func (l *logger) WithField(key string, value interface{}) types.Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l *logger) With(args ...interface{}) types.Logger {
+	return kv.With(l, args)
+}
+
+func (l *logger) WithContext(ctx context.Context) types.Logger {
+	child := l.contextual(ctx)
+	if child == l {
+		// Derive even from a context without values, so the child never
+		// shares its level with l.
+		return l.clone()
+	}
+	child.level = newLevelVar(l.level)
+	return child
+}
+
+// contextual returns a shallow copy of l, sharing its level, carrying the
+// correlation ID, trace context and deadline stored in ctx, or l itself
+// when ctx holds none of them.
+func (l *logger) contextual(ctx context.Context) *logger {
+	id := logctx.GetCorrelationID(ctx)
+	traceID, spanID := logctx.GetTraceContext(ctx)
+	var hasDeadline bool
+	if ctx != nil {
+		_, hasDeadline = ctx.Deadline()
+	}
+	if id == "" && traceID == "" && spanID == "" && !hasDeadline {
+		return l
+	}
+
+	child := *l
+	if id != "" {
+		child.correlationID = id
+	}
+	if traceID != "" || spanID != "" {
+		child.traceID, child.spanID = traceID, spanID
+	}
+	if hasDeadline {
+		child.deadlineCtx = ctx
+	}
+	return &child
+}
+
+func (l *logger) WithCorrelationID(correlationID string) types.Logger {
+	child := l.clone()
+	child.correlationID = correlationID
+	return child
+}
+
+func (l *logger) WithPrefix(prefix string) types.Logger {
+	child := l.clone()
+	child.prefix = l.prefixKey(prefix)
+	return child
+}
+
+func (l *logger) Named(name string) types.Logger {
+	child := l.clone()
+	if name != "" {
+		if l.name != "" {
+			name = l.name + "." + name
+		}
+		child.name = name
+		child.entry = l.entry.WithField("logger", name)
+	}
+	return child
+}
+
+func (l *logger) WithError(err error) types.Logger {
+	child := l.clone()
+	if err != nil {
+		child.err = newLogError(err)
+	}
+	return child
+}
+
+func (l *logger) WithStack() types.Logger {
+	child := l.clone()
+	child.stack = stack.Capture(1, l.options.MaxStackFrames)
+	return child
+}
+
+// clone returns a shallow copy of l for deriving child loggers. The child
+// follows l's level until its own level is set.
+func (l *logger) clone() *logger {
+	child := *l
+	child.level = newLevelVar(l.level)
+	return &child
+}
+
+// prefixKey qualifies key with the logger's prefix, if any.
+func (l *logger) prefixKey(key string) string {
+	if l.prefix == "" {
+		return key
+	}
+	return l.prefix + "." + key
+}
+
+// prefixFields returns fields with every key qualified by the logger's prefix.
+func (l *logger) prefixFields(fields map[string]interface{}) map[string]interface{} {
+	if l.prefix == "" {
+		return fields
+	}
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		out[l.prefixKey(k)] = v
+	}
+	return out
+}
+
+func (l *logger) LogExternalCall(service, operation string, statusCode int, duration time.Duration, err error) {
+	level := types.InfoLevel
+	outcome := "success"
+	switch {
+	case err != nil || statusCode >= 500:
+		level = types.ErrorLevel
+		outcome = "failure"
+	case statusCode >= 400:
+		level = types.WarnLevel
+		outcome = "failure"
+	}
+	if !l.enabled(level) {
+		return
+	}
+
+	durationMs := float64(duration) / float64(time.Millisecond)
+	fields := map[string]interface{}{
+		"external.service":    service,
+		"external.operation":  operation,
+		"external.statusCode": statusCode,
+		"durationMs":          durationMs,
+		"outcome":             outcome,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	l.log(level, fmt.Sprintf("%s.%s %s in %.1fms", service, operation, outcome, durationMs), fields)
+}
+
+func (l *logger) LogFlagEvaluation(flag string, value interface{}, reason string) {
+	// Flags are evaluated on hot paths: skip building fields when debug is off.
+	if !l.enabled(types.DebugLevel) {
+		return
+	}
+	l.log(types.DebugLevel, "feature flag evaluated", map[string]interface{}{
+		"flag.name":   flag,
+		"flag.value":  value,
+		"flag.reason": reason,
+	})
+}
+
+func (l *logger) log(level types.LogLevel, msg string, fields ...map[string]interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	if l.limiter != nil && level != types.FatalLevel {
+		allowed := l.limiter.allow(level, msg, func(suppressed int) {
+			summary, summaryFields := suppressedSummary(level, msg, suppressed)
+			l.write(level, summary, summaryFields)
+		})
+		if !allowed {
+			return
+		}
+	}
+	l.write(level, msg, fields...)
+}
+
+// write emits a line that passed level filtering and rate limiting.
+func (l *logger) write(level types.LogLevel, msg string, fields ...map[string]interface{}) {
+	allFields := make(logrus.Fields, len(l.entry.Data))
+	for k, v := range l.entry.Data {
+		allFields[k] = v
+	}
+	for _, f := range fields {
+		if len(l.namespace) > 0 {
+			key, nested := l.nest(allFields, l.prefixFields(f))
+			allFields[key] = nested
+			continue
+		}
+		for k, v := range f {
+			allFields[l.prefixKey(k)] = v
+		}
+	}
+
+	var seq uint64
+	if l.options.Sequence != nil {
+		seq = l.options.Sequence.Next()
+	}
+	now := l.options.Now()
+	redacted := l.redact(allFields)
+	if l.options.MaxStringLength > 0 {
+		truncateFields(redacted, l.options.MaxStringLength)
+	}
+	for k, v := range l.static {
+		if _, ok := redacted[k]; !ok {
+			redacted[k] = v
+		}
+	}
+	if l.correlationID != "" {
+		redacted["correlationId"] = l.correlationID
+	}
+	// Trace IDs are hex strings the hash pattern would mask; like the
+	// correlation ID they are added after redaction, unprefixed.
+	if l.traceID != "" {
+		redacted["trace_id"] = l.traceID
+	}
+	if l.spanID != "" {
+		redacted["span_id"] = l.spanID
+	}
+	if l.deadlineCtx != nil {
+		l.addDeadline(redacted, now)
+	}
+	if seq != 0 {
+		redacted["sequence"] = seq
+	}
+	// Like the error stack, the captured stack is redacted as text and
+	// added unprefixed.
+	if l.stack != "" {
+		redacted["stack"] = l.redactText(l.stack)
+	}
+	logErr := l.redactError()
+	var scope *types.LogScope
+	if l.options.IncludeCaller {
+		scope = callerScope(l.callerSkip)
+	}
+
+	if l.options.Sink == nil && len(l.options.Hooks) == 0 {
+		l.writeOutput(level, msg, redacted, logErr, scope, now)
+		return
+	}
+
+	entry := types.LogEntry{
+		Timestamp:     now,
+		Level:         level,
+		Message:       msg,
+		Error:         logErr,
+		CorrelationID: l.correlationID,
+		Sequence:      seq,
+		Fields:        redacted,
+	}
+	if scope != nil {
+		entry.Scope = *scope
+	}
+	for _, hook := range l.options.Hooks {
+		if !hook(&entry) {
+			return
+		}
+	}
+	if l.options.Sink != nil {
+		renameKeys(entry.Fields, l.options.FieldKeyMap)
+		l.writeToSink(entry)
+		return
+	}
+	l.writeEntry(entry)
+}
+
+// writeOutput renders a line to Output with the JSON backend or logrus.
+func (l *logger) writeOutput(level types.LogLevel, msg string, fields logrus.Fields, logErr *types.LogError, scope *types.LogScope, now time.Time) {
+	if l.renameOutput {
+		renameKeys(fields, l.options.FieldKeyMap)
+	}
+	if logErr != nil {
+		fields["error"] = logErr
+	}
+	if scope != nil {
+		fields["scope"] = scope
+	}
+	if l.json != nil {
+		l.json.write(level, msg, fields, now)
+		return
+	}
+	entry := l.entry.Logger.WithFields(fields)
+	entry.Time = now
+	entry.Log(toLogrusLevel(level), msg)
+}
+
+// writeEntry renders an entry returned by the hooks to Output.
+func (l *logger) writeEntry(entry types.LogEntry) {
+	fields := logrus.Fields(entry.Fields)
+	if fields == nil {
+		fields = logrus.Fields{}
+	}
+	var scope *types.LogScope
+	if entry.Scope != (types.LogScope{}) {
+		scope = &entry.Scope
+	}
+	l.writeOutput(entry.Level, entry.Message, fields, entry.Error, scope, entry.Timestamp)
+}
+
+// writeToSink hands the entry to the configured sink. Sink failures are
+// reported on stderr, as logrus does for its own output.
+func (l *logger) writeToSink(entry types.LogEntry) {
+	if err := l.options.Sink.Write(entry); err != nil {
+		l.options.OnSinkError(entry, err)
+	}
+}
+
+// reportSinkError is the default OnSinkError.
+func reportSinkError(_ types.LogEntry, err error) {
+	fmt.Fprintf(os.Stderr, "Failed to write to sink, %v\n", err)
+}
+
+// addDeadline sets deadlineMs to the milliseconds left until the deadline
+// of the context given to WithContext, negative once it has passed, and
+// ctxCancelled when that context is done.
+func (l *logger) addDeadline(fields logrus.Fields, now time.Time) {
+	deadline, _ := l.deadlineCtx.Deadline()
+	fields["deadlineMs"] = deadline.Sub(now).Milliseconds()
+	if l.deadlineCtx.Err() != nil {
+		fields["ctxCancelled"] = true
+	}
+}
+
+// redact masks sensitive data in fields, attaching a redactionSummary
+// when enabled and something was masked.
+func (l *logger) redact(fields logrus.Fields) logrus.Fields {
+	if l.skipRedaction {
+		return fields
+	}
+	var (
+		redacted interface{}
+		summary  redactor.Summary
+	)
+	if sr, ok := l.options.Redactor.(summaryRedactor); ok && l.options.RedactionSummary {
+		redacted, summary = sr.RedactWithSummary(map[string]interface{}(fields))
+	} else {
+		redacted = l.options.Redactor.Redact(map[string]interface{}(fields))
+	}
+
+	out, ok := redacted.(map[string]interface{})
+	if !ok {
+		out = map[string]interface{}{"fields": redacted}
+	}
+	if summary.KeysRedacted > 0 || summary.CharsRedacted > 0 {
+		out["redactionSummary"] = map[string]interface{}{
+			"keysRedacted":  summary.KeysRedacted,
+			"charsRedacted": summary.CharsRedacted,
+		}
+	}
+	return out
+}
+
+func (l *logger) SetLevel(level types.LogLevel) {
+	l.level.store(toLogrusLevel(level))
+}
+
+func (l *logger) GetLevel() types.LogLevel {
+	return fromLogrusLevel(l.level.get())
+}
+
+// enabled reports whether lines at level are emitted by this logger.
+func (l *logger) enabled(level types.LogLevel) bool {
+	return level.Enabled(fromLogrusLevel(l.level.get()))
+}
+
+// parseLevel converts s into a LogLevel, accepting logrus spellings such
+// as "warning".
+func parseLevel(s string) (types.LogLevel, bool) {
+	level, err := logrus.ParseLevel(strings.TrimSpace(s))
+	if err != nil || level == logrus.PanicLevel {
+		return "", false
+	}
+	return fromLogrusLevel(level), true
+}
+
+func fromLogrusLevel(level logrus.Level) types.LogLevel {
+	switch level {
+	case logrus.TraceLevel:
+		return types.TraceLevel
+	case logrus.DebugLevel:
+		return types.DebugLevel
+	case logrus.WarnLevel:
+		return types.WarnLevel
+	case logrus.ErrorLevel:
+		return types.ErrorLevel
+	case logrus.FatalLevel, logrus.PanicLevel:
+		return types.FatalLevel
+	default:
+		return types.InfoLevel
+	}
+}
+
+func toLogrusLevel(level types.LogLevel) logrus.Level {
+	switch level {
+	case types.TraceLevel:
+		return logrus.TraceLevel
+	case types.DebugLevel:
+		return logrus.DebugLevel
+	case types.WarnLevel:
+		return logrus.WarnLevel
+	case types.ErrorLevel:
+		return logrus.ErrorLevel
+	case types.FatalLevel:
+		return logrus.FatalLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
@@ -0,0 +1,835 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/redactor"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// newTestLogger returns a logger writing into a buffer instead of stdout.
+func newTestLogger(options types.LogOptions) (*logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	options.Output = buf
+	return NewLogger(options).(*logger), buf
+}
+
+// decodeLines parses every JSON line written to buf.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func lastLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := decodeLines(t, buf)
+	if len(lines) == 0 {
+		t.Fatal("Expected at least one log line")
+	}
+	return lines[len(lines)-1]
+}
+
+func TestLoggerInfo(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Service: "orders", Environment: "test", Version: "1.2.3"})
+
+	l.Info("order created", map[string]interface{}{"orderId": "42"})
+
+	line := lastLine(t, buf)
+	if line["message"] != "order created" {
+		t.Errorf("Expected message 'order created', got %v", line["message"])
+	}
+	if line["level"] != "info" {
+		t.Errorf("Expected level 'info', got %v", line["level"])
+	}
+	if line["orderId"] != "42" {
+		t.Errorf("Expected orderId '42', got %v", line["orderId"])
+	}
+	if line["service"] != "orders" || line["environment"] != "test" || line["version"] != "1.2.3" {
+		t.Errorf("Expected base fields, got %v", line)
+	}
+	if _, ok := line["timestamp"]; !ok {
+		t.Error("Expected timestamp field")
+	}
+}
+
+func TestLoggerOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	other := &bytes.Buffer{}
+	NewLogger(types.LogOptions{Output: buf}).Info("to buffer")
+	NewLogger(types.LogOptions{Output: other}).Info("elsewhere")
+
+	if !strings.Contains(buf.String(), `"message":"to buffer"`) || strings.Contains(buf.String(), "elsewhere") {
+		t.Errorf("Expected only this logger's line in its output, got %q", buf.String())
+	}
+}
+
+func TestLoggerLevelFiltering(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Level: types.WarnLevel})
+
+	l.Info("suppressed")
+	l.Warn("emitted")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d", len(lines))
+	}
+	if lines[0]["message"] != "emitted" {
+		t.Errorf("Expected 'emitted', got %v", lines[0]["message"])
+	}
+}
+
+func TestLoggerInvalidLevelDefaultsToInfo(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Level: "verbose"})
+
+	l.Debug("suppressed")
+	l.Info("emitted")
+
+	if lines := decodeLines(t, buf); len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d", len(lines))
+	}
+}
+
+func TestLoggerWithFields(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	child := l.WithFields(map[string]interface{}{"component": "billing"})
+	child.Info("charged", map[string]interface{}{"amount": 10})
+	l.Info("parent")
+
+	lines := decodeLines(t, buf)
+	if lines[0]["component"] != "billing" || lines[0]["amount"] != float64(10) {
+		t.Errorf("Expected child fields, got %v", lines[0])
+	}
+	if _, ok := lines[1]["component"]; ok {
+		t.Error("Expected parent logger to be unaffected by WithFields")
+	}
+}
+
+func TestLoggerWithCorrelationID(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithCorrelationID("cid-1").Info("hello")
+
+	if line := lastLine(t, buf); line["correlationId"] != "cid-1" {
+		t.Errorf("Expected correlationId 'cid-1', got %v", line["correlationId"])
+	}
+}
+
+func TestLoggerWithContext(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-ctx")
+	l.WithContext(ctx).Info("hello")
+	l.WithContext(context.Background()).Info("no id")
+
+	lines := decodeLines(t, buf)
+	if lines[0]["correlationId"] != "cid-ctx" {
+		t.Errorf("Expected correlationId 'cid-ctx', got %v", lines[0]["correlationId"])
+	}
+	if _, ok := lines[1]["correlationId"]; ok {
+		t.Error("Expected no correlationId without one in context")
+	}
+}
+
+func TestLoggerWithTraceContext(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-trace")
+	ctx = logctx.WithTraceContext(ctx, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	l.WithPrefix("db").WithContext(ctx).Info("query")
+
+	line := lastLine(t, buf)
+	if line["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || line["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("Expected unredacted, unprefixed trace fields, got %v", line)
+	}
+	if line["correlationId"] != "cid-trace" {
+		t.Errorf("Expected correlationId 'cid-trace', got %v", line["correlationId"])
+	}
+}
+
+func TestLoggerWithContextWithoutTrace(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithContext(logctx.WithCorrelationID(context.Background(), "cid-only")).Info("hello")
+
+	line := lastLine(t, buf)
+	if _, ok := line["trace_id"]; ok {
+		t.Errorf("Expected no trace fields without trace context, got %v", line)
+	}
+	if line["correlationId"] != "cid-only" {
+		t.Errorf("Expected correlationId 'cid-only', got %v", line["correlationId"])
+	}
+}
+
+func TestLoggerWithContextWithoutValuesDerives(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	child := l.WithContext(context.Background())
+	child.SetLevel(types.ErrorLevel)
+	l.Info("parent")
+
+	if child == types.Logger(l) {
+		t.Error("Expected a derived logger for a context without values")
+	}
+	if l.GetLevel() != types.InfoLevel || len(decodeLines(t, buf)) != 1 {
+		t.Errorf("Expected the parent level to be unaffected, got '%s'", l.GetLevel())
+	}
+}
+
+func TestLoggerNoOpDerivationsDerive(t *testing.T) {
+	derive := map[string]func(types.Logger) types.Logger{
+		"WithError(nil)":      func(l types.Logger) types.Logger { return l.WithError(nil) },
+		"WithNamespace(\"\")": func(l types.Logger) types.Logger { return l.WithNamespace("") },
+		"With()":              func(l types.Logger) types.Logger { return l.With() },
+		"WithFields(nil)":     func(l types.Logger) types.Logger { return l.WithFields(nil) },
+	}
+
+	for name, fn := range derive {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newTestLogger(types.LogOptions{})
+
+			child := fn(l)
+			child.SetLevel(types.ErrorLevel)
+			l.Info("parent")
+
+			if l.GetLevel() != types.InfoLevel || len(decodeLines(t, buf)) != 1 {
+				t.Errorf("Expected the parent level to be unaffected, got '%s'", l.GetLevel())
+			}
+		})
+	}
+}
+
+func TestLoggerNow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(1500*time.Millisecond))
+	defer cancel()
+
+	for _, backend := range []types.Backend{types.LogrusBackend, types.JSONBackend} {
+		l, buf := newTestLogger(types.LogOptions{Now: clock, Backend: backend})
+		l.WithContext(ctx).Info("tick")
+
+		line := lastLine(t, buf)
+		if line["timestamp"] != "2024-05-01T12:00:00Z" {
+			t.Errorf("%s: expected the injected timestamp, got %v", backend, line["timestamp"])
+		}
+		if line["deadlineMs"] != float64(1500) {
+			t.Errorf("%s: expected deadlineMs 1500, got %v", backend, line["deadlineMs"])
+		}
+	}
+
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Now: clock, Sink: sink})
+	l.Info("tick")
+	if got := sink.entries[0].Timestamp; !got.Equal(now) {
+		t.Errorf("Expected the injected timestamp on the sink entry, got %v", got)
+	}
+}
+
+func TestLoggerInfoCtx(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-call")
+	ctx = logctx.WithTraceContext(ctx, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	l.InfoCtx(ctx, "handled", map[string]interface{}{"k": "v"})
+	l.Info("plain")
+	l.SetLevel(types.WarnLevel)
+	l.DebugCtx(ctx, "hidden")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	if lines[0]["correlationId"] != "cid-call" || lines[0]["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || lines[0]["k"] != "v" {
+		t.Errorf("Expected the context values on the line, got %v", lines[0])
+	}
+	if _, ok := lines[1]["correlationId"]; ok {
+		t.Errorf("Expected the logger itself to be unchanged, got %v", lines[1])
+	}
+}
+
+func TestLoggerWithContextDeadline(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	log := l.WithContext(ctx)
+	log.Info("pending")
+	cancel()
+	log.Info("cancelled")
+	l.WithContext(context.Background()).Info("no deadline")
+
+	lines := decodeLines(t, buf)
+	if ms, ok := lines[0]["deadlineMs"].(float64); !ok || ms <= 0 || ms > 60000 {
+		t.Errorf("Expected deadlineMs within the timeout, got %v", lines[0]["deadlineMs"])
+	}
+	if _, ok := lines[0]["ctxCancelled"]; ok {
+		t.Error("Expected no ctxCancelled before cancel")
+	}
+	if lines[1]["ctxCancelled"] != true {
+		t.Errorf("Expected ctxCancelled true, got %v", lines[1]["ctxCancelled"])
+	}
+	if _, ok := lines[2]["deadlineMs"]; ok {
+		t.Error("Expected no deadlineMs without a deadline")
+	}
+}
+
+func TestLogExternalCallSuccess(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithCorrelationID("cid-ext").LogExternalCall("payments", "charge", 200, 1500*time.Microsecond, nil)
+
+	line := lastLine(t, buf)
+	if line["level"] != "info" {
+		t.Errorf("Expected level 'info', got %v", line["level"])
+	}
+	if line["external.service"] != "payments" || line["external.operation"] != "charge" {
+		t.Errorf("Expected external service/operation, got %v", line)
+	}
+	if line["external.statusCode"] != float64(200) {
+		t.Errorf("Expected statusCode 200, got %v", line["external.statusCode"])
+	}
+	if line["durationMs"] != 1.5 {
+		t.Errorf("Expected durationMs 1.5, got %v", line["durationMs"])
+	}
+	if line["outcome"] != "success" {
+		t.Errorf("Expected outcome 'success', got %v", line["outcome"])
+	}
+	if line["correlationId"] != "cid-ext" {
+		t.Errorf("Expected correlationId 'cid-ext', got %v", line["correlationId"])
+	}
+	if _, ok := line["error"]; ok {
+		t.Error("Expected no error field on success")
+	}
+}
+
+func TestLogExternalCallFailureLevels(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		err        error
+		level      string
+	}{
+		{"client error", 404, nil, "warning"},
+		{"server error", 503, nil, "error"},
+		{"transport error", 0, errors.New("connection refused"), "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, buf := newTestLogger(types.LogOptions{})
+
+			l.LogExternalCall("crm", "getContact", tt.statusCode, time.Millisecond, tt.err)
+
+			line := lastLine(t, buf)
+			if line["level"] != tt.level {
+				t.Errorf("Expected level '%s', got %v", tt.level, line["level"])
+			}
+			if line["outcome"] != "failure" {
+				t.Errorf("Expected outcome 'failure', got %v", line["outcome"])
+			}
+			if tt.err != nil && line["error"] != tt.err.Error() {
+				t.Errorf("Expected error '%s', got %v", tt.err, line["error"])
+			}
+		})
+	}
+}
+
+func TestLoggerRedactsFields(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithFields(map[string]interface{}{"token": "abc"}).Info("login", map[string]interface{}{
+		"user":     "joao@example.com",
+		"password": "hunter2",
+	})
+
+	line := lastLine(t, buf)
+	if line["token"] != "***" || line["password"] != "***" || line["user"] != "***" {
+		t.Errorf("Expected sensitive fields to be masked, got %v", line)
+	}
+	if _, ok := line["redactionSummary"]; ok {
+		t.Error("Expected no redactionSummary when the option is disabled")
+	}
+}
+
+func TestLoggerDisableRedaction(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{DisableRedaction: true, Redactor: redactor.DefaultRedactor()})
+
+	l.WithError(errors.New("token=abc rejected")).Info("login", map[string]interface{}{"password": "hunter2"})
+
+	line := lastLine(t, buf)
+	if line["password"] != "hunter2" {
+		t.Errorf("Expected the field to be logged as is, got %v", line["password"])
+	}
+	if logErr, _ := line["error"].(map[string]interface{}); logErr["message"] != "token=abc rejected" {
+		t.Errorf("Expected the error message to be logged as is, got %v", line["error"])
+	}
+	if !l.skipRedaction {
+		t.Error("Expected the redaction pass to be skipped")
+	}
+}
+
+func TestLoggerRedactionSummary(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{RedactionSummary: true})
+
+	l.Info("payment", map[string]interface{}{
+		"password": "hunter2",
+		"card":     "4111111111111111",
+		"cvv":      123,
+		"contact":  "mail joao@example.com",
+		"amount":   10,
+	})
+	l.Info("nothing sensitive", map[string]interface{}{"amount": 10})
+
+	lines := decodeLines(t, buf)
+	summary, ok := lines[0]["redactionSummary"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected redactionSummary, got %v", lines[0])
+	}
+	if summary["keysRedacted"] != float64(3) {
+		t.Errorf("Expected 3 keys redacted, got %v", summary["keysRedacted"])
+	}
+	// hunter2 (7) + card (16) + joao@example.com (16); cvv is not a string.
+	if summary["charsRedacted"] != float64(39) {
+		t.Errorf("Expected 39 chars redacted, got %v", summary["charsRedacted"])
+	}
+	if _, ok := lines[1]["redactionSummary"]; ok {
+		t.Error("Expected no redactionSummary when nothing was redacted")
+	}
+}
+
+func TestLoggerWithPrefix(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Service: "api"})
+
+	cache := l.WithFields(map[string]interface{}{"region": "us"}).WithPrefix("cache")
+	cache.WithFields(map[string]interface{}{"hits": 3}).Info("stats", map[string]interface{}{"misses": 1})
+	cache.WithPrefix("l2").Info("nested", map[string]interface{}{"size": 10})
+
+	lines := decodeLines(t, buf)
+	first := lines[0]
+	if first["cache.hits"] != float64(3) || first["cache.misses"] != float64(1) {
+		t.Errorf("Expected prefixed keys, got %v", first)
+	}
+	if first["region"] != "us" || first["service"] != "api" {
+		t.Errorf("Expected fields added before WithPrefix to keep their keys, got %v", first)
+	}
+	if _, ok := first["cache"]; ok {
+		t.Error("Expected prefixed keys to stay flat, not nested")
+	}
+	if lines[1]["cache.l2.size"] != float64(10) {
+		t.Errorf("Expected chained prefix 'cache.l2.size', got %v", lines[1])
+	}
+}
+
+func TestLoggerWithPrefixStillRedacts(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	db := l.WithPrefix("db")
+	db.WithFields(map[string]interface{}{"password": "hunter2"}).Info("connect", map[string]interface{}{"token": "abc"})
+
+	line := lastLine(t, buf)
+	if line["db.password"] != "***" || line["db.token"] != "***" {
+		t.Errorf("Expected prefixed sensitive keys to be masked, got %v", line)
+	}
+}
+
+func TestLogFlagEvaluation(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Level: types.DebugLevel})
+
+	l.WithCorrelationID("cid-flag").LogFlagEvaluation("new-checkout", true, "targeting-match")
+
+	line := lastLine(t, buf)
+	if line["level"] != "debug" {
+		t.Errorf("Expected level 'debug', got %v", line["level"])
+	}
+	if line["flag.name"] != "new-checkout" || line["flag.value"] != true || line["flag.reason"] != "targeting-match" {
+		t.Errorf("Expected flag fields, got %v", line)
+	}
+	if line["correlationId"] != "cid-flag" {
+		t.Errorf("Expected correlationId 'cid-flag', got %v", line["correlationId"])
+	}
+}
+
+func TestLogFlagEvaluationRespectsLevel(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{Level: types.InfoLevel})
+
+	l.LogFlagEvaluation("new-checkout", "variant-b", "rollout")
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output at info level, got %s", buf.String())
+	}
+}
+
+func TestLoggerAnnotatesPIIInDevelopment(t *testing.T) {
+	tests := []struct {
+		environment string
+		email       string
+		password    string
+	}{
+		{"development", "joao@example.com [PII:email]", "hunter2 [PII:password]"},
+		{"production", "***", "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			l, buf := newTestLogger(types.LogOptions{Environment: tt.environment})
+
+			l.Info("signup", map[string]interface{}{"email": "joao@example.com", "password": "hunter2"})
+
+			line := lastLine(t, buf)
+			if line["email"] != tt.email || line["password"] != tt.password {
+				t.Errorf("Expected email %q and password %q, got %v", tt.email, tt.password, line)
+			}
+		})
+	}
+}
+
+// captureSink records the entries written by the logger.
+type captureSink struct {
+	entries []types.LogEntry
+}
+
+func (s *captureSink) Write(entry types.LogEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *captureSink) Close() error { return nil }
+
+func TestLoggerHooks(t *testing.T) {
+	enrich := func(entry *types.LogEntry) bool {
+		entry.Fields["host"] = "web-1"
+		entry.Message = strings.ToUpper(entry.Message)
+		return true
+	}
+	dropDebug := func(entry *types.LogEntry) bool {
+		return entry.Level != types.DebugLevel
+	}
+	called := 0
+	count := func(*types.LogEntry) bool {
+		called++
+		return true
+	}
+	hooks := []func(*types.LogEntry) bool{enrich, dropDebug, count}
+
+	l, buf := newTestLogger(types.LogOptions{Level: types.DebugLevel, Hooks: hooks})
+	l.WithError(errors.New("boom")).Info("saved", map[string]interface{}{"id": 1})
+	l.Debug("dropped")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || called != 1 {
+		t.Fatalf("Expected the debug line to be dropped before later hooks, got %d lines and %d calls", len(lines), called)
+	}
+	if lines[0]["message"] != "SAVED" || lines[0]["host"] != "web-1" || lines[0]["id"] != float64(1) {
+		t.Errorf("Expected the hooks' changes in the output, got %v", lines[0])
+	}
+	if _, ok := lines[0]["error"]; !ok {
+		t.Errorf("Expected the error to be kept, got %v", lines[0])
+	}
+
+	sink := &captureSink{}
+	l, _ = newTestLogger(types.LogOptions{Level: types.DebugLevel, Hooks: hooks, Sink: sink})
+	l.Info("saved")
+	l.Debug("dropped")
+	if len(sink.entries) != 1 || sink.entries[0].Fields["host"] != "web-1" {
+		t.Errorf("Expected one enriched entry in the sink, got %v", sink.entries)
+	}
+}
+
+func TestLoggerPreservesFieldTypes(t *testing.T) {
+	type item struct {
+		Qty   int
+		Price float64
+		Gift  bool
+	}
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink})
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	l.WithFields(map[string]interface{}{"retries": 3, "cached": false}).
+		Info("typed", map[string]interface{}{"item": item{Qty: 2, Price: 9.5, Gift: true}, "at": ts})
+
+	fields := sink.entries[0].Fields
+	if fields["retries"] != 3 || fields["cached"] != false || fields["at"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Expected int, bool and RFC3339 time, got %#v", fields)
+	}
+	if it := fields["item"].(map[string]interface{}); it["Qty"] != 2 || it["Price"] != 9.5 || it["Gift"] != true {
+		t.Errorf("Expected nested struct fields to keep their types, got %#v", it)
+	}
+}
+
+func TestLoggerWritesToSink(t *testing.T) {
+	sink := &captureSink{}
+	l, buf := newTestLogger(types.LogOptions{Service: "orders", Level: types.InfoLevel, Sink: sink})
+
+	l.WithCorrelationID("cid-sink").WithFields(map[string]interface{}{"orderId": "42"}).
+		Warn("slow order", map[string]interface{}{"password": "hunter2"})
+	l.Debug("suppressed")
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no stdout output with a sink, got %s", buf.String())
+	}
+	if len(sink.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(sink.entries))
+	}
+	entry := sink.entries[0]
+	if entry.Level != types.WarnLevel {
+		t.Errorf("Expected level warn, got %v", entry.Level)
+	}
+	if entry.CorrelationID != "cid-sink" {
+		t.Errorf("Expected correlationId 'cid-sink', got %v", entry.CorrelationID)
+	}
+	if entry.Timestamp.IsZero() {
+		t.Error("Expected timestamp to be set")
+	}
+	if entry.Message != "slow order" {
+		t.Errorf("Expected message 'slow order', got %q", entry.Message)
+	}
+	if entry.Fields["orderId"] != "42" || entry.Fields["service"] != "orders" {
+		t.Errorf("Expected merged fields, got %v", entry.Fields)
+	}
+	if entry.Fields["password"] != "***" {
+		t.Errorf("Expected redacted password, got %v", entry.Fields["password"])
+	}
+}
+
+func TestLoggerWithNamespace(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink})
+
+	db := l.WithFields(map[string]interface{}{"service": "api"}).WithNamespace("db")
+	db = db.WithFields(map[string]interface{}{"rows": 10})
+	db.WithNamespace("query").Info("done", map[string]interface{}{"ms": 5, "password": "hunter2"})
+	db.Info("again")
+
+	fields := sink.entries[0].Fields
+	nested, ok := fields["db"].(map[string]interface{})
+	if !ok || fields["service"] != "api" || nested["rows"] != 10 {
+		t.Fatalf("Expected rows nested under db, got %v", fields)
+	}
+	query, ok := nested["query"].(map[string]interface{})
+	if !ok || query["ms"] != 5 || query["password"] == "hunter2" {
+		t.Errorf("Expected redacted fields nested under db.query, got %v", nested["query"])
+	}
+	if again := sink.entries[1].Fields["db"].(map[string]interface{}); len(again) != 1 {
+		t.Errorf("Expected the parent's namespace to be unchanged, got %v", again)
+	}
+}
+
+func TestLoggerDisabledLevelDoesNotAllocate(t *testing.T) {
+	log := NewLogger(types.LogOptions{Level: types.WarnLevel, Output: io.Discard}).(*logger)
+	fields := map[string]interface{}{"orderId": 42}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Trace("ignored", fields)
+		log.Debug("ignored", fields)
+		log.Info("ignored", fields)
+		log.LogExternalCall("svc", "op", 200, time.Millisecond, nil)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestLoggerWithKeyValues(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.WithField("orderId", 42).With("status", "paid", "items", 3).Info("order processed")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	if line["orderId"] != float64(42) || line["status"] != "paid" || line["items"] != float64(3) {
+		t.Errorf("Expected orderId, status and items fields, got %v", line)
+	}
+}
+
+type failingSink struct{ err error }
+
+func (s failingSink) Write(types.LogEntry) error { return s.err }
+func (s failingSink) Close() error               { return nil }
+
+func TestLoggerOnSinkError(t *testing.T) {
+	sinkErr := errors.New("connection refused")
+	var gotEntry types.LogEntry
+	var gotErr error
+	l, _ := newTestLogger(types.LogOptions{
+		Sink: failingSink{err: sinkErr},
+		OnSinkError: func(entry types.LogEntry, err error) {
+			gotEntry, gotErr = entry, err
+		},
+	})
+
+	l.Info("order processed")
+
+	if gotErr != sinkErr {
+		t.Errorf("Expected the sink error, got %v", gotErr)
+	}
+	if gotEntry.Message != "order processed" {
+		t.Errorf("Expected the failed entry, got %+v", gotEntry)
+	}
+}
+
+func TestLoggerRedactorReconfiguredAtRuntime(t *testing.T) {
+	r := redactor.DefaultRedactor()
+	l, buf := newTestLogger(types.LogOptions{Redactor: r})
+
+	l.Info("before", map[string]interface{}{"sessionId": "s-1"})
+	opts := r.Options()
+	opts.Keys = append(opts.Keys, "sessionId")
+	r.Reconfigure(opts)
+	l.Info("after", map[string]interface{}{"sessionId": "s-1"})
+
+	lines := decodeLines(t, buf)
+	if lines[0]["sessionId"] != "s-1" {
+		t.Errorf("Expected sessionId to be visible before reconfiguring, got %v", lines[0]["sessionId"])
+	}
+	if lines[1]["sessionId"] != "***" {
+		t.Errorf("Expected sessionId to be masked after reconfiguring, got %v", lines[1]["sessionId"])
+	}
+}
+
+func TestLoggerRuntimeInfoAndStaticFields(t *testing.T) {
+	hostname, _ := os.Hostname()
+	l, buf := newTestLogger(types.LogOptions{
+		IncludeRuntimeInfo: true,
+		StaticFields: map[string]interface{}{
+			"k8s.pod":  "orders-7d9f8b6c5-x2x4k",
+			"k8s.node": "node-a3ce929d0e0e4736a3ce929d0e0e4736",
+			"token":    "static",
+		},
+	})
+
+	l.WithPrefix("db").Info("ready", map[string]interface{}{"k8s.node": "override"})
+	l.Info("moved", map[string]interface{}{"k8s.node": "override"})
+
+	lines := decodeLines(t, buf)
+	line := lines[0]
+	if line["hostname"] != hostname || line["pid"] != float64(os.Getpid()) || line["goVersion"] != runtime.Version() {
+		t.Errorf("Expected hostname, pid and goVersion, got %v", line)
+	}
+	if line["k8s.pod"] != "orders-7d9f8b6c5-x2x4k" || line["token"] != "static" {
+		t.Errorf("Expected unredacted static fields, got %v", line)
+	}
+	if line["k8s.node"] != "node-a3ce929d0e0e4736a3ce929d0e0e4736" || line["db.k8s.node"] != "override" {
+		t.Errorf("Expected static fields to stay unprefixed, got %v", line)
+	}
+	if lines[1]["k8s.node"] != "override" {
+		t.Errorf("Expected logged fields to take precedence, got %v", lines[1]["k8s.node"])
+	}
+}
+
+func TestLoggerSequence(t *testing.T) {
+	seq := &types.Sequence{}
+	sink := &captureSink{}
+	parent := NewLogger(types.LogOptions{Sink: sink, Sequence: seq})
+	l, buf := newTestLogger(types.LogOptions{Sequence: seq})
+
+	parent.Info("one")
+	parent.WithFields(map[string]interface{}{"child": true}).Info("two")
+	l.Info("three")
+	parent.Debug("filtered")
+	parent.Info("four")
+
+	var got []uint64
+	for _, entry := range sink.entries {
+		got = append(got, entry.Sequence)
+		if entry.Fields["sequence"] != entry.Sequence {
+			t.Errorf("Expected the sequence field to match, got %v", entry.Fields["sequence"])
+		}
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 4 {
+		t.Errorf("Expected sequences 1, 2 and 4, got %v", got)
+	}
+	if line := lastLine(t, buf); line["sequence"] != float64(3) {
+		t.Errorf("Expected sequence 3 on the shared counter, got %v", line["sequence"])
+	}
+}
+
+func TestLoggerWithoutSequence(t *testing.T) {
+	sink := &captureSink{}
+	NewLogger(types.LogOptions{Sink: sink}).Info("unnumbered")
+
+	if _, ok := sink.entries[0].Fields["sequence"]; ok || sink.entries[0].Sequence != 0 {
+		t.Errorf("Expected no sequence by default, got %+v", sink.entries[0])
+	}
+}
+
+func TestLoggerWithStack(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{MaxStackFrames: 3})
+
+	traced := l.WithPrefix("db").WithStack()
+	traced.Warn("unexpected retry", map[string]interface{}{"attempt": 2})
+	l.Info("no stack")
+
+	lines := decodeLines(t, buf)
+	stack, _ := lines[0]["stack"].(string)
+	if !strings.HasPrefix(stack, "github.com/mateusmacedo/boyscout/go-logger/internal/logger.TestLoggerWithStack\n\t") {
+		t.Errorf("Expected the stack to start at the caller of WithStack, got %q", stack)
+	}
+	if !strings.Contains(stack, "logger_test.go:") {
+		t.Errorf("Expected readable file and line, got %q", stack)
+	}
+	if frames := strings.Count(stack, "\n\t"); frames != 3 {
+		t.Errorf("Expected 3 frames, got %d", frames)
+	}
+	if lines[0]["db.attempt"] != float64(2) {
+		t.Errorf("Expected prefixed fields to be kept, got %v", lines[0])
+	}
+	if _, ok := lines[1]["stack"]; ok {
+		t.Error("Expected no stack on the parent logger")
+	}
+}
+
+func TestLoggerWithStackToSink(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink})
+
+	l.WithStack().Info("checkpoint")
+
+	if len(sink.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(sink.entries))
+	}
+	if stack, _ := sink.entries[0].Fields["stack"].(string); !strings.Contains(stack, "TestLoggerWithStackToSink") {
+		t.Errorf("Expected the stack in the entry fields, got %q", stack)
+	}
+}
+
+type nilError struct{}
+
+func (*nilError) Error() string { return "nil error" }
+
+func TestLoggerKeepsFieldsNextToTypedNilError(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	l.Info("hi", map[string]interface{}{"user": "bob", "err": (*nilError)(nil)})
+
+	line := lastLine(t, buf)
+	if line["user"] != "bob" {
+		t.Errorf("Expected user 'bob', got %v", line)
+	}
+	if v, ok := line["err"]; !ok || v != nil {
+		t.Errorf("Expected err to be null, got %v", line["err"])
+	}
+}
@@ -0,0 +1,38 @@
+package logger
+
+import "github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+
+func (l *logger) WithNamespace(name string) types.Logger {
+	child := l.clone()
+	if name != "" {
+		child.namespace = append(l.namespace[:len(l.namespace):len(l.namespace)], name)
+	}
+	return child
+}
+
+// nest returns fields placed under the logger's namespace, as the single
+// top-level entry to store in data. Maps already at that path in data are
+// merged with fields; they are copied, never modified, because they are
+// shared with the logger's parents.
+func (l *logger) nest(data map[string]interface{}, fields map[string]interface{}) (string, map[string]interface{}) {
+	inner, _ := data[l.namespace[0]].(map[string]interface{})
+	return l.namespace[0], mergeAt(inner, l.namespace[1:], fields)
+}
+
+// mergeAt returns a copy of base with fields merged at path. A value at
+// path that is not a map is replaced.
+func mergeAt(base map[string]interface{}, path []string, fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(fields))
+	for k, v := range base {
+		out[k] = v
+	}
+	if len(path) == 0 {
+		for k, v := range fields {
+			out[k] = v
+		}
+		return out
+	}
+	inner, _ := base[path[0]].(map[string]interface{})
+	out[path[0]] = mergeAt(inner, path[1:], fields)
+	return out
+}
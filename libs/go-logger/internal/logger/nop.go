@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// nopLogger discards everything logged through it.
+type nopLogger struct{}
+
+// NopLogger returns a logger that discards everything, for tests and for
+// libraries whose callers did not configure logging. Its With* methods
+// return the logger itself, so it never allocates.
+func NopLogger() types.Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Trace(string, ...map[string]interface{}) {}
+func (nopLogger) Debug(string, ...map[string]interface{}) {}
+func (nopLogger) Info(string, ...map[string]interface{})  {}
+func (nopLogger) Warn(string, ...map[string]interface{})  {}
+func (nopLogger) Error(string, ...map[string]interface{}) {}
+func (nopLogger) Fatal(string, ...map[string]interface{}) {}
+
+func (nopLogger) DebugCtx(context.Context, string, ...map[string]interface{}) {}
+func (nopLogger) InfoCtx(context.Context, string, ...map[string]interface{})  {}
+func (nopLogger) WarnCtx(context.Context, string, ...map[string]interface{})  {}
+func (nopLogger) ErrorCtx(context.Context, string, ...map[string]interface{}) {}
+
+func (n nopLogger) WithFields(map[string]interface{}) types.Logger { return n }
+func (n nopLogger) WithField(string, interface{}) types.Logger     { return n }
+func (n nopLogger) With(...interface{}) types.Logger               { return n }
+func (n nopLogger) WithContext(context.Context) types.Logger       { return n }
+func (n nopLogger) WithCorrelationID(string) types.Logger          { return n }
+func (n nopLogger) WithPrefix(string) types.Logger                 { return n }
+func (n nopLogger) WithNamespace(string) types.Logger              { return n }
+func (n nopLogger) Named(string) types.Logger                      { return n }
+func (n nopLogger) WithError(error) types.Logger                   { return n }
+func (n nopLogger) WithStack() types.Logger                        { return n }
+
+func (nopLogger) Close() error { return nil }
+
+func (nopLogger) SetLevel(types.LogLevel)  {}
+func (nopLogger) GetLevel() types.LogLevel { return types.InfoLevel }
+
+func (nopLogger) LogExternalCall(string, string, int, time.Duration, error) {}
+func (nopLogger) LogFlagEvaluation(string, interface{}, string)             {}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNopLoggerDoesNotAllocate(t *testing.T) {
+	log := NopLogger()
+	fields := map[string]interface{}{"k": "v"}
+	err := errors.New("boom")
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		child := log.WithFields(fields).WithContext(ctx).WithCorrelationID("cid").WithPrefix("p").WithError(err)
+		child.Info("ignored", fields)
+		child.Error("ignored")
+		child.LogExternalCall("svc", "op", 500, time.Millisecond, err)
+		child.LogFlagEvaluation("flag", true, "default")
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestNopLoggerChildrenAreItself(t *testing.T) {
+	log := NopLogger()
+
+	if log.WithFields(nil) != log || log.WithContext(context.Background()) != log || log.WithCorrelationID("cid") != log {
+		t.Error("Expected With* to return the logger itself")
+	}
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	rateLimitShards = 16
+	// rateLimitSweepSize is the shard size above which expired buckets are
+	// removed, so one-off messages do not accumulate.
+	rateLimitSweepSize = 1024
+)
+
+// rateLimiter suppresses repeated lines with the same level and message
+// beyond MaxPerInterval per Interval. Buckets are spread over mutex
+// guarded shards so concurrent loggers rarely contend.
+type rateLimiter struct {
+	options types.RateLimit
+	shards  [rateLimitShards]rateLimitShard
+}
+
+type rateLimitShard struct {
+	mu      sync.Mutex
+	buckets map[rateLimitKey]*rateLimitBucket
+}
+
+type rateLimitKey struct {
+	level types.LogLevel
+	msg   string
+}
+
+// rateLimitBucket counts the lines of one key during one interval.
+type rateLimitBucket struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+func newRateLimiter(options types.RateLimit) *rateLimiter {
+	r := &rateLimiter{options: options}
+	for i := range r.shards {
+		r.shards[i].buckets = make(map[rateLimitKey]*rateLimitBucket)
+	}
+	return r
+}
+
+// allow reports whether a line may be emitted. The first suppressed line
+// of an interval schedules summarize to run with the number of suppressed
+// lines once the interval ends.
+func (r *rateLimiter) allow(level types.LogLevel, msg string, summarize func(suppressed int)) bool {
+	key := rateLimitKey{level: level, msg: msg}
+	shard := &r.shards[shardIndex(level, msg)]
+	now := time.Now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	b := shard.buckets[key]
+	if b == nil || now.Sub(b.start) >= r.options.Interval {
+		if len(shard.buckets) >= rateLimitSweepSize {
+			r.sweep(shard, now)
+		}
+		b = &rateLimitBucket{start: now}
+		shard.buckets[key] = b
+	}
+	if b.count < r.options.MaxPerInterval {
+		b.count++
+		return true
+	}
+
+	b.suppressed++
+	if b.suppressed == 1 {
+		time.AfterFunc(b.start.Add(r.options.Interval).Sub(now), func() {
+			shard.mu.Lock()
+			n := b.suppressed
+			if shard.buckets[key] == b {
+				delete(shard.buckets, key)
+			}
+			shard.mu.Unlock()
+			summarize(n)
+		})
+	}
+	return false
+}
+
+// sweep removes buckets whose interval is over and that have no pending
+// summary; the shard lock must be held.
+func (r *rateLimiter) sweep(shard *rateLimitShard, now time.Time) {
+	for key, b := range shard.buckets {
+		if b.suppressed == 0 && now.Sub(b.start) >= r.options.Interval {
+			delete(shard.buckets, key)
+		}
+	}
+}
+
+func shardIndex(level types.LogLevel, msg string) int {
+	h := fnv.New32a()
+	h.Write([]byte(level))
+	h.Write([]byte(msg))
+	return int(h.Sum32() % rateLimitShards)
+}
+
+// suppressedSummary describes the lines dropped by the rate limiter.
+func suppressedSummary(level types.LogLevel, msg string, n int) (string, map[string]interface{}) {
+	return fmt.Sprintf("%d messages suppressed", n), map[string]interface{}{
+		"suppressed.count":   n,
+		"suppressed.level":   string(level),
+		"suppressed.message": msg,
+	}
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestLoggerRateLimit(t *testing.T) {
+	sink := &lockedSink{}
+	l, _ := newTestLogger(types.LogOptions{
+		Sink:      sink,
+		RateLimit: types.RateLimit{MaxPerInterval: 3, Interval: 50 * time.Millisecond},
+	})
+
+	for i := 0; i < 10; i++ {
+		l.Error("db down")
+	}
+	l.Error("other error")
+	l.Warn("db down")
+
+	if got := len(sink.Entries()); got != 5 {
+		t.Fatalf("Expected 5 lines before the interval ends, got %d", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(sink.Entries()) < 6 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	entries := sink.Entries()
+	if len(entries) != 6 {
+		t.Fatalf("Expected a summary line, got %d lines", len(entries))
+	}
+	summary := entries[5]
+	if summary.Message != "7 messages suppressed" || summary.Level != types.ErrorLevel {
+		t.Errorf("Expected error summary of 7 suppressed lines, got %+v", summary)
+	}
+	if summary.Fields["suppressed.message"] != "db down" || summary.Fields["suppressed.count"] != 7 {
+		t.Errorf("Expected summary fields, got %v", summary.Fields)
+	}
+
+	l.Error("db down")
+	if got := len(sink.Entries()); got != 7 {
+		t.Errorf("Expected a new interval to allow the message again, got %d lines", got)
+	}
+}
+
+func TestLoggerRateLimitDisabledByDefault(t *testing.T) {
+	l, buf := newTestLogger(types.LogOptions{})
+
+	for i := 0; i < 100; i++ {
+		l.Info("same")
+	}
+
+	if got := len(decodeLines(t, buf)); got != 100 {
+		t.Errorf("Expected 100 lines, got %d", got)
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	r := newRateLimiter(types.RateLimit{MaxPerInterval: 10, Interval: time.Hour})
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed = map[string]int{}
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				msg := fmt.Sprintf("msg-%d", i%20)
+				if r.allow(types.InfoLevel, msg, func(int) {}) {
+					mu.Lock()
+					allowed[msg]++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for msg, n := range allowed {
+		if n != 10 {
+			t.Errorf("Expected 10 lines allowed for %s, got %d", msg, n)
+		}
+	}
+	if len(allowed) != 20 {
+		t.Errorf("Expected 20 distinct messages, got %d", len(allowed))
+	}
+}
+
+func TestRateLimiterSweepsExpiredBuckets(t *testing.T) {
+	r := newRateLimiter(types.RateLimit{MaxPerInterval: 1, Interval: time.Millisecond})
+
+	for i := 0; i < 5*rateLimitSweepSize*rateLimitShards; i++ {
+		r.allow(types.InfoLevel, fmt.Sprintf("unique-%d", i), func(int) {})
+		if i%1000 == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+
+	for i := range r.shards {
+		if n := len(r.shards[i].buckets); n > 2*rateLimitSweepSize {
+			t.Errorf("Expected shard %d to be swept, has %d buckets", i, n)
+		}
+	}
+}
+
+// lockedSink is a captureSink safe for the rate limiter's summary goroutine.
+type lockedSink struct {
+	mu sync.Mutex
+	captureSink
+}
+
+func (s *lockedSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.captureSink.Write(entry)
+}
+
+func (s *lockedSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
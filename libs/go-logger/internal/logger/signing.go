@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var signatureMarker = []byte(`,"signature":"`)
+
+// signingFormatter appends a chained HMAC-SHA256 signature to every JSON
+// line produced by inner. Each signature covers the previous signature and
+// the serialized line, so altered, reordered or deleted lines break the
+// chain.
+type signingFormatter struct {
+	inner logrus.Formatter
+	key   []byte
+
+	mu       sync.Mutex
+	previous []byte
+}
+
+func newSigningFormatter(inner logrus.Formatter, key []byte) *signingFormatter {
+	return &signingFormatter{inner: inner, key: key}
+}
+
+func (f *signingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	out, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	payload := bytes.TrimRight(out, "\n")
+	if len(payload) < 2 || payload[len(payload)-1] != '}' {
+		return nil, errors.New("log signing requires a JSON formatter")
+	}
+
+	f.mu.Lock()
+	signature := sign(f.key, f.previous, payload)
+	f.previous = signature
+	f.mu.Unlock()
+
+	signed := make([]byte, 0, len(payload)+len(signatureMarker)+len(signature)+3)
+	signed = append(signed, payload[:len(payload)-1]...)
+	signed = append(signed, signatureMarker...)
+	signed = append(signed, signature...)
+	signed = append(signed, '"', '}', '\n')
+	return signed, nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of previous followed by payload.
+func sign(key, previous, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(previous)
+	mac.Write(payload)
+	sum := mac.Sum(nil)
+	out := make([]byte, hex.EncodedLen(len(sum)))
+	hex.Encode(out, sum)
+	return out
+}
+
+// VerifySignedLines checks the signature chain of lines written by a logger
+// configured with LogOptions.SigningKey. Lines must be complete and in the
+// order they were written, starting from the logger's first line. It
+// returns an error identifying the first line that fails verification.
+func VerifySignedLines(key []byte, lines [][]byte) error {
+	var previous []byte
+	for i, line := range lines {
+		line = bytes.TrimRight(line, "\n")
+		idx := bytes.LastIndex(line, signatureMarker)
+		if idx < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+			return fmt.Errorf("line %d: missing signature", i+1)
+		}
+		signature := line[idx+len(signatureMarker) : len(line)-2]
+
+		payload := make([]byte, 0, idx+1)
+		payload = append(payload, line[:idx]...)
+		payload = append(payload, '}')
+
+		expected := sign(key, previous, payload)
+		if !hmac.Equal(signature, expected) {
+			return fmt.Errorf("line %d: signature mismatch", i+1)
+		}
+		previous = expected
+	}
+	return nil
+}
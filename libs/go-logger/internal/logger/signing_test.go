@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func signedLines(t *testing.T, key []byte) [][]byte {
+	t.Helper()
+	l, buf := newTestLogger(types.LogOptions{SigningKey: key})
+
+	l.Info("payment captured", map[string]interface{}{"status": "paid", "amount": 10})
+	l.Warn("retrying webhook", map[string]interface{}{"attempt": 2})
+	l.Info("refund issued", map[string]interface{}{"amount": 5})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	for _, line := range lines {
+		if !bytes.Contains(line, []byte(`"signature":"`)) {
+			t.Fatalf("Expected signature field in %s", line)
+		}
+	}
+	return lines
+}
+
+func TestSignedLinesVerify(t *testing.T) {
+	key := []byte("audit-secret")
+	lines := signedLines(t, key)
+
+	if err := VerifySignedLines(key, lines); err != nil {
+		t.Errorf("Expected chain to verify, got %v", err)
+	}
+	if err := VerifySignedLines([]byte("other-key"), lines); err == nil {
+		t.Error("Expected verification with a different key to fail")
+	}
+}
+
+func TestSigningFallsBackFromJSONBackend(t *testing.T) {
+	key := []byte("audit-secret")
+	l, buf := newTestLogger(types.LogOptions{Backend: types.JSONBackend, SigningKey: key})
+
+	l.Info("payment captured")
+	l.Info("refund issued")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if err := VerifySignedLines(key, lines); err != nil {
+		t.Errorf("Expected signed lines despite the json backend, got %v", err)
+	}
+}
+
+func TestSignedLinesDetectTampering(t *testing.T) {
+	key := []byte("audit-secret")
+	lines := signedLines(t, key)
+
+	tampered := append([][]byte{}, lines...)
+	tampered[0] = bytes.Replace(tampered[0], []byte(`"paid"`), []byte(`"free"`), 1)
+	err := VerifySignedLines(key, tampered)
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected modified line 1 to fail, got %v", err)
+	}
+
+	deleted := [][]byte{lines[0], lines[2]}
+	err = VerifySignedLines(key, deleted)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected deletion to break the chain at line 2, got %v", err)
+	}
+
+	if err := VerifySignedLines(key, [][]byte{[]byte(`{"level":"info"}`)}); err == nil {
+		t.Error("Expected unsigned line to fail")
+	}
+}
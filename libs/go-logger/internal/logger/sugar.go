@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/mateusmacedo/boyscout/go-logger/internal/kv"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// SugaredLogger offers the printf-style (Infof) and key/value (Infow)
+// methods of zap's SugaredLogger on top of a Logger, to ease migrating
+// from zap. Messages are only formatted when their level is enabled.
+type SugaredLogger struct {
+	// base is the wrapped Logger and log the one lines are written to,
+	// skipping the frames of SugaredLogger when reporting the caller.
+	base types.Logger
+	log  types.Logger
+}
+
+// sugarFrames is the number of frames a SugaredLogger adds above the
+// Logger method: the public method (Infof, ...), logf or logw, emit and
+// logAt.
+const sugarFrames = 4
+
+// Sugar wraps log in a SugaredLogger.
+func Sugar(log types.Logger) *SugaredLogger {
+	s := &SugaredLogger{base: log, log: log}
+	if l, ok := log.(*logger); ok {
+		// A shallow copy shares l's level, so it is the same logger.
+		skipped := *l
+		skipped.callerSkip += sugarFrames
+		s.log = &skipped
+	}
+	return s
+}
+
+// Desugar returns the wrapped Logger.
+func (s *SugaredLogger) Desugar() types.Logger {
+	return s.base
+}
+
+// With returns a SugaredLogger whose lines carry the given alternating
+// keys and values, see Logger.With.
+func (s *SugaredLogger) With(args ...interface{}) *SugaredLogger {
+	return Sugar(s.base.With(args...))
+}
+
+func (s *SugaredLogger) Tracef(template string, args ...interface{}) {
+	s.logf(types.TraceLevel, template, args)
+}
+
+func (s *SugaredLogger) Debugf(template string, args ...interface{}) {
+	s.logf(types.DebugLevel, template, args)
+}
+
+func (s *SugaredLogger) Infof(template string, args ...interface{}) {
+	s.logf(types.InfoLevel, template, args)
+}
+
+func (s *SugaredLogger) Warnf(template string, args ...interface{}) {
+	s.logf(types.WarnLevel, template, args)
+}
+
+func (s *SugaredLogger) Errorf(template string, args ...interface{}) {
+	s.logf(types.ErrorLevel, template, args)
+}
+
+func (s *SugaredLogger) Fatalf(template string, args ...interface{}) {
+	s.logf(types.FatalLevel, template, args)
+}
+
+// Tracew logs msg with fields given as alternating keys and values. A
+// trailing key without a value is logged under with.dangling.
+func (s *SugaredLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	s.logw(types.TraceLevel, msg, keysAndValues)
+}
+
+func (s *SugaredLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	s.logw(types.DebugLevel, msg, keysAndValues)
+}
+
+func (s *SugaredLogger) Infow(msg string, keysAndValues ...interface{}) {
+	s.logw(types.InfoLevel, msg, keysAndValues)
+}
+
+func (s *SugaredLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	s.logw(types.WarnLevel, msg, keysAndValues)
+}
+
+func (s *SugaredLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	s.logw(types.ErrorLevel, msg, keysAndValues)
+}
+
+func (s *SugaredLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	s.logw(types.FatalLevel, msg, keysAndValues)
+}
+
+func (s *SugaredLogger) logf(level types.LogLevel, template string, args []interface{}) {
+	if !level.Enabled(s.log.GetLevel()) {
+		return
+	}
+	msg := template
+	if len(args) > 0 {
+		msg = fmt.Sprintf(template, args...)
+	}
+	s.emit(level, msg, nil)
+}
+
+func (s *SugaredLogger) logw(level types.LogLevel, msg string, keysAndValues []interface{}) {
+	if !level.Enabled(s.log.GetLevel()) {
+		return
+	}
+	fields, dangling, ok := kv.Fields(keysAndValues)
+	if ok {
+		if fields == nil {
+			fields = make(map[string]interface{}, 1)
+		}
+		fields[kv.DanglingKey] = dangling
+	}
+	s.emit(level, msg, fields)
+}
+
+func (s *SugaredLogger) emit(level types.LogLevel, msg string, fields map[string]interface{}) {
+	if fields == nil {
+		logAt(s.log, level, msg)
+		return
+	}
+	logAt(s.log, level, msg, fields)
+}
+
+// logAt calls the method of log for level.
+func logAt(log types.Logger, level types.LogLevel, msg string, fields ...map[string]interface{}) {
+	switch level {
+	case types.TraceLevel:
+		log.Trace(msg, fields...)
+	case types.DebugLevel:
+		log.Debug(msg, fields...)
+	case types.WarnLevel:
+		log.Warn(msg, fields...)
+	case types.ErrorLevel:
+		log.Error(msg, fields...)
+	case types.FatalLevel:
+		log.Fatal(msg, fields...)
+	default:
+		log.Info(msg, fields...)
+	}
+}
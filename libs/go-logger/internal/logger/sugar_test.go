@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// countingStringer counts how often it is formatted.
+type countingStringer struct{ calls *int }
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "formatted"
+}
+
+func TestSugaredLoggerPrintf(t *testing.T) {
+	sink := &captureSink{}
+	s := Sugar(NewLogger(types.LogOptions{Sink: sink}))
+	calls := 0
+
+	s.Infof("processed %d orders in %s", 3, "2s")
+	s.Debugf("skipped %v", countingStringer{&calls})
+	s.Errorf("no args %d")
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(sink.entries))
+	}
+	if sink.entries[0].Message != "processed 3 orders in 2s" || sink.entries[0].Level != types.InfoLevel {
+		t.Errorf("Expected the formatted info message, got %+v", sink.entries[0])
+	}
+	if sink.entries[1].Message != "no args %d" || sink.entries[1].Level != types.ErrorLevel {
+		t.Errorf("Expected the template as is without args, got %+v", sink.entries[1])
+	}
+	if calls != 0 {
+		t.Errorf("Expected disabled levels not to format, got %d calls", calls)
+	}
+}
+
+func TestSugaredLoggerKeyValues(t *testing.T) {
+	sink := &captureSink{}
+	s := Sugar(NewLogger(types.LogOptions{Sink: sink})).With("service", "billing")
+
+	s.Warnw("retrying", "attempt", 2, 3, "three", "password", "hunter2")
+	s.Infow("odd", "orderId", 42, "status")
+	s.Infow("plain")
+
+	fields := sink.entries[0].Fields
+	if sink.entries[0].Level != types.WarnLevel || fields["attempt"] != 2 || fields["3"] != "three" || fields["service"] != "billing" {
+		t.Errorf("Expected the key/value fields, got %v", fields)
+	}
+	if fields["password"] != "***" {
+		t.Errorf("Expected key/value fields to be redacted, got %v", fields["password"])
+	}
+	if odd := sink.entries[1].Fields; odd["orderId"] != 42 || odd["with.dangling"] != "status" {
+		t.Errorf("Expected the dangling key to be reported on the line, got %v", odd)
+	}
+	if len(sink.entries) != 3 || sink.entries[2].Message != "plain" {
+		t.Errorf("Expected a line without fields, got %+v", sink.entries)
+	}
+	if s.Desugar() == nil {
+		t.Error("Expected the wrapped logger")
+	}
+}
+
+func TestSugaredLoggerIncludeCaller(t *testing.T) {
+	sink := &captureSink{}
+	log := NewLogger(types.LogOptions{IncludeCaller: true, Sink: sink})
+	s := Sugar(log)
+
+	_, file, line, _ := runtime.Caller(0)
+	s.Infof("printf")
+	s.Warnw("key values", "k", "v")
+	s.With("k", "v").Errorw("derived")
+	s.Desugar().Info("desugared")
+
+	if len(sink.entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(sink.entries))
+	}
+	for i, entry := range sink.entries {
+		if entry.Scope.File != file || entry.Scope.Line != line+1+i {
+			t.Errorf("Expected caller %s:%d for %q, got %s:%d", file, line+1+i, entry.Message, entry.Scope.File, entry.Scope.Line)
+		}
+	}
+	if s.Desugar() != log {
+		t.Error("Expected Desugar to return the wrapped logger")
+	}
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// truncateFields shortens the string leaves of fields, including those in
+// nested maps and slices, to max bytes. fields is modified in place;
+// nested containers are copied before being changed since, without
+// redaction, they may still belong to the caller.
+func truncateFields(fields map[string]interface{}, max int) {
+	for k, v := range fields {
+		if out, changed := truncateValue(v, max); changed {
+			fields[k] = out
+		}
+	}
+}
+
+// truncateValue returns v with its long strings truncated, and whether
+// anything changed.
+func truncateValue(v interface{}, max int) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		if len(v) <= max {
+			return v, false
+		}
+		return truncateString(v, max), true
+	case map[string]interface{}:
+		var out map[string]interface{}
+		for k, item := range v {
+			item, changed := truncateValue(item, max)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]interface{}, len(v))
+				for key, orig := range v {
+					out[key] = orig
+				}
+			}
+			out[k] = item
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case []interface{}:
+		var out []interface{}
+		for i, item := range v {
+			item, changed := truncateValue(item, max)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = append([]interface{}(nil), v...)
+			}
+			out[i] = item
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	}
+	return v, false
+}
+
+// truncateString cuts s to at most max bytes, on a rune boundary, and
+// notes how many bytes were dropped.
+func truncateString(s string, max int) string {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…(truncated " + strconv.Itoa(len(s)-cut) + " bytes)"
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"abcdef", 4, "abcd…(truncated 2 bytes)"},
+		{"ação", 2, "a…(truncated 5 bytes)"},
+		{"ação", 3, "aç…(truncated 3 bytes)"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateString(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerMaxStringLength(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink, MaxStringLength: 8, StaticFields: map[string]interface{}{"pod": "orders-7d9f8b6c5-x2x4z"}})
+
+	l.Info("query", map[string]interface{}{
+		"sql":      "SELECT * FROM orders WHERE id = 1",
+		"short":    "ok",
+		"password": "a-very-long-password",
+		"nested":   map[string]interface{}{"blob": strings.Repeat("A", 20), "list": []interface{}{"0123456789", 1}},
+	})
+
+	fields := sink.entries[0].Fields
+	if fields["sql"] != "SELECT *…(truncated 25 bytes)" || fields["short"] != "ok" {
+		t.Errorf("Expected long strings to be truncated, got %v and %v", fields["sql"], fields["short"])
+	}
+	if fields["password"] != "***" {
+		t.Errorf("Expected the mask to be kept whole, got %v", fields["password"])
+	}
+	nested := fields["nested"].(map[string]interface{})
+	if nested["blob"] != "AAAAAAAA…(truncated 12 bytes)" || nested["list"].([]interface{})[0] != "01234567…(truncated 2 bytes)" {
+		t.Errorf("Expected nested strings to be truncated, got %v", nested)
+	}
+	if fields["pod"] != "orders-7d9f8b6c5-x2x4z" {
+		t.Errorf("Expected static fields to be left alone, got %v", fields["pod"])
+	}
+}
+
+func TestLoggerMaxStringLengthKeepsCallerMaps(t *testing.T) {
+	sink := &captureSink{}
+	l, _ := newTestLogger(types.LogOptions{Sink: sink, MaxStringLength: 4, DisableRedaction: true})
+	nested := map[string]interface{}{"blob": "abcdefgh"}
+
+	l.Info("unredacted", map[string]interface{}{"nested": nested})
+
+	if nested["blob"] != "abcdefgh" {
+		t.Errorf("Expected the caller's map to be left alone, got %v", nested)
+	}
+	if got := sink.entries[0].Fields["nested"].(map[string]interface{})["blob"]; got != "abcd…(truncated 4 bytes)" {
+		t.Errorf("Expected the logged copy to be truncated, got %v", got)
+	}
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/redactor"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// NewLoggerChecked is NewLogger that reports invalid options instead of
+// silently replacing them with defaults.
+func NewLoggerChecked(options types.LogOptions) (types.Logger, error) {
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+	return NewLogger(options), nil
+}
+
+// validateOptions returns the joined problems found in options, or nil.
+func validateOptions(options types.LogOptions) error {
+	var errs []error
+	if options.Level != "" {
+		if _, err := logrus.ParseLevel(string(options.Level)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid level %q", options.Level))
+		}
+	}
+	switch options.Backend {
+	case "", types.LogrusBackend, types.JSONBackend:
+	default:
+		errs = append(errs, fmt.Errorf("unknown backend %q", options.Backend))
+	}
+
+	switch options.Format {
+	case "", types.JSONFormat:
+		if len(options.SigningKey) > 0 && options.Backend == types.JSONBackend {
+			errs = append(errs, errors.New("SigningKey is not supported by the json backend"))
+		}
+	case types.TextFormat, types.LogfmtFormat:
+		if len(options.SigningKey) > 0 {
+			errs = append(errs, fmt.Errorf("SigningKey requires the json format, got %q", options.Format))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown format %q", options.Format))
+	}
+
+	if options.MaxStringLength < 0 {
+		errs = append(errs, errors.New("MaxStringLength must not be negative"))
+	}
+	if options.MaxStackFrames < 0 {
+		errs = append(errs, errors.New("MaxStackFrames must not be negative"))
+	}
+
+	rate := options.RateLimit
+	switch {
+	case rate.MaxPerInterval < 0 || rate.Interval < 0:
+		errs = append(errs, errors.New("rate limit must not be negative"))
+	case (rate.MaxPerInterval > 0) != (rate.Interval > 0):
+		errs = append(errs, errors.New("rate limit needs both MaxPerInterval and Interval"))
+	}
+
+	// Interfaces holding a nil pointer are not nil and would pass the
+	// defaulting in NewLogger, then panic on the first line.
+	for _, option := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"Sink", options.Sink},
+		{"Redactor", options.Redactor},
+		{"Output", options.Output},
+		{"Formatter", options.Formatter},
+	} {
+		if isNilValue(option.value) {
+			errs = append(errs, fmt.Errorf("%s is a nil %T", option.name, option.value))
+		}
+	}
+	// AnnotatePII logs masked values in clear, so a redactor built with it
+	// must not reach production by accident.
+	if r, ok := options.Redactor.(*redactor.Redactor); ok && r != nil && r.Options().AnnotatePII && options.Environment != developmentEnvironment {
+		errs = append(errs, fmt.Errorf("AnnotatePII requires Environment %q, got %q", developmentEnvironment, options.Environment))
+	}
+	for i, hook := range options.Hooks {
+		if hook == nil {
+			errs = append(errs, fmt.Errorf("hook %d is nil", i))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid log options: %w", errors.Join(errs...))
+}
+
+// isNilValue reports whether v is an interface holding a nil pointer, map,
+// slice, func or channel.
+func isNilValue(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	switch val := reflect.ValueOf(v); val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return val.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/redactor"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestNewLoggerChecked(t *testing.T) {
+	var nilSink *captureSink
+	var nilOutput *bytes.Buffer
+	var nilRedactor *redactor.Redactor
+
+	tests := []struct {
+		name    string
+		options types.LogOptions
+		wantErr string
+	}{
+		{"defaults", types.LogOptions{}, ""},
+		{"valid", types.LogOptions{Level: types.DebugLevel, Backend: types.JSONBackend, RateLimit: types.RateLimit{MaxPerInterval: 1, Interval: time.Second}}, ""},
+		{"level", types.LogOptions{Level: "verbose"}, `invalid level "verbose"`},
+		{"backend", types.LogOptions{Backend: "xml"}, `unknown backend "xml"`},
+		{"format", types.LogOptions{Format: "yaml"}, `unknown format "yaml"`},
+		{"signed logfmt", types.LogOptions{Format: types.LogfmtFormat, SigningKey: []byte("k")}, `SigningKey requires the json format, got "logfmt"`},
+		{"signed json backend", types.LogOptions{Backend: types.JSONBackend, SigningKey: []byte("k")}, "SigningKey is not supported by the json backend"},
+		{"negative max string length", types.LogOptions{MaxStringLength: -1}, "MaxStringLength must not be negative"},
+		{"negative max stack frames", types.LogOptions{MaxStackFrames: -1}, "MaxStackFrames must not be negative"},
+		{"negative rate limit", types.LogOptions{RateLimit: types.RateLimit{MaxPerInterval: -1}}, "rate limit must not be negative"},
+		{"partial rate limit", types.LogOptions{RateLimit: types.RateLimit{MaxPerInterval: 10}}, "rate limit needs both MaxPerInterval and Interval"},
+		{"nil sink", types.LogOptions{Sink: nilSink}, "Sink is a nil *logger.captureSink"},
+		{"nil redactor", types.LogOptions{Redactor: nilRedactor}, "Redactor is a nil *redactor.Redactor"},
+		{"annotating redactor", types.LogOptions{Redactor: redactor.NewRedactor(redactor.RedactorOptions{AnnotatePII: true}), Environment: "production"}, `AnnotatePII requires Environment "development", got "production"`},
+		{"annotating redactor without environment", types.LogOptions{Redactor: redactor.NewRedactor(redactor.RedactorOptions{AnnotatePII: true})}, `AnnotatePII requires Environment "development", got ""`},
+		{"annotating redactor in development", types.LogOptions{Redactor: redactor.NewRedactor(redactor.RedactorOptions{AnnotatePII: true}), Environment: "development"}, ""},
+		{"nil output", types.LogOptions{Output: nilOutput}, "Output is a nil *bytes.Buffer"},
+		{"nil formatter", types.LogOptions{Formatter: types.FormatterFunc(nil)}, "Formatter is a nil types.FormatterFunc"},
+		{"nil hook", types.LogOptions{Hooks: []func(*types.LogEntry) bool{nil}}, "hook 0 is nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := NewLoggerChecked(tt.options)
+			if tt.wantErr == "" {
+				if err != nil || log == nil {
+					t.Errorf("Expected a logger, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if log != nil {
+				t.Error("Expected no logger on error")
+			}
+		})
+	}
+}
+
+func TestNewLoggerCheckedJoinsErrors(t *testing.T) {
+	_, err := NewLoggerChecked(types.LogOptions{Level: "verbose", Backend: "xml"})
+
+	if err == nil || !strings.Contains(err.Error(), "invalid level") || !strings.Contains(err.Error(), "unknown backend") {
+		t.Errorf("Expected both problems to be reported, got %v", err)
+	}
+}
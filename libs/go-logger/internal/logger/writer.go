@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"unicode"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// maxWriterLine caps the partial line a Writer buffers; longer lines are
+// logged in pieces of this size.
+const maxWriterLine = 64 << 10
+
+// Writer is an io.Writer that logs every line written to it, so libraries
+// logging to an io.Writer or a *log.Logger end up in the structured log:
+//
+//	srv := &http.Server{ErrorLog: log.New(NewWriter(l, types.ErrorLevel), "", 0)}
+//
+// Lines are split on newlines and trimmed of trailing whitespace; blank
+// lines are dropped. A line written in several calls is buffered until its
+// newline arrives or Close is called.
+type Writer struct {
+	log   types.Logger
+	level types.LogLevel
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewWriter returns a Writer logging to log at level.
+func NewWriter(log types.Logger, level types.LogLevel) *Writer {
+	return &Writer{log: log, level: level}
+}
+
+// Write logs the complete lines in p and buffers the rest. It never fails.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			for len(w.buf) >= maxWriterLine {
+				w.emit(w.buf[:maxWriterLine])
+				w.buf = append(w.buf[:0], w.buf[maxWriterLine:]...)
+			}
+			break
+		}
+		if len(w.buf) > 0 {
+			w.emit(append(w.buf, p[:i]...))
+			w.buf = w.buf[:0]
+		} else {
+			w.emit(p[:i])
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close logs the buffered partial line, if any.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *Writer) emit(line []byte) {
+	line = bytes.TrimRightFunc(line, unicode.IsSpace)
+	if len(line) == 0 {
+		return
+	}
+	logAt(w.log, w.level, string(line))
+}
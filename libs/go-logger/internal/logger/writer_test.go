@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func messages(entries []types.LogEntry) []string {
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.Message
+	}
+	return out
+}
+
+func TestWriterSplitsLines(t *testing.T) {
+	sink := &captureSink{}
+	w := NewWriter(NewLogger(types.LogOptions{Sink: sink}), types.WarnLevel)
+
+	_, _ = w.Write([]byte("first line  \r\n\n  second"))
+	_, _ = w.Write([]byte(" line\nthird"))
+	if got := messages(sink.entries); strings.Join(got, "|") != "first line|  second line" {
+		t.Errorf("Expected the complete lines, got %q", got)
+	}
+
+	_ = w.Close()
+	if got := messages(sink.entries); len(got) != 3 || got[2] != "third" {
+		t.Errorf("Expected Close to log the partial line, got %q", got)
+	}
+	for _, entry := range sink.entries {
+		if entry.Level != types.WarnLevel {
+			t.Errorf("Expected warn entries, got %s", entry.Level)
+		}
+	}
+}
+
+func TestWriterWithStandardLogger(t *testing.T) {
+	sink := &captureSink{}
+	std := log.New(NewWriter(NewLogger(types.LogOptions{Sink: sink}), types.ErrorLevel), "http: ", 0)
+
+	std.Printf("TLS handshake error from %s: EOF", "10.0.0.1:5000")
+
+	if len(sink.entries) != 1 || sink.entries[0].Message != "http: TLS handshake error from 10.0.0.1:5000: EOF" || sink.entries[0].Level != types.ErrorLevel {
+		t.Errorf("Expected one error entry per Printf, got %+v", sink.entries)
+	}
+}
+
+func TestWriterCapsPartialLines(t *testing.T) {
+	sink := &captureSink{}
+	w := NewWriter(NewLogger(types.LogOptions{Sink: sink}), types.InfoLevel)
+
+	n, err := w.Write([]byte(strings.Repeat("a", maxWriterLine+10)))
+	if n != maxWriterLine+10 || err != nil {
+		t.Errorf("Expected the whole write to be accepted, got %d, %v", n, err)
+	}
+	if len(sink.entries) != 1 || len(sink.entries[0].Message) != maxWriterLine {
+		t.Fatalf("Expected the first %d bytes to be logged, got %d entries", maxWriterLine, len(sink.entries))
+	}
+	_ = w.Close()
+	if len(sink.entries) != 2 || sink.entries[1].Message != strings.Repeat("a", 10) {
+		t.Errorf("Expected the rest on Close, got %d entries", len(sink.entries))
+	}
+}
@@ -0,0 +1,61 @@
+package reqlog
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces the values of sensitive query parameters and
+// headers, matching the default mask of the redactor.
+const redactedValue = "***"
+
+// sensitiveNames are the query parameters and headers masked by
+// RedactQuery and RedactHeaders whatever the logger's redactor is
+// configured with. Names are compared case-insensitively.
+var sensitiveNames = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"cookie":              {},
+	"set-cookie":          {},
+	"x-api-key":           {},
+	"api_key":             {},
+	"apikey":              {},
+	"token":               {},
+	"access_token":        {},
+	"refresh_token":       {},
+	"password":            {},
+}
+
+// RedactQuery converts query into a field value, masking sensitive
+// parameters. See nethttp.RedactQuery.
+func RedactQuery(query url.Values) map[string]interface{} {
+	return redactValues(query, func(name string) string { return name })
+}
+
+// RedactHeaders converts header into a field value with lower-cased keys,
+// masking sensitive headers. See nethttp.RedactHeaders.
+func RedactHeaders(header http.Header) map[string]interface{} {
+	return redactValues(header, strings.ToLower)
+}
+
+func redactValues(values map[string][]string, key func(string) string) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for name, vs := range values {
+		k := key(name)
+		switch {
+		case isSensitiveName(name):
+			out[k] = redactedValue
+		case len(vs) == 1:
+			out[k] = vs[0]
+		default:
+			out[k] = append([]string(nil), vs...)
+		}
+	}
+	return out
+}
+
+func isSensitiveName(name string) bool {
+	_, ok := sensitiveNames[strings.ToLower(name)]
+	return ok
+}
@@ -0,0 +1,158 @@
+// Package reqlog holds the request logging shared by the middleware
+// adapters: resolving and propagating the correlation ID, binding a logger
+// to it and logging the finished request with the standard fields.
+package reqlog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/stack"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// CorrelationIDHeader carries the correlation ID of a request and is
+	// echoed on the response.
+	CorrelationIDHeader = "X-Correlation-ID"
+	// RequestIDHeader is used as the correlation ID when CorrelationIDHeader
+	// is absent.
+	RequestIDHeader = "X-Request-ID"
+)
+
+// Bind returns ctx carrying the correlation ID, generated when id is
+// empty or rejected by logctx.SanitizeCorrelationID, and a logger bound
+// to it, which is also returned.
+func Bind(ctx context.Context, id string, log types.Logger) (context.Context, string, types.Logger) {
+	if id = logctx.SanitizeCorrelationID(id); id == "" {
+		id = logctx.GenerateCorrelationID()
+	}
+	ctx = logctx.WithCorrelationID(ctx, id)
+	reqLog := log.WithContext(ctx)
+	return logger.NewContext(ctx, reqLog), id, reqLog
+}
+
+// CorrelationHeaders names the HTTP headers carrying the correlation ID.
+type CorrelationHeaders struct {
+	// Request lists the request headers the ID is read from, in order: the
+	// first holding a valid ID wins. Defaults to CorrelationIDHeader, then
+	// RequestIDHeader.
+	Request []string
+	// Response lists the response headers the ID is echoed in, all of
+	// them. Defaults to CorrelationIDHeader.
+	Response []string
+}
+
+var (
+	defaultRequestHeaders  = []string{CorrelationIDHeader, RequestIDHeader}
+	defaultResponseHeaders = []string{CorrelationIDHeader}
+)
+
+// BindHTTP is BindHTTPHeaders with the default headers.
+func BindHTTP(r *http.Request, respHeader http.Header, log types.Logger) (*http.Request, types.Logger) {
+	return BindHTTPHeaders(r, respHeader, log, CorrelationHeaders{})
+}
+
+// BindHTTPHeaders resolves the correlation ID of r from the request
+// headers of h, echoes it in its response headers and returns r with the
+// ID and a bound logger stored in its context.
+func BindHTTPHeaders(r *http.Request, respHeader http.Header, log types.Logger, h CorrelationHeaders) (*http.Request, types.Logger) {
+	request, response := h.Request, h.Response
+	if len(request) == 0 {
+		request = defaultRequestHeaders
+	}
+	if len(response) == 0 {
+		response = defaultResponseHeaders
+	}
+
+	var id string
+	for _, name := range request {
+		if id = logctx.SanitizeCorrelationID(r.Header.Get(name)); id != "" {
+			break
+		}
+	}
+	ctx, id, reqLog := Bind(r.Context(), id, log)
+	for _, name := range response {
+		respHeader.Set(name, id)
+	}
+	return r.WithContext(ctx), reqLog
+}
+
+// healthCheckPaths are the paths liveness and readiness probes usually
+// call.
+var healthCheckPaths = map[string]struct{}{
+	"/health":  {},
+	"/healthz": {},
+	"/livez":   {},
+	"/readyz":  {},
+	"/ping":    {},
+}
+
+// IsSuccessfulHealthCheck reports whether a request to path answered with
+// statusCode is a passing health check.
+func IsSuccessfulHealthCheck(path string, statusCode int) bool {
+	_, ok := healthCheckPaths[path]
+	return ok && statusCode < http.StatusBadRequest
+}
+
+// LogHTTPRequest logs a finished request at a level derived from its
+// status: error for 5xx, warn for 4xx and info otherwise. fields, which
+// may hold adapter specific values such as the route, get the method,
+// path, query, status code and duration. The duration is logged both as
+// durationMs, the numeric field to aggregate on, and as duration, a
+// readable string such as "1.5ms".
+func LogHTTPRequest(log types.Logger, r *http.Request, statusCode int, duration time.Duration, fields map[string]interface{}) {
+	fields["http.method"] = r.Method
+	fields["http.path"] = r.URL.Path
+	if r.URL.RawQuery != "" {
+		fields["http.query"] = RedactQuery(r.URL.Query())
+	}
+	fields["http.statusCode"] = statusCode
+	fields["durationMs"] = float64(duration) / float64(time.Millisecond)
+	fields["duration"] = duration.Round(time.Microsecond).String()
+	msg := fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, statusCode)
+
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		log.Error(msg, fields)
+	case statusCode >= http.StatusBadRequest:
+		log.Warn(msg, fields)
+	default:
+		log.Info(msg, fields)
+	}
+}
+
+// PanicError is a panic recovered while serving a request. Its StackTrace
+// method makes WithError log the stack as the LogError stack.
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+func (e *PanicError) Error() string { return fmt.Sprint(e.Value) }
+
+// StackTrace returns the stack of the goroutine that panicked.
+func (e *PanicError) StackTrace() string { return e.Stack }
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// LogPanic logs p, recovered while serving r, at error level with up to
+// stack.DefaultMaxFrames frames of the stack, starting at the function
+// that panicked. It must be called from the deferred function that
+// recovered p.
+func LogPanic(log types.Logger, r *http.Request, p interface{}) {
+	err := &PanicError{Value: p, Stack: stack.Panic(0)}
+	log.WithError(err).Error("panic recovered", map[string]interface{}{
+		"http.method": r.Method,
+		"http.path":   r.URL.Path,
+		"panic":       err.Error(),
+	})
+}
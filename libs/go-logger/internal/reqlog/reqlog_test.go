@@ -0,0 +1,114 @@
+package reqlog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func TestBindHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"correlation header", map[string]string{CorrelationIDHeader: "cid", RequestIDHeader: "rid"}, "cid"},
+		{"request header", map[string]string{RequestIDHeader: "rid"}, "rid"},
+		{"generated", nil, ""},
+		{"injected", map[string]string{CorrelationIDHeader: "cid\r\nlevel=error msg=forged"}, ""},
+		{"oversized", map[string]string{CorrelationIDHeader: strings.Repeat("a", 4096)}, ""},
+		{"invalid correlation header", map[string]string{CorrelationIDHeader: "c\nid", RequestIDHeader: "rid"}, "rid"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		resp := http.Header{}
+
+		req, _ = BindHTTP(req, resp, logger.NopLogger())
+
+		id := logctx.GetCorrelationID(req.Context())
+		if id == "" || (tt.want != "" && id != tt.want) {
+			t.Errorf("%s: expected correlation ID %q, got %q", tt.name, tt.want, id)
+		}
+		if logctx.SanitizeCorrelationID(id) != id {
+			t.Errorf("%s: expected a sanitized correlation ID, got %q", tt.name, id)
+		}
+		if resp.Get(CorrelationIDHeader) != id {
+			t.Errorf("%s: expected %q echoed, got %q", tt.name, id, resp.Get(CorrelationIDHeader))
+		}
+		if logger.FromContext(req.Context()) == nil {
+			t.Errorf("%s: expected a logger in the request context", tt.name)
+		}
+	}
+}
+
+func TestBindHTTPHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(CorrelationIDHeader, "cid")
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp := http.Header{}
+
+	req, _ = BindHTTPHeaders(req, resp, logger.NopLogger(), CorrelationHeaders{
+		Request:  []string{"traceparent", CorrelationIDHeader},
+		Response: []string{"traceparent", RequestIDHeader},
+	})
+
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if id := logctx.GetCorrelationID(req.Context()); id != want {
+		t.Errorf("Expected the ID from the first configured header, got %q", id)
+	}
+	if resp.Get("traceparent") != want || resp.Get(RequestIDHeader) != want || resp.Get(CorrelationIDHeader) != "" {
+		t.Errorf("Expected the ID echoed under the configured headers only, got %v", resp)
+	}
+}
+
+func TestBindGeneratesMissingID(t *testing.T) {
+	ctx, id, _ := Bind(context.Background(), "", logger.NopLogger())
+	if id == "" || logctx.GetCorrelationID(ctx) != id {
+		t.Errorf("Expected a generated ID stored in ctx, got %q", id)
+	}
+}
+
+func TestLogHTTPRequestLevels(t *testing.T) {
+	sink := &mockSink{}
+	log := logger.NewLogger(types.LogOptions{Sink: sink})
+	req := httptest.NewRequest(http.MethodGet, "/items?page=2", nil)
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound, http.StatusBadGateway} {
+		LogHTTPRequest(log, req, status, 1500*time.Microsecond, map[string]interface{}{})
+	}
+
+	levels := []types.LogLevel{types.InfoLevel, types.WarnLevel, types.ErrorLevel}
+	for i, entry := range sink.entries {
+		if entry.Level != levels[i] {
+			t.Errorf("Expected %s for status %v, got %s", levels[i], entry.Fields["http.statusCode"], entry.Level)
+		}
+	}
+	fields := sink.entries[0].Fields
+	if fields["durationMs"] != 1.5 || fields["duration"] != "1.5ms" {
+		t.Errorf("Expected durationMs 1.5 and duration 1.5ms, got %v and %v", fields["durationMs"], fields["duration"])
+	}
+	if query, _ := fields["http.query"].(map[string]interface{}); query["page"] != "2" {
+		t.Errorf("Expected the query, got %v", fields["http.query"])
+	}
+}
@@ -0,0 +1,71 @@
+// Package stack captures the stack of the calling goroutine as readable
+// frames, for the Logger implementations.
+package stack
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// DefaultMaxFrames caps the frames captured when no limit is given.
+const DefaultMaxFrames = 32
+
+// Capture returns up to maxFrames frames of the calling goroutine's stack
+// in the layout of runtime/debug.Stack, the function then its file and
+// line indented. The first frame is the caller of Capture, or its skip-th
+// caller to leave out wrappers. maxFrames <= 0 means DefaultMaxFrames.
+func Capture(skip, maxFrames int) string {
+	if maxFrames <= 0 {
+		maxFrames = DefaultMaxFrames
+	}
+	pcs := make([]uintptr, maxFrames)
+	// Skip runtime.Callers and Capture.
+	n := runtime.Callers(skip+2, pcs)
+	return write(runtime.CallersFrames(pcs[:n]), maxFrames)
+}
+
+// panicFrames is the room left in the capture buffer for the frames of
+// the recovering deferred function, which are skipped.
+const panicFrames = 8
+
+// Panic returns up to maxFrames frames of the stack of a panicking
+// goroutine, starting at the function that panicked, in the layout of
+// Capture. It must be called while the panic is being recovered, below
+// the deferred function; elsewhere it returns the stack of its caller.
+// maxFrames <= 0 means DefaultMaxFrames.
+func Panic(maxFrames int) string {
+	if maxFrames <= 0 {
+		maxFrames = DefaultMaxFrames
+	}
+	pcs := make([]uintptr, maxFrames+panicFrames)
+	// Skip runtime.Callers and Panic.
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			return write(frames, maxFrames)
+		}
+		if !more {
+			return write(runtime.CallersFrames(pcs), maxFrames)
+		}
+	}
+}
+
+// write renders up to maxFrames of the remaining frames.
+func write(frames *runtime.Frames, maxFrames int) string {
+	var b strings.Builder
+	// Inlined calls expand into more frames than program counters.
+	for i := 0; i < maxFrames; i++ {
+		frame, more := frames.Next()
+		if frame.PC == 0 {
+			break
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
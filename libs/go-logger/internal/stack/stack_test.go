@@ -0,0 +1,57 @@
+package stack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	s := Capture(0, 0)
+
+	lines := strings.Split(s, "\n")
+	if !strings.HasSuffix(lines[0], "stack.TestCapture") {
+		t.Errorf("Expected the caller first, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "\t") || !strings.Contains(lines[1], "stack_test.go:") {
+		t.Errorf("Expected the indented file and line, got %q", lines[1])
+	}
+}
+
+func TestCaptureSkip(t *testing.T) {
+	s := func() string { return Capture(1, 0) }()
+
+	if !strings.HasPrefix(s, "github.com/mateusmacedo/boyscout/go-logger/internal/stack.TestCaptureSkip\n") {
+		t.Errorf("Expected the wrapper to be skipped, got %q", s)
+	}
+}
+
+func TestCaptureMaxFrames(t *testing.T) {
+	s := Capture(0, 2)
+
+	if frames := strings.Count(s, "\n\t"); frames != 2 {
+		t.Errorf("Expected 2 frames, got %d in %q", frames, s)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	var s string
+	func() {
+		defer func() {
+			recover()
+			s = Panic(2)
+		}()
+		panicking()
+	}()
+
+	if !strings.HasPrefix(s, "github.com/mateusmacedo/boyscout/go-logger/internal/stack.panicking\n") {
+		t.Errorf("Expected the panicking function first, got %q", s)
+	}
+	if frames := strings.Count(s, "\n\t"); frames != 2 {
+		t.Errorf("Expected 2 frames, got %d in %q", frames, s)
+	}
+}
+
+//go:noinline
+func panicking() {
+	panic("boom")
+}
@@ -0,0 +1,46 @@
+// Package chi provides request logging middleware for the chi router. It
+// lives apart from package nethttp so that only applications importing it
+// depend on chi.
+package chi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/nethttp"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// CorrelationIDHeader carries the correlation ID of a request and is
+	// echoed on the response.
+	CorrelationIDHeader = nethttp.CorrelationIDHeader
+	// RequestIDHeader is used as the correlation ID when CorrelationIDHeader
+	// is absent.
+	RequestIDHeader = nethttp.RequestIDHeader
+)
+
+// RequestLoggingMiddleware is nethttp.LoggingMiddleware logging the chi
+// route pattern that matched, e.g. /users/{id}, as http.route next to the
+// concrete http.path. Outside a chi router, or when no route matched, the
+// field is left out, so the middleware also works on a bare mux.
+func RequestLoggingMiddleware(log types.Logger) func(http.Handler) http.Handler {
+	return nethttp.LoggingMiddlewareWithRoute(log, RoutePattern)
+}
+
+// RequestLoggingMiddlewareWithHeaders is RequestLoggingMiddleware reading
+// and echoing the correlation ID with the given headers.
+func RequestLoggingMiddlewareWithHeaders(log types.Logger, headers nethttp.CorrelationHeaders) func(http.Handler) http.Handler {
+	return nethttp.LoggingMiddlewareWithOptions(log, nethttp.Options{Route: RoutePattern, Headers: headers})
+}
+
+// RoutePattern returns the chi route pattern that matched r, or "" when r
+// was not routed by chi.
+func RoutePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
@@ -0,0 +1,79 @@
+package chi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func (s *mockSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
+
+func newTestLogger() (types.Logger, *mockSink) {
+	sink := &mockSink{}
+	return logger.NewLogger(types.LogOptions{Sink: sink}), sink
+}
+
+func TestRequestLoggingMiddleware(t *testing.T) {
+	log, sink := newTestLogger()
+	r := chi.NewRouter()
+	r.Use(RequestLoggingMiddleware(log))
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	entry := sink.Entries()[0]
+	if entry.Fields["http.route"] != "/users/{id}" || entry.Fields["http.path"] != "/users/42" {
+		t.Errorf("Expected route /users/{id} for /users/42, got %v", entry.Fields)
+	}
+	if entry.Level != types.WarnLevel || entry.Fields["http.statusCode"] != http.StatusNotFound {
+		t.Errorf("Expected a warn entry with status 404, got %s %v", entry.Level, entry.Fields)
+	}
+	if rec.Header().Get(CorrelationIDHeader) == "" {
+		t.Error("Expected a correlation ID on the response")
+	}
+}
+
+func TestRequestLoggingMiddlewareWithoutChi(t *testing.T) {
+	log, sink := newTestLogger()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	RequestLoggingMiddleware(log)(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	entry := sink.Entries()[0]
+	if _, ok := entry.Fields["http.route"]; ok {
+		t.Errorf("Expected no route outside chi, got %v", entry.Fields["http.route"])
+	}
+	if entry.Fields["http.path"] != "/health" || entry.Fields["http.statusCode"] != http.StatusOK {
+		t.Errorf("Expected a 200 entry for /health, got %v", entry.Fields)
+	}
+}
@@ -0,0 +1,405 @@
+// Package decorators wraps functions so every call is logged as a
+// types.LogEntry with its scope, outcome, duration and, optionally, its
+// arguments and result.
+package decorators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/redactor"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/sinks"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+// stdoutSink is shared by every decorated function so their lines do not
+// interleave.
+var stdoutSink = sinks.NewWriterSink(os.Stdout, nil)
+
+// LogMethodOptions configures the decorators.
+type LogMethodOptions struct {
+	// Level is used for successful calls; failures are logged at error.
+	// Defaults to info.
+	Level types.LogLevel
+	// IncludeArgs and IncludeResult attach the redacted arguments and
+	// result to the entry.
+	IncludeArgs   bool
+	IncludeResult bool
+	// SampleRate is the fraction of calls logged, between 0 and 1. Zero
+	// logs every call.
+	SampleRate float64
+	// Redactor masks arguments, results and error messages. Defaults to
+	// redactor.DefaultRedactor().
+	Redactor types.Redactor
+	// Sink receives the entries. Defaults to JSON lines on stdout.
+	Sink types.Sink
+	// RecoverPanics swallows panics raised by the decorated function
+	// instead of re-panicking. Panics are logged as failures either way.
+	RecoverPanics bool
+	// MaxStackFrames caps the frames recorded in the stack of a returned
+	// error or of a panic. Defaults to 32.
+	MaxStackFrames int
+	// Now returns the current time, used for timestamps and durations.
+	// Defaults to time.Now, whose monotonic clock reading keeps durations
+	// immune to wall clock changes. Tests can inject a fake clock.
+	Now func() time.Time
+	// OnSinkError is called with the entry and the error whenever Sink
+	// fails to write it. Defaults to a one-line diagnostic on stderr.
+	OnSinkError func(entry types.LogEntry, err error)
+	// Sequence, when set, numbers the entries in LogEntry.Sequence. Share
+	// it with the logger writing to the same sink for a single sequence.
+	Sequence *types.Sequence
+}
+
+// LogMethod returns a function of the same type as fn that logs every
+// call. When fn's last result is an error, calls returning a non-nil error
+// are logged at error level with outcome failure and the error attached.
+// When fn's first parameter is a context.Context, every entry carries the
+// correlation ID and trace context of the ctx passed to that call, as
+// trace_id and span_id fields.
+// The result must be asserted back to fn's type:
+//
+//	create := decorators.LogMethod(svc.CreateUser, opts).(func(string) (*User, error))
+//
+// LogMethod panics if fn is not a function.
+func LogMethod(fn interface{}, opts LogMethodOptions) interface{} {
+	return decorate(context.Background(), fn, opts)
+}
+
+// LogMethodError is LogMethod restricted to functions whose last result is
+// an error. It panics for any other fn, catching signature mistakes when
+// the function is decorated rather than silently never logging failures.
+func LogMethodError(fn interface{}, opts LogMethodOptions) interface{} {
+	if fnType := reflect.TypeOf(fn); fnType == nil || fnType.Kind() != reflect.Func || !returnsError(fnType) {
+		panic("decorators: LogMethodError requires a function whose last result is an error")
+	}
+	return decorate(context.Background(), fn, opts)
+}
+
+// LogMethodWithContext is like LogMethod and tags every entry with the
+// correlation ID stored in ctx when the function is decorated. For
+// functions taking a context.Context first, the correlation ID of the
+// per-call ctx takes precedence when it has one.
+func LogMethodWithContext(ctx context.Context, fn interface{}, opts LogMethodOptions) interface{} {
+	return decorate(ctx, fn, opts)
+}
+
+// takesContext reports whether the first parameter of fnType is a
+// context.Context.
+func takesContext(fnType reflect.Type) bool {
+	return fnType.NumIn() > 0 && fnType.In(0).Implements(contextType)
+}
+
+// returnsError reports whether the last result of fnType is an error.
+func returnsError(fnType reflect.Type) bool {
+	return fnType.NumOut() > 0 && fnType.Out(fnType.NumOut()-1) == errorType
+}
+
+// decorator holds what a decorated function needs on every call.
+type decorator struct {
+	fn            reflect.Value
+	opts          LogMethodOptions
+	className     string
+	methodName    string
+	correlationID string
+	checkErr      bool
+	// ctxArg is set when the first parameter of fn is a context.Context.
+	ctxArg bool
+}
+
+func decorate(ctx context.Context, fn interface{}, opts LogMethodOptions) interface{} {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func {
+		panic("decorators: expected a function, got " + fnValue.Kind().String())
+	}
+	d := &decorator{
+		fn:            fnValue,
+		opts:          withDefaults(opts),
+		correlationID: logctx.GetCorrelationID(ctx),
+		checkErr:      returnsError(fnValue.Type()),
+		ctxArg:        takesContext(fnValue.Type()),
+	}
+	d.className, d.methodName = extractClassAndMethod(fnValue)
+	return reflect.MakeFunc(fnValue.Type(), d.invoke).Interface()
+}
+
+func (d *decorator) invoke(args []reflect.Value) (results []reflect.Value) {
+	sampled := shouldSample(d.opts.SampleRate)
+	start := d.opts.Now()
+	// Panics are logged whether or not the call was sampled.
+	defer func() {
+		if p := recover(); p != nil {
+			results = d.handlePanic(p, args, start)
+		}
+	}()
+
+	results = call(d.fn, args)
+	if sampled {
+		d.logResults(args, results, start)
+	}
+	return results
+}
+
+// newEntry starts the entry of a call that began at start. Its timestamp
+// is the end of the call.
+func (d *decorator) newEntry(args []reflect.Value, start time.Time) types.LogEntry {
+	end := d.opts.Now()
+	entry := types.LogEntry{
+		Timestamp:     end,
+		Level:         d.opts.Level,
+		Scope:         types.LogScope{ClassName: d.className, MethodName: d.methodName},
+		Outcome:       outcomeSuccess,
+		CorrelationID: d.correlationID,
+		DurationMs:    float64(end.Sub(start)) / float64(time.Millisecond),
+	}
+	if d.ctxArg {
+		d.addCallContext(&entry, args[0])
+	}
+	if d.opts.IncludeArgs {
+		entry.Args = redactValues(d.opts.Redactor, args)
+	}
+	if d.opts.Sequence != nil {
+		entry.Sequence = d.opts.Sequence.Next()
+	}
+	return entry
+}
+
+// addCallContext tags entry with the correlation ID and trace context of
+// the ctx a call received.
+func (d *decorator) addCallContext(entry *types.LogEntry, arg reflect.Value) {
+	ctx, _ := arg.Interface().(context.Context)
+	if ctx == nil {
+		return
+	}
+	if id := logctx.GetCorrelationID(ctx); id != "" {
+		entry.CorrelationID = id
+	}
+	if traceID, spanID := logctx.GetTraceContext(ctx); traceID != "" {
+		entry.Fields = map[string]interface{}{"trace_id": traceID, "span_id": spanID}
+	}
+}
+
+func (d *decorator) logResults(args, results []reflect.Value, start time.Time) {
+	entry := d.newEntry(args, start)
+	values := results
+	if d.checkErr {
+		values = results[:len(results)-1]
+		if err, _ := results[len(results)-1].Interface().(error); err != nil {
+			entry.Level = types.ErrorLevel
+			entry.Outcome = outcomeFailure
+			entry.Error = d.logError(err)
+		}
+	}
+	if d.opts.IncludeResult && entry.Outcome == outcomeSuccess {
+		entry.Result = d.result(values)
+	}
+	logMethodExecution(d.opts, entry)
+}
+
+// logError describes a returned error and the errors it wraps, redacted.
+// Causes only get a stack when they carry their own.
+func (d *decorator) logError(err error) *types.LogError {
+	out := &types.LogError{
+		Name:    redactString(d.opts.Redactor, reflect.TypeOf(err).String()),
+		Message: redactString(d.opts.Redactor, err.Error()),
+		Stack:   redactString(d.opts.Redactor, errorStack(err, d.opts.MaxStackFrames)),
+	}
+	last := out
+	for i := 0; i < types.MaxErrorCauses; i++ {
+		if err = errors.Unwrap(err); err == nil {
+			break
+		}
+		cause := &types.LogError{
+			Name:    redactString(d.opts.Redactor, reflect.TypeOf(err).String()),
+			Message: redactString(d.opts.Redactor, err.Error()),
+		}
+		if _, ok := stackTraceOf(err); ok {
+			if stack := redactString(d.opts.Redactor, errorStack(err, d.opts.MaxStackFrames)); stack != last.Stack {
+				cause.Stack = stack
+			}
+		}
+		last.Cause, last = cause, cause
+	}
+	return out
+}
+
+// result returns the redacted non-error results of a call: nil for none,
+// the value itself for one and a slice in declaration order for several.
+func (d *decorator) result(values []reflect.Value) interface{} {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return d.opts.Redactor.Redact(values[0].Interface())
+	default:
+		return redactValues(d.opts.Redactor, values)
+	}
+}
+
+// handlePanic logs p as a failure with the stack of the panicking call,
+// then panics again unless RecoverPanics is set. A recovered call returns
+// zero values, with a *PanicError as its error result if it has one.
+func (d *decorator) handlePanic(p interface{}, args []reflect.Value, start time.Time) []reflect.Value {
+	entry := d.newEntry(args, start)
+	entry.Level = types.ErrorLevel
+	entry.Outcome = outcomeFailure
+	entry.Error = &types.LogError{
+		Name:    "panic",
+		Message: redactString(d.opts.Redactor, fmt.Sprint(p)),
+		Stack:   redactString(d.opts.Redactor, panicStack(d.opts.MaxStackFrames)),
+	}
+	logMethodExecution(d.opts, entry)
+
+	if !d.opts.RecoverPanics {
+		panic(p)
+	}
+	fnType := d.fn.Type()
+	results := make([]reflect.Value, fnType.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(fnType.Out(i))
+	}
+	if n := len(results); n > 0 && fnType.Out(n-1) == errorType {
+		errValue := reflect.New(errorType).Elem()
+		errValue.Set(reflect.ValueOf(&PanicError{Value: p}))
+		results[n-1] = errValue
+	}
+	return results
+}
+
+// PanicError is returned as the error result of a decorated function that
+// panicked while RecoverPanics was set.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// call invokes fn with the arguments received by a reflect.MakeFunc
+// implementation, where a variadic parameter arrives as a single slice.
+func call(fn reflect.Value, args []reflect.Value) []reflect.Value {
+	if fn.Type().IsVariadic() {
+		return fn.CallSlice(args)
+	}
+	return fn.Call(args)
+}
+
+func withDefaults(opts LogMethodOptions) LogMethodOptions {
+	if opts.Level == "" {
+		opts.Level = types.InfoLevel
+	}
+	if opts.Redactor == nil {
+		opts.Redactor = redactor.DefaultRedactor()
+	}
+	if opts.Sink == nil {
+		opts.Sink = stdoutSink
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.MaxStackFrames <= 0 {
+		opts.MaxStackFrames = defaultMaxStackFrames
+	}
+	if opts.OnSinkError == nil {
+		opts.OnSinkError = reportSinkError
+	}
+	return opts
+}
+
+// logMethodExecution writes entry to the sink, reporting failures to
+// OnSinkError.
+func logMethodExecution(opts LogMethodOptions, entry types.LogEntry) {
+	if err := opts.Sink.Write(entry); err != nil {
+		opts.OnSinkError(entry, err)
+	}
+}
+
+// reportSinkError is the default OnSinkError.
+func reportSinkError(_ types.LogEntry, err error) {
+	fmt.Fprintf(os.Stderr, "Failed to write to sink, %v\n", err)
+}
+
+func redactValues(r types.Redactor, values []reflect.Value) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = r.Redact(v.Interface())
+	}
+	return out
+}
+
+func redactString(r types.Redactor, s string) string {
+	if redacted, ok := r.Redact(s).(string); ok {
+		return redacted
+	}
+	return s
+}
+
+// extractClassAndMethod derives the scope of fn from its runtime name,
+// e.g. "github.com/acme/users.(*Service).Create" gives "Service" and
+// "Create", and "github.com/acme/users.Create" gives "" and "Create".
+func extractClassAndMethod(fn reflect.Value) (className, methodName string) {
+	return splitFuncName(runtimeName(fn))
+}
+
+// splitFuncName splits a runtime function name into its receiver type and
+// function. The "-fm" suffix of method values and the type parameters of
+// generic receivers are dropped, so svc.Create gives the same scope as
+// (*Service).Create. Closures keep the function enclosing them, as in
+// "Create.func1".
+func splitFuncName(name string) (className, methodName string) {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	name = strings.ReplaceAll(name, "[...]", "")
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 {
+		return "", name
+	}
+	parts = parts[1:]
+	if len(parts) > 1 && (strings.HasPrefix(parts[0], "(") || !isClosureName(parts[1])) {
+		return strings.Trim(parts[0], "(*)"), strings.Join(parts[1:], ".")
+	}
+	return "", strings.Join(parts, ".")
+}
+
+// isClosureName reports whether part names a closure, "func1" or, when
+// nested, a bare number.
+func isClosureName(part string) bool {
+	digits := strings.TrimPrefix(part, "func")
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// runtimeName returns the fully qualified name of the function behind fn.
+func runtimeName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
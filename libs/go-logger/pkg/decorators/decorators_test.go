@@ -0,0 +1,535 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func (s *mockSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
+
+type UserService struct{}
+
+func (s *UserService) CreateUser(email, password string) (string, error) {
+	if email == "" {
+		return "", errors.New("email is required")
+	}
+	return "user-1", nil
+}
+
+func add(a, b int) int { return a + b }
+
+func sum(base int, values ...int) int {
+	for _, v := range values {
+		base += v
+	}
+	return base
+}
+
+func TestLogMethod(t *testing.T) {
+	sink := &mockSink{}
+	decorated := LogMethod(add, LogMethodOptions{Sink: sink, IncludeArgs: true, IncludeResult: true}).(func(int, int) int)
+
+	if got := decorated(2, 3); got != 5 {
+		t.Fatalf("Expected 5, got %d", got)
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != types.InfoLevel || entry.Outcome != "success" {
+		t.Errorf("Expected info success entry, got %+v", entry)
+	}
+	if entry.Scope.MethodName != "add" || entry.Scope.ClassName != "" {
+		t.Errorf("Expected scope 'add', got %+v", entry.Scope)
+	}
+	if !reflect.DeepEqual(entry.Args, []interface{}{2, 3}) || entry.Result != 5 {
+		t.Errorf("Expected args [2 3] and result 5, got %v and %v", entry.Args, entry.Result)
+	}
+	if entry.Timestamp.IsZero() || entry.DurationMs < 0 {
+		t.Errorf("Expected timestamp and duration, got %+v", entry)
+	}
+}
+
+func TestLogMethodOmitsArgsAndResultByDefault(t *testing.T) {
+	sink := &mockSink{}
+	LogMethod(add, LogMethodOptions{Sink: sink}).(func(int, int) int)(1, 1)
+
+	entry := sink.Entries()[0]
+	if entry.Args != nil || entry.Result != nil {
+		t.Errorf("Expected no args or result, got %v and %v", entry.Args, entry.Result)
+	}
+}
+
+func TestLogMethodRedactsArgs(t *testing.T) {
+	sink := &mockSink{}
+	svc := &UserService{}
+	create := LogMethod(svc.CreateUser, LogMethodOptions{Sink: sink, IncludeArgs: true}).(func(string, string) (string, error))
+
+	create("joao@example.com", "hunter2")
+
+	args := sink.Entries()[0].Args
+	if args[0] != "***" {
+		t.Errorf("Expected email argument to be masked, got %v", args[0])
+	}
+}
+
+func TestLogMethodErrorCauses(t *testing.T) {
+	sink := &mockSink{}
+	fetch := LogMethodError(func(id string) error {
+		return fmt.Errorf("fetching %s: %w", id, newTracedError())
+	}, LogMethodOptions{Sink: sink}).(func(string) error)
+
+	_ = fetch("42")
+
+	logErr := sink.Entries()[0].Error
+	if logErr.Name != "*fmt.wrapError" || logErr.Cause == nil {
+		t.Fatalf("Expected the wrapping error with a cause, got %+v", logErr)
+	}
+	if cause := logErr.Cause; cause.Name != "*decorators.tracedError" || cause.Stack == "" || cause.Cause != nil {
+		t.Errorf("Expected the traced cause with its own stack, got %+v", cause)
+	}
+}
+
+func TestLogMethodError(t *testing.T) {
+	sink := &mockSink{}
+	svc := &UserService{}
+	create := LogMethodError(svc.CreateUser, LogMethodOptions{Sink: sink, IncludeResult: true}).(func(string, string) (string, error))
+
+	if _, err := create("", "x"); err == nil {
+		t.Fatal("Expected the wrapped error to be returned")
+	}
+	create("joao@example.com", "x")
+
+	entries := sink.Entries()
+	failure, success := entries[0], entries[1]
+	if failure.Level != types.ErrorLevel || failure.Outcome != "failure" {
+		t.Errorf("Expected error failure entry, got %+v", failure)
+	}
+	if failure.Error == nil || failure.Error.Message != "email is required" || failure.Error.Name != "*errors.errorString" {
+		t.Errorf("Expected error details, got %+v", failure.Error)
+	}
+	if failure.Result != nil {
+		t.Errorf("Expected no result on failure, got %v", failure.Result)
+	}
+	if failure.Error.Cause != nil {
+		t.Errorf("Expected no cause for an unwrapped error, got %+v", failure.Error.Cause)
+	}
+	if success.Outcome != "success" || success.Error != nil || success.Result != "user-1" {
+		t.Errorf("Expected success entry with result, got %+v", success)
+	}
+}
+
+func TestLogMethodErrorStack(t *testing.T) {
+	sink := &mockSink{}
+	svc := &UserService{}
+	create := LogMethodError(svc.CreateUser, LogMethodOptions{Sink: sink, MaxStackFrames: 1}).(func(string, string) (string, error))
+
+	create("", "x")
+
+	stack := sink.Entries()[0].Error.Stack
+	if !strings.Contains(stack, "TestLogMethodErrorStack") {
+		t.Errorf("Expected the stack to start at the call site, got %q", stack)
+	}
+	if n := strings.Count(stack, "\n\t"); n != 1 {
+		t.Errorf("Expected 1 frame, got %d in %q", n, stack)
+	}
+}
+
+// frame and tracedError mimic the stack traces of github.com/pkg/errors.
+type frame uintptr
+
+type tracedError struct{ pcs []frame }
+
+func (e *tracedError) Error() string { return "traced" }
+
+func (e *tracedError) StackTrace() []frame { return e.pcs }
+
+func newTracedError() error {
+	pcs := make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	err := &tracedError{}
+	for _, pc := range pcs {
+		err.pcs = append(err.pcs, frame(pc))
+	}
+	return err
+}
+
+func TestLogMethodErrorPrefersStackTrace(t *testing.T) {
+	sink := &mockSink{}
+	fail := LogMethodError(func() error { return newTracedError() }, LogMethodOptions{Sink: sink}).(func() error)
+
+	fail()
+
+	entry := sink.Entries()[0]
+	if entry.Error.Name != "*decorators.tracedError" {
+		t.Errorf("Expected the concrete error type, got %q", entry.Error.Name)
+	}
+	if !strings.HasPrefix(entry.Error.Stack, "github.com/mateusmacedo/boyscout/go-logger/pkg/decorators.newTracedError") {
+		t.Errorf("Expected the stack recorded by the error, got %q", entry.Error.Stack)
+	}
+}
+
+func TestLogMethodNow(t *testing.T) {
+	sink := &mockSink{}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	clock := func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls-1) * 250 * time.Millisecond)
+	}
+	decorated := LogMethod(add, LogMethodOptions{Sink: sink, Now: clock}).(func(int, int) int)
+
+	decorated(1, 2)
+
+	entry := sink.Entries()[0]
+	if entry.DurationMs != 250 {
+		t.Errorf("Expected a 250ms duration, got %v", entry.DurationMs)
+	}
+	if !entry.Timestamp.Equal(start.Add(250 * time.Millisecond)) {
+		t.Errorf("Expected the end of the call as timestamp, got %v", entry.Timestamp)
+	}
+}
+
+func TestLogMethodErrorRequiresErrorResult(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a function without an error result")
+		}
+	}()
+	LogMethodError(add, LogMethodOptions{})
+}
+
+func TestLogMethodWithContext(t *testing.T) {
+	sink := &mockSink{}
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-dec")
+	svc := &UserService{}
+	create := LogMethodWithContext(ctx, svc.CreateUser, LogMethodOptions{Sink: sink}).(func(string, string) (string, error))
+
+	create("", "x")
+
+	entry := sink.Entries()[0]
+	if entry.CorrelationID != "cid-dec" {
+		t.Errorf("Expected correlationId 'cid-dec', got %q", entry.CorrelationID)
+	}
+	if entry.Outcome != "failure" {
+		t.Errorf("Expected failure outcome for a returned error, got %q", entry.Outcome)
+	}
+}
+
+func TestLogMethodReadsPerCallContext(t *testing.T) {
+	sink := &mockSink{}
+	decorationCtx := logctx.WithCorrelationID(context.Background(), "cid-decoration")
+	handle := LogMethodWithContext(decorationCtx, func(ctx context.Context, id string) error { return nil }, LogMethodOptions{Sink: sink}).(func(context.Context, string) error)
+
+	ctx := logctx.WithTraceContext(logctx.WithCorrelationID(context.Background(), "cid-1"), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	handle(ctx, "a")
+	handle(logctx.WithCorrelationID(context.Background(), "cid-2"), "b")
+	handle(context.Background(), "c")
+	handle(nil, "d")
+
+	entries := sink.Entries()
+	if entries[0].CorrelationID != "cid-1" || entries[1].CorrelationID != "cid-2" {
+		t.Errorf("Expected the per-call correlation IDs, got %q and %q", entries[0].CorrelationID, entries[1].CorrelationID)
+	}
+	if entries[0].Fields["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || entries[0].Fields["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("Expected the trace context of the call, got %v", entries[0].Fields)
+	}
+	if entries[1].Fields != nil {
+		t.Errorf("Expected no trace fields without a trace context, got %v", entries[1].Fields)
+	}
+	if entries[2].CorrelationID != "cid-decoration" || entries[3].CorrelationID != "cid-decoration" {
+		t.Errorf("Expected the decoration ctx as fallback, got %q and %q", entries[2].CorrelationID, entries[3].CorrelationID)
+	}
+}
+
+func TestLogMethodVariadic(t *testing.T) {
+	sink := &mockSink{}
+	decorated := LogMethod(sum, LogMethodOptions{Sink: sink, IncludeResult: true}).(func(int, ...int) int)
+
+	if got := decorated(1, 2, 3); got != 6 {
+		t.Errorf("Expected 6, got %d", got)
+	}
+	if got := decorated(1); got != 1 {
+		t.Errorf("Expected 1, got %d", got)
+	}
+	spread := decorated(1, []int{4, 5}...)
+	if spread != 10 {
+		t.Errorf("Expected 10, got %d", spread)
+	}
+}
+
+func TestLogMethodVariadicArgs(t *testing.T) {
+	sink := &mockSink{}
+	decorated := LogMethod(sum, LogMethodOptions{Sink: sink, IncludeArgs: true}).(func(int, ...int) int)
+
+	decorated(1, 2, 3)
+
+	entry := sink.Entries()[0]
+	if len(entry.Args) != 2 || entry.Args[0] != 1 || fmt.Sprint(entry.Args[1]) != "[2 3]" {
+		t.Errorf("Expected the variadic values as one slice argument, got %v", entry.Args)
+	}
+	if entry.Scope.MethodName != "sum" {
+		t.Errorf("Expected method sum, got %q", entry.Scope.MethodName)
+	}
+}
+
+func TestLogMethodPanicsOnNonFunction(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a non-function")
+		}
+	}()
+	LogMethod(42, LogMethodOptions{})
+}
+
+func TestExtractClassAndMethod(t *testing.T) {
+	className, methodName := extractClassAndMethod(reflect.ValueOf((*UserService).CreateUser))
+	if className != "UserService" || methodName != "CreateUser" {
+		t.Errorf("Expected UserService.CreateUser, got %s.%s", className, methodName)
+	}
+
+	className, methodName = extractClassAndMethod(reflect.ValueOf(add))
+	if className != "" || methodName != "add" {
+		t.Errorf("Expected add, got %q.%q", className, methodName)
+	}
+}
+
+func TestExtractClassAndMethodBoundMethod(t *testing.T) {
+	svc := &UserService{}
+	className, methodName := extractClassAndMethod(reflect.ValueOf(svc.CreateUser))
+	if className != "UserService" || methodName != "CreateUser" {
+		t.Errorf("Expected UserService.CreateUser, got %s.%s", className, methodName)
+	}
+}
+
+func TestSplitFuncName(t *testing.T) {
+	tests := []struct {
+		name, className, methodName string
+	}{
+		{"github.com/acme/users.(*Service).Create-fm", "Service", "Create"},
+		{"github.com/acme/users.Service.Get-fm", "Service", "Get"},
+		{"github.com/acme/users.(*Box[...]).Get", "Box", "Get"},
+		{"github.com/acme/users.Create.func1", "", "Create.func1"},
+		{"github.com/acme/users.Create.func1.2", "", "Create.func1.2"},
+		{"github.com/acme/users.(*Service).Create.func1", "Service", "Create.func1"},
+		{"main.main", "", "main"},
+	}
+	for _, tt := range tests {
+		className, methodName := splitFuncName(tt.name)
+		if className != tt.className || methodName != tt.methodName {
+			t.Errorf("%s: expected %q.%q, got %q.%q", tt.name, tt.className, tt.methodName, className, methodName)
+		}
+	}
+}
+
+func explode(msg string) (int, error) {
+	panic(msg)
+}
+
+func TestLogMethodLogsPanics(t *testing.T) {
+	sink := &mockSink{}
+	// Sampling must not hide panics.
+	decorated := LogMethod(explode, LogMethodOptions{Sink: sink, SampleRate: 0.0001}).(func(string) (int, error))
+
+	func() {
+		defer func() {
+			if p := recover(); p != "token=abc leaked" {
+				t.Errorf("Expected the original panic to propagate, got %v", p)
+			}
+		}()
+		decorated("token=abc leaked")
+	}()
+
+	entries := sink.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Outcome != "failure" || entry.Level != types.ErrorLevel {
+		t.Errorf("Expected error failure entry, got %+v", entry)
+	}
+	if entry.Error == nil || entry.Error.Name != "panic" || entry.Error.Message != "token=abc leaked" {
+		t.Errorf("Expected panic details, got %+v", entry.Error)
+	}
+	if !strings.Contains(entry.Error.Stack, "explode") {
+		t.Errorf("Expected stack to include the panicking function, got %q", entry.Error.Stack)
+	}
+}
+
+func TestLogMethodRecoverPanics(t *testing.T) {
+	sink := &mockSink{}
+	decorated := LogMethodError(explode, LogMethodOptions{Sink: sink, RecoverPanics: true}).(func(string) (int, error))
+
+	n, err := decorated("boom")
+
+	var panicErr *PanicError
+	if n != 0 || !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Expected zero value and a PanicError, got %d and %v", n, err)
+	}
+	if entries := sink.Entries(); len(entries) != 1 || entries[0].Outcome != "failure" {
+		t.Errorf("Expected the recovered panic to be logged, got %+v", entries)
+	}
+}
+
+func TestLogMethodRecoverPanicsWithoutErrorResult(t *testing.T) {
+	decorated := LogMethod(func() int { panic("boom") }, LogMethodOptions{Sink: &mockSink{}, RecoverPanics: true}).(func() int)
+
+	if got := decorated(); got != 0 {
+		t.Errorf("Expected zero value, got %d", got)
+	}
+}
+
+func fetchPage(query string) ([]string, int, error) {
+	if query == "" {
+		return nil, 0, errors.New("empty query")
+	}
+	return []string{"a", "b"}, 42, nil
+}
+
+func TestLogMethodCapturesAllResults(t *testing.T) {
+	sink := &mockSink{}
+	fetch := LogMethod(fetchPage, LogMethodOptions{Sink: sink, IncludeResult: true}).(func(string) ([]string, int, error))
+
+	fetch("q")
+	fetch("")
+
+	entries := sink.Entries()
+	want := []interface{}{[]interface{}{"a", "b"}, 42}
+	if !reflect.DeepEqual(entries[0].Result, want) {
+		t.Errorf("Expected result %v, got %#v", want, entries[0].Result)
+	}
+	if entries[1].Outcome != "failure" || entries[1].Error == nil || entries[1].Error.Message != "empty query" {
+		t.Errorf("Expected LogMethod to detect the trailing error, got %+v", entries[1])
+	}
+}
+
+func TestLogMethodResultShapes(t *testing.T) {
+	sink := &mockSink{}
+	opts := LogMethodOptions{Sink: sink, IncludeResult: true}
+
+	LogMethod(func() {}, opts).(func())()
+	LogMethod(func() error { return nil }, opts).(func() error)()
+	LogMethod(func() (string, error) { return "one", nil }, opts).(func() (string, error))()
+
+	entries := sink.Entries()
+	if entries[0].Result != nil || entries[1].Result != nil {
+		t.Errorf("Expected no result without values, got %v and %v", entries[0].Result, entries[1].Result)
+	}
+	if entries[2].Result != "one" {
+		t.Errorf("Expected a single value to be kept as is, got %#v", entries[2].Result)
+	}
+}
+
+type failingSink struct{ err error }
+
+func (s failingSink) Write(types.LogEntry) error { return s.err }
+func (s failingSink) Close() error               { return nil }
+
+func TestLogMethodOnSinkError(t *testing.T) {
+	sinkErr := errors.New("connection refused")
+	var failed []types.LogEntry
+	fn := LogMethod(func() {}, LogMethodOptions{
+		Sink: failingSink{err: sinkErr},
+		OnSinkError: func(entry types.LogEntry, err error) {
+			if err != sinkErr {
+				t.Errorf("Expected the sink error, got %v", err)
+			}
+			failed = append(failed, entry)
+		},
+	}).(func())
+
+	fn()
+
+	if len(failed) != 1 || failed[0].Outcome != "success" {
+		t.Errorf("Expected the failed entry to be reported, got %+v", failed)
+	}
+}
+
+// upperRedactor masks every string, so tests can see which parts of an
+// entry went through the redactor.
+type upperRedactor struct{}
+
+func (upperRedactor) Redact(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToUpper(s)
+	}
+	return value
+}
+
+func TestLogMethodRedactsError(t *testing.T) {
+	sink := &mockSink{}
+	fail := LogMethodError(func() error { return newTracedError() }, LogMethodOptions{Sink: sink, Redactor: upperRedactor{}}).(func() error)
+
+	fail()
+
+	logErr := sink.Entries()[0].Error
+	if logErr.Name != "*DECORATORS.TRACEDERROR" || logErr.Message != "TRACED" {
+		t.Errorf("Expected redacted name and message, got %+v", logErr)
+	}
+	if logErr.Stack == "" || logErr.Stack != strings.ToUpper(logErr.Stack) {
+		t.Errorf("Expected redacted stack, got %q", logErr.Stack)
+	}
+}
+
+func TestLogMethodPanicStackIsRedactedAndCapped(t *testing.T) {
+	sink := &mockSink{}
+	decorated := LogMethodError(explode, LogMethodOptions{Sink: sink, Redactor: upperRedactor{}, MaxStackFrames: 2, RecoverPanics: true}).(func(string) (int, error))
+
+	decorated("boom")
+
+	stack := sink.Entries()[0].Error.Stack
+	if !strings.HasPrefix(stack, "GITHUB.COM/MATEUSMACEDO/BOYSCOUT/GO-LOGGER/PKG/DECORATORS.EXPLODE\n\t") {
+		t.Errorf("Expected a redacted stack starting at the panicking function, got %q", stack)
+	}
+	if frames := strings.Count(stack, "\n\t"); frames != 2 {
+		t.Errorf("Expected 2 frames, got %d in %q", frames, stack)
+	}
+}
+
+func TestLogMethodSequence(t *testing.T) {
+	sink := &mockSink{}
+	seq := &types.Sequence{}
+	opts := LogMethodOptions{Sink: sink, Sequence: seq}
+	first := LogMethod(add, opts).(func(int, int) int)
+	second := LogMethod(add, opts).(func(int, int) int)
+
+	first(1, 2)
+	second(3, 4)
+	first(5, 6)
+
+	for i, entry := range sink.Entries() {
+		if entry.Sequence != uint64(i+1) {
+			t.Errorf("Expected entry %d to have sequence %d, got %d", i, i+1, entry.Sequence)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package decorators
+
+// WrapFunc1 is a typed LogMethod for functions of one argument returning a
+// value and an error. It needs no type assertion at the call site:
+//
+//	create := decorators.WrapFunc1(svc.CreateUser, opts)
+//	user, err := create(req)
+func WrapFunc1[A, R any](fn func(A) (R, error), opts LogMethodOptions) func(A) (R, error) {
+	return LogMethod(fn, opts).(func(A) (R, error))
+}
+
+// WrapFunc2 is WrapFunc1 for functions of two arguments.
+func WrapFunc2[A, B, R any](fn func(A, B) (R, error), opts LogMethodOptions) func(A, B) (R, error) {
+	return LogMethod(fn, opts).(func(A, B) (R, error))
+}
+
+// WrapFunc3 is WrapFunc1 for functions of three arguments.
+func WrapFunc3[A, B, C, R any](fn func(A, B, C) (R, error), opts LogMethodOptions) func(A, B, C) (R, error) {
+	return LogMethod(fn, opts).(func(A, B, C) (R, error))
+}
@@ -0,0 +1,57 @@
+package decorators
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWrapFunc1(t *testing.T) {
+	sink := &mockSink{}
+	double := WrapFunc1(func(n int) (int, error) {
+		if n < 0 {
+			return 0, errors.New("negative")
+		}
+		return n * 2, nil
+	}, LogMethodOptions{Sink: sink, IncludeArgs: true, IncludeResult: true})
+
+	if got, err := double(21); got != 42 || err != nil {
+		t.Errorf("Expected 42, got %d and %v", got, err)
+	}
+	if _, err := double(-1); err == nil {
+		t.Error("Expected the wrapped error to be returned")
+	}
+
+	entries := sink.Entries()
+	if entries[0].Result != 42 || !reflect.DeepEqual(entries[0].Args, []interface{}{21}) {
+		t.Errorf("Expected args and result to be logged, got %+v", entries[0])
+	}
+	if entries[1].Outcome != "failure" {
+		t.Errorf("Expected failure outcome, got %q", entries[1].Outcome)
+	}
+}
+
+func TestWrapFunc2(t *testing.T) {
+	sink := &mockSink{}
+	svc := &UserService{}
+	create := WrapFunc2(svc.CreateUser, LogMethodOptions{Sink: sink})
+
+	if id, err := create("joao@example.com", "secret"); id != "user-1" || err != nil {
+		t.Errorf("Expected user-1, got %q and %v", id, err)
+	}
+	if len(sink.Entries()) != 1 {
+		t.Errorf("Expected 1 entry, got %d", len(sink.Entries()))
+	}
+}
+
+func TestWrapFunc3(t *testing.T) {
+	sink := &mockSink{}
+	join := WrapFunc3(func(a, b, sep string) (string, error) { return a + sep + b, nil }, LogMethodOptions{Sink: sink, IncludeResult: true})
+
+	if got, _ := join("a", "b", "-"); got != "a-b" {
+		t.Errorf("Expected a-b, got %q", got)
+	}
+	if sink.Entries()[0].Result != "a-b" {
+		t.Errorf("Expected result a-b, got %v", sink.Entries()[0].Result)
+	}
+}
@@ -0,0 +1,13 @@
+package decorators
+
+import "math/rand/v2"
+
+// shouldSample reports whether a call is logged under rate. The draw uses
+// the runtime's per-process seeded generator, so the decision does not
+// depend on timing and calls in a burst are sampled independently.
+func shouldSample(rate float64) bool {
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
@@ -0,0 +1,29 @@
+package decorators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShouldSampleBounds(t *testing.T) {
+	for _, rate := range []float64{0, 1, 1.5, -1} {
+		if !shouldSample(rate) {
+			t.Errorf("Expected rate %v to log every call", rate)
+		}
+	}
+}
+
+func TestSampleRateIsHonored(t *testing.T) {
+	const calls, rate, tolerance = 10000, 0.3, 0.03
+	sink := &mockSink{}
+	decorated := LogMethod(add, LogMethodOptions{Sink: sink, SampleRate: rate}).(func(int, int) int)
+
+	for i := 0; i < calls; i++ {
+		decorated(i, i)
+	}
+
+	observed := float64(len(sink.Entries())) / calls
+	if math.Abs(observed-rate) > tolerance {
+		t.Errorf("Expected a fraction of %.2f±%.2f, got %.4f", rate, tolerance, observed)
+	}
+}
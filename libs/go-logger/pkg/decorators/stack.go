@@ -0,0 +1,112 @@
+package decorators
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// defaultMaxStackFrames caps the frames recorded for a failed call when
+// LogMethodOptions.MaxStackFrames is zero.
+const defaultMaxStackFrames = 32
+
+// errorStack returns the stack recorded for err: the one returned by its
+// StackTrace method, as errors from github.com/pkg/errors have, or else the
+// stack of the caller of the decorated function. At most maxFrames frames
+// are kept whenever the program counters are available.
+func errorStack(err error, maxFrames int) string {
+	if trace, ok := stackTraceOf(err); ok {
+		if pcs, ok := programCounters(trace); ok {
+			return formatFrames(pcs, maxFrames)
+		}
+		return fmt.Sprintf("%+v", trace)
+	}
+	pcs := make([]uintptr, maxFrames+decoratorFrames)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	return formatFrames(pcs, maxFrames)
+}
+
+// decoratorFrames is the room left in the capture buffer for the frames of
+// this package, reflect and the panic machinery that are skipped.
+const decoratorFrames = 8
+
+// panicStack returns up to maxFrames frames of the stack of a panicking
+// call, starting at the function that panicked. It must be called while
+// the panic is being recovered, below the deferred function.
+func panicStack(maxFrames int) string {
+	pcs := make([]uintptr, maxFrames+decoratorFrames)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			return writeFrames(frames, maxFrames)
+		}
+		if !more {
+			return formatFrames(pcs, maxFrames)
+		}
+	}
+}
+
+// stackTraceOf calls err's StackTrace method, if any. The method is looked
+// up by name because its return type differs between error packages.
+func stackTraceOf(err error) (interface{}, bool) {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil, false
+	}
+	return method.Call(nil)[0].Interface(), true
+}
+
+// programCounters converts a stack trace made of program counters, such as
+// errors.StackTrace from github.com/pkg/errors, to a []uintptr.
+func programCounters(trace interface{}) ([]uintptr, bool) {
+	v := reflect.ValueOf(trace)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs, true
+}
+
+// isDecoratorFrame reports whether frame belongs to the decorator or to
+// reflect.MakeFunc rather than to the code calling the decorated function.
+func isDecoratorFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, "reflect.") || strings.Contains(frame.Function, "decorators.(*decorator)")
+}
+
+// formatFrames renders up to maxFrames frames of pcs in the layout of
+// runtime/debug.Stack, the function then its file and line indented,
+// starting at the first frame outside the decorator.
+func formatFrames(pcs []uintptr, maxFrames int) string {
+	return writeFrames(runtime.CallersFrames(pcs), maxFrames)
+}
+
+// writeFrames renders the remaining frames like formatFrames.
+func writeFrames(frames *runtime.Frames, maxFrames int) string {
+	var b strings.Builder
+	leading := true
+	for n := 0; n < maxFrames; {
+		frame, more := frames.Next()
+		if frame.PC == 0 {
+			break
+		}
+		if leading && isDecoratorFrame(frame) {
+			if !more {
+				break
+			}
+			continue
+		}
+		leading = false
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		n++
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
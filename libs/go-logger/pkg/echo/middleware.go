@@ -0,0 +1,141 @@
+// Package echo provides request logging middleware for the Echo web
+// framework. It lives apart from package nethttp so that only
+// applications importing it depend on Echo.
+package echo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/reqlog"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// CorrelationIDHeader carries the correlation ID of a request and is
+	// echoed on the response.
+	CorrelationIDHeader = reqlog.CorrelationIDHeader
+	// RequestIDHeader is used as the correlation ID when CorrelationIDHeader
+	// is absent.
+	RequestIDHeader = reqlog.RequestIDHeader
+)
+
+// CorrelationHeaders names the headers the correlation ID is read from,
+// in order, and echoed in. The defaults are CorrelationIDHeader then
+// RequestIDHeader, and CorrelationIDHeader.
+type CorrelationHeaders = reqlog.CorrelationHeaders
+
+// RequestLoggingOptions configures RequestLoggingMiddleware.
+type RequestLoggingOptions struct {
+	// LogHeaders adds the request headers as the http.headers field, with
+	// Authorization, Cookie and API key headers masked (see
+	// nethttp.RedactHeaders). Defaults to off.
+	LogHeaders bool
+	// SkipPaths lists request paths that are not logged, e.g. /health or
+	// /metrics. Their requests still get a correlation ID.
+	SkipPaths []string
+	// SkipSuccessfulHealthChecks skips requests to /health, /healthz,
+	// /livez, /readyz and /ping answered with a status below 400, so
+	// probes do not flood the logs while failing checks are still logged.
+	SkipSuccessfulHealthChecks bool
+	// Skipper, when set, is called before the handler; requests it returns
+	// true for are not logged.
+	Skipper func(echo.Context) bool
+	// Headers overrides the correlation ID headers, e.g. to read
+	// X-Request-ID first or echo the ID under several names.
+	Headers CorrelationHeaders
+}
+
+// LoggingMiddleware is RequestLoggingMiddleware with the default options.
+func LoggingMiddleware(log types.Logger) echo.MiddlewareFunc {
+	return RequestLoggingMiddleware(log, RequestLoggingOptions{})
+}
+
+// RequestLoggingMiddleware logs every request with its method, path,
+// route template, query, status code, duration and correlation ID, and
+// optionally its headers. Sensitive query parameters and headers are
+// masked. The route template (e.g. /orders/:id) is logged as http.route
+// so it can label metrics without the cardinality of the concrete path.
+// An error returned by the handler is passed to the Echo error handler,
+// so the status it answers with is the one logged, and is logged as the
+// entry's error. The correlation ID is read from the request headers or
+// generated, stored in the request context together with a logger bound
+// to it (see FromContext in the root package) and echoed on the response.
+func RequestLoggingMiddleware(log types.Logger, options RequestLoggingOptions) echo.MiddlewareFunc {
+	skip := make(map[string]struct{}, len(options.SkipPaths))
+	for _, path := range options.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			r, reqLog := reqlog.BindHTTPHeaders(c.Request(), c.Response().Header(), log, options.Headers)
+			c.SetRequest(r)
+			if _, ok := skip[r.URL.Path]; ok {
+				return next(c)
+			}
+			if options.Skipper != nil && options.Skipper(c) {
+				return next(c)
+			}
+
+			fields := map[string]interface{}{}
+			if options.LogHeaders {
+				fields["http.headers"] = reqlog.RedactHeaders(r.Header)
+			}
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if options.SkipSuccessfulHealthChecks && reqlog.IsSuccessfulHealthCheck(r.URL.Path, status) {
+				return err
+			}
+			if route := c.Path(); route != "" {
+				fields["http.route"] = route
+			}
+			if err != nil {
+				reqLog = reqLog.WithError(err)
+			}
+			reqlog.LogHTTPRequest(reqLog, c.Request(), status, time.Since(start), fields)
+			return err
+		}
+	}
+}
+
+// RecoveryMiddleware recovers panics raised by the handlers after it,
+// logs them at error level with the panic value as the error and its
+// stack as the error stack, and answers with a 500. It replaces Echo's
+// middleware.Recover. Register it after RequestLoggingMiddleware so the
+// panic is logged with the request's correlation ID and the request line
+// records the 500; used alone it binds the correlation ID itself.
+func RecoveryMiddleware(log types.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if logctx.GetCorrelationID(c.Request().Context()) == "" {
+				r, _ := reqlog.BindHTTP(c.Request(), c.Response().Header(), log)
+				c.SetRequest(r)
+			}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				reqlog.LogPanic(log.WithContext(c.Request().Context()), c.Request(), p)
+				if !c.Response().Committed {
+					err = echo.NewHTTPError(http.StatusInternalServerError)
+				}
+			}()
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package echo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func (s *mockSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
+
+func newTestLogger() (types.Logger, *mockSink) {
+	sink := &mockSink{}
+	return logger.NewLogger(types.LogOptions{Sink: sink}), sink
+}
+
+// serve runs req through a router using the middleware and returns the
+// last entry logged.
+func serve(t *testing.T, options RequestLoggingOptions, register func(e *echo.Echo), req *http.Request) (*httptest.ResponseRecorder, types.LogEntry) {
+	t.Helper()
+	log, sink := newTestLogger()
+	e := echo.New()
+	e.Use(RequestLoggingMiddleware(log, options))
+	register(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	entries := sink.Entries()
+	if len(entries) == 0 {
+		t.Fatal("Expected a log entry")
+	}
+	return rec, entries[len(entries)-1]
+}
+
+func TestRequestLoggingMiddleware(t *testing.T) {
+	var handlerID string
+	req := httptest.NewRequest(http.MethodGet, "/orders/42?token=abc", nil)
+	req.Header.Set(CorrelationIDHeader, "cid-echo")
+
+	rec, entry := serve(t, RequestLoggingOptions{}, func(e *echo.Echo) {
+		e.GET("/orders/:id", func(c echo.Context) error {
+			handlerID = logctx.GetCorrelationID(c.Request().Context())
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		})
+	}, req)
+
+	if handlerID != "cid-echo" || rec.Header().Get(CorrelationIDHeader) != "cid-echo" {
+		t.Errorf("Expected the correlation ID in the handler and response, got %q and %q", handlerID, rec.Header().Get(CorrelationIDHeader))
+	}
+	if entry.Level != types.WarnLevel || entry.CorrelationID != "cid-echo" {
+		t.Errorf("Expected a warn entry with the correlation ID, got %+v", entry)
+	}
+	if entry.Fields["http.statusCode"] != http.StatusNotFound || entry.Fields["http.route"] != "/orders/:id" || entry.Fields["http.path"] != "/orders/42" {
+		t.Errorf("Expected the status, route and path, got %v", entry.Fields)
+	}
+	if query, _ := entry.Fields["http.query"].(map[string]interface{}); query["token"] == "abc" {
+		t.Errorf("Expected the token to be masked, got %v", query)
+	}
+	if _, ok := entry.Fields["http.headers"]; ok {
+		t.Error("Expected no headers by default")
+	}
+}
+
+func TestRequestLoggingMiddlewareLogsReturnedErrors(t *testing.T) {
+	rec, entry := serve(t, RequestLoggingOptions{}, func(e *echo.Echo) {
+		e.GET("/orders", func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusConflict, "order locked")
+		})
+	}, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if rec.Code != http.StatusConflict || entry.Fields["http.statusCode"] != http.StatusConflict {
+		t.Errorf("Expected the status set by the error handler, got %d and %v", rec.Code, entry.Fields["http.statusCode"])
+	}
+	if entry.Error == nil {
+		t.Error("Expected the returned error on the entry")
+	}
+
+	rec, entry = serve(t, RequestLoggingOptions{}, func(e *echo.Echo) {
+		e.GET("/fail", func(c echo.Context) error { return errors.New("db down") })
+	}, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if rec.Code != http.StatusInternalServerError || entry.Level != types.ErrorLevel {
+		t.Errorf("Expected an error entry with a 500, got %d and %+v", rec.Code, entry)
+	}
+	if entry.Error == nil || entry.Error.Message != "db down" {
+		t.Errorf("Expected the returned error on the entry, got %+v", entry.Error)
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipPaths(t *testing.T) {
+	log, sink := newTestLogger()
+	e := echo.New()
+	e.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{SkipPaths: []string{"/health"}}))
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].Fields["http.path"] != "/missing" {
+		t.Errorf("Expected only /missing to be logged, got %v", entries)
+	}
+	if rec.Header().Get(CorrelationIDHeader) == "" {
+		t.Error("Expected skipped requests to still get a correlation ID")
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipsSuccessfulHealthChecks(t *testing.T) {
+	log, sink := newTestLogger()
+	healthy := true
+	e := echo.New()
+	e.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{SkipSuccessfulHealthChecks: true}))
+	e.GET("/healthz", func(c echo.Context) error {
+		if healthy {
+			return c.NoContent(http.StatusOK)
+		}
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	healthy = false
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].Fields["http.statusCode"] != http.StatusServiceUnavailable {
+		t.Errorf("Expected only the failing check to be logged, got %v", entries)
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipper(t *testing.T) {
+	log, sink := newTestLogger()
+	e := echo.New()
+	e.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{
+		Skipper: func(c echo.Context) bool { return c.Request().UserAgent() == "kube-probe/1.29" },
+	}))
+	e.GET("/orders", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	probe := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	probe.Header.Set("User-Agent", "kube-probe/1.29")
+	e.ServeHTTP(httptest.NewRecorder(), probe)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if got := len(sink.Entries()); got != 1 {
+		t.Errorf("Expected 1 logged request, got %d", got)
+	}
+}
+
+func TestRequestLoggingMiddlewareHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(RequestIDHeader, "rid-echo")
+	req.Header.Set("Authorization", "Bearer secret")
+
+	rec, entry := serve(t, RequestLoggingOptions{LogHeaders: true, Headers: CorrelationHeaders{
+		Request:  []string{RequestIDHeader},
+		Response: []string{RequestIDHeader, CorrelationIDHeader},
+	}}, func(e *echo.Echo) {
+		e.GET("/orders", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	}, req)
+
+	if entry.CorrelationID != "rid-echo" {
+		t.Errorf("Expected the ID from X-Request-ID, got %q", entry.CorrelationID)
+	}
+	if rec.Header().Get(RequestIDHeader) != "rid-echo" || rec.Header().Get(CorrelationIDHeader) != "rid-echo" {
+		t.Errorf("Expected the ID echoed under both headers, got %v", rec.Header())
+	}
+	if headers, _ := entry.Fields["http.headers"].(map[string]interface{}); headers["authorization"] != "***" {
+		t.Errorf("Expected the masked headers, got %v", entry.Fields["http.headers"])
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	log, sink := newTestLogger()
+	e := echo.New()
+	e.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{}), RecoveryMiddleware(log))
+	e.GET("/panic", func(c echo.Context) error { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(CorrelationIDHeader, "cid-panic")
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 after a panic, got %d", rec.Code)
+	}
+	entries := sink.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected the panic and the request to be logged, got %v", entries)
+	}
+	panicked := entries[0]
+	if panicked.Level != types.ErrorLevel || panicked.Fields["panic"] != "boom" || panicked.CorrelationID != "cid-panic" {
+		t.Errorf("Expected an error entry for the panic, got %+v", panicked)
+	}
+	if panicked.Error == nil || panicked.Error.Message != "boom" || !strings.Contains(panicked.Error.Stack, "middleware_test.go") {
+		t.Errorf("Expected the panic as the error with its stack, got %+v", panicked.Error)
+	}
+	if entries[1].Fields["http.statusCode"] != http.StatusInternalServerError {
+		t.Errorf("Expected the request to be logged with a 500, got %v", entries[1].Fields)
+	}
+}
+
+func TestRecoveryMiddlewareAlone(t *testing.T) {
+	log, sink := newTestLogger()
+	e := echo.New()
+	e.Use(RecoveryMiddleware(log))
+	e.GET("/panic", func(c echo.Context) error { panic(io.ErrUnexpectedEOF) })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].CorrelationID == "" {
+		t.Fatalf("Expected the panic logged with a correlation ID, got %v", entries)
+	}
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get(CorrelationIDHeader) != entries[0].CorrelationID {
+		t.Errorf("Expected a 500 echoing the correlation ID, got %d and %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,122 @@
+package gin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isJSON reports whether contentType is application/json or a +json
+// media type.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// captureRequestBody adds up to max bytes of a JSON request body to
+// fields. The bytes read are put back in front of the rest of the body so
+// the handler still sees all of it.
+func captureRequestBody(r *http.Request, max int, fields map[string]interface{}) {
+	if r.Body == nil || r.Body == http.NoBody || !isJSON(r.Header.Get("Content-Type")) {
+		return
+	}
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(prefix), r.Body))
+	if err != nil || len(prefix) == 0 {
+		return
+	}
+	addBody(fields, "http.requestBody", prefix, max, int(r.ContentLength))
+}
+
+// addBody sets key to body parsed, so keys inside it are redacted. A body
+// that cannot be parsed, because it is longer than max or not valid JSON,
+// is left out: only pattern rules would apply to it as a raw string, so
+// secrets under sensitive keys would reach the log. key+"Truncated" or
+// key+"Invalid" is set instead, with key+"Size" holding the size of the
+// whole body when known (size < 0 means unknown).
+func addBody(fields map[string]interface{}, key string, body []byte, max, size int) {
+	if len(body) > max {
+		fields[key+"Truncated"] = true
+		if size >= 0 {
+			fields[key+"Size"] = size
+		}
+		return
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		fields[key] = parsed
+		return
+	}
+	fields[key+"Invalid"] = true
+	fields[key+"Size"] = len(body)
+}
+
+// bodyWriter tees up to max bytes of a JSON response into buf. Capture
+// stops for good once the response is flushed or is an event stream, so
+// streamed responses are never buffered.
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	max       int
+	checked   bool
+	capturing bool
+}
+
+func newBodyWriter(w gin.ResponseWriter, max int) *bodyWriter {
+	return &bodyWriter{ResponseWriter: w, max: max}
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush marks the response as streamed and drops what was captured.
+func (w *bodyWriter) Flush() {
+	w.stop()
+	w.ResponseWriter.Flush()
+}
+
+func (w *bodyWriter) capture(b []byte) {
+	if !w.checked {
+		w.checked = true
+		w.capturing = isJSON(w.Header().Get("Content-Type"))
+	}
+	if !w.capturing {
+		return
+	}
+	// Keep one byte past max to tell a truncated body apart.
+	if room := w.max + 1 - w.buf.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.buf.Write(b)
+	}
+}
+
+func (w *bodyWriter) stop() {
+	w.checked = true
+	w.capturing = false
+	w.buf = bytes.Buffer{}
+}
+
+// addBody adds the captured response body to fields, if any.
+func (w *bodyWriter) addBody(fields map[string]interface{}) {
+	if !w.capturing || w.buf.Len() == 0 {
+		return
+	}
+	addBody(fields, "http.responseBody", w.buf.Bytes(), w.max, w.Size())
+}
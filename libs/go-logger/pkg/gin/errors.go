@@ -0,0 +1,55 @@
+package gin
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addErrors adds the errors attached to the request with c.Error to
+// fields: those typed gin.ErrorTypePublic as http.publicErrors and all
+// others, including bind and render errors, as http.privateErrors, so
+// internal errors can be routed apart from the ones shown to clients.
+// Each error is logged with its type, message and meta, which the logger
+// redacts like any other field.
+func addErrors(fields map[string]interface{}, errs []*gin.Error) {
+	var public, private []interface{}
+	for _, err := range errs {
+		entry := map[string]interface{}{
+			"type":    errorTypeName(err.Type),
+			"message": err.Error(),
+		}
+		if err.Meta != nil {
+			entry["meta"] = err.Meta
+		}
+		if err.Type != gin.ErrorTypeAny && err.Type&gin.ErrorTypePublic != 0 {
+			public = append(public, entry)
+		} else {
+			private = append(private, entry)
+		}
+	}
+	if len(public) > 0 {
+		fields["http.publicErrors"] = public
+	}
+	if len(private) > 0 {
+		fields["http.privateErrors"] = private
+	}
+}
+
+// errorTypeName names the most specific flag of t.
+func errorTypeName(t gin.ErrorType) string {
+	switch {
+	case t == gin.ErrorTypeAny:
+		return "any"
+	case t&gin.ErrorTypeBind != 0:
+		return "bind"
+	case t&gin.ErrorTypeRender != 0:
+		return "render"
+	case t&gin.ErrorTypePublic != 0:
+		return "public"
+	case t&gin.ErrorTypePrivate != 0:
+		return "private"
+	default:
+		return strconv.FormatUint(uint64(t), 10)
+	}
+}
@@ -0,0 +1,158 @@
+// Package gin provides request logging middleware for the Gin web
+// framework.
+package gin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/reqlog"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// CorrelationIDHeader carries the correlation ID of a request and is
+	// echoed on the response.
+	CorrelationIDHeader = reqlog.CorrelationIDHeader
+	// RequestIDHeader is used as the correlation ID when CorrelationIDHeader
+	// is absent.
+	RequestIDHeader = reqlog.RequestIDHeader
+
+	defaultMaxBodyBytes = 4096
+)
+
+// CorrelationHeaders names the headers the correlation ID is read from,
+// in order, and echoed in. The defaults are CorrelationIDHeader then
+// RequestIDHeader, and CorrelationIDHeader.
+type CorrelationHeaders = reqlog.CorrelationHeaders
+
+// RequestLoggingOptions configures RequestLoggingMiddleware.
+type RequestLoggingOptions struct {
+	// LogRequestBody and LogResponseBody add the JSON request and response
+	// bodies as the http.requestBody and http.responseBody fields. Bodies
+	// are parsed so the logger redacts them key by key; other content
+	// types and streamed responses are never captured. Both default to off.
+	LogRequestBody  bool
+	LogResponseBody bool
+	// MaxBodyBytes caps each captured body. Longer bodies, and bodies that
+	// are not valid JSON, are never logged since they could not be redacted
+	// key by key: http.requestBodyTruncated or http.requestBodyInvalid (and
+	// the http.responseBody counterparts) are set instead, along with
+	// http.requestBodySize or http.responseBodySize when the size is
+	// known. Defaults to 4096.
+	MaxBodyBytes int
+	// LogHeaders adds the request headers as the http.headers field, with
+	// Authorization, Cookie and API key headers masked (see
+	// nethttp.RedactHeaders). Defaults to off.
+	LogHeaders bool
+	// SkipPaths lists request paths that are not logged, e.g. /health or
+	// /metrics. Their requests still get a correlation ID.
+	SkipPaths []string
+	// SkipSuccessfulHealthChecks skips requests to /health, /healthz,
+	// /livez, /readyz and /ping answered with a status below 400, so
+	// probes do not flood the logs while failing checks are still logged.
+	SkipSuccessfulHealthChecks bool
+	// Skipper, when set, is called before the handler; requests it returns
+	// true for are not logged.
+	Skipper func(*gin.Context) bool
+	// Headers overrides the correlation ID headers, e.g. to read
+	// X-Request-ID first or echo the ID under several names.
+	Headers CorrelationHeaders
+}
+
+// RequestLoggingMiddleware logs every request with its method, path,
+// route template, query, status code, duration, correlation ID and the
+// errors handlers attached with c.Error, and optionally its headers and
+// bodies. Sensitive query parameters and
+// headers are masked. The route template (e.g. /orders/:id) is logged
+// as http.route so it can label metrics without the cardinality of the
+// concrete path. The correlation ID is read from the request headers or
+// generated, stored in the request context together with a logger bound
+// to it (see FromContext in the root package) and echoed on the response.
+func RequestLoggingMiddleware(log types.Logger, options RequestLoggingOptions) gin.HandlerFunc {
+	if options.MaxBodyBytes <= 0 {
+		options.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	skip := make(map[string]struct{}, len(options.SkipPaths))
+	for _, path := range options.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		var reqLog types.Logger
+		c.Request, reqLog = reqlog.BindHTTPHeaders(c.Request, c.Writer.Header(), log, options.Headers)
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+		if options.Skipper != nil && options.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		fields := map[string]interface{}{}
+		if options.LogHeaders {
+			fields["http.headers"] = reqlog.RedactHeaders(c.Request.Header)
+		}
+		if options.LogRequestBody {
+			captureRequestBody(c.Request, options.MaxBodyBytes, fields)
+		}
+		var bw *bodyWriter
+		if options.LogResponseBody {
+			bw = newBodyWriter(c.Writer, options.MaxBodyBytes)
+			c.Writer = bw
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if options.SkipSuccessfulHealthChecks && reqlog.IsSuccessfulHealthCheck(c.Request.URL.Path, status) {
+			return
+		}
+		if bw != nil {
+			bw.addBody(fields)
+		}
+		if len(c.Errors) > 0 {
+			addErrors(fields, c.Errors)
+		}
+		if route := c.FullPath(); route != "" {
+			fields["http.route"] = route
+		}
+		reqlog.LogHTTPRequest(reqLog, c.Request, status, time.Since(start), fields)
+	}
+}
+
+// RecoveryMiddleware recovers panics raised by the handlers after it,
+// logs them at error level with the panic value as the error and its
+// stack as the error stack, and answers with a 500. It replaces
+// gin.Recovery. Register it after RequestLoggingMiddleware so the panic
+// is logged with the request's correlation ID and the request line
+// records the 500; used alone it binds the correlation ID itself.
+func RecoveryMiddleware(log types.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if logctx.GetCorrelationID(c.Request.Context()) == "" {
+			c.Request, _ = reqlog.BindHTTP(c.Request, c.Writer.Header(), log)
+		}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			reqlog.LogPanic(log.WithContext(c.Request.Context()), c.Request, p)
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+
+		c.Next()
+	}
+}
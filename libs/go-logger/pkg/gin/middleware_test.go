@@ -0,0 +1,380 @@
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type mockSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func (s *mockSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
+
+func newTestLogger() (types.Logger, *mockSink) {
+	sink := &mockSink{}
+	return logger.NewLogger(types.LogOptions{Sink: sink}), sink
+}
+
+// serve runs req through a router using the middleware and returns the
+// last entry logged.
+func serve(t *testing.T, options RequestLoggingOptions, register func(r *gin.Engine), req *http.Request) (*httptest.ResponseRecorder, types.LogEntry) {
+	t.Helper()
+	log, sink := newTestLogger()
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(log, options))
+	register(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	entries := sink.Entries()
+	if len(entries) == 0 {
+		t.Fatal("Expected a log entry")
+	}
+	return rec, entries[len(entries)-1]
+}
+
+func TestRequestLoggingMiddleware(t *testing.T) {
+	var handlerID string
+	req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+	req.Header.Set(CorrelationIDHeader, "cid-gin")
+
+	rec, entry := serve(t, RequestLoggingOptions{}, func(r *gin.Engine) {
+		r.GET("/orders/:id", func(c *gin.Context) {
+			handlerID = logctx.GetCorrelationID(c.Request.Context())
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		})
+	}, req)
+
+	if handlerID != "cid-gin" || rec.Header().Get(CorrelationIDHeader) != "cid-gin" {
+		t.Errorf("Expected correlation ID in context and response, got %q and %q", handlerID, rec.Header().Get(CorrelationIDHeader))
+	}
+	if entry.Level != types.WarnLevel || entry.Fields["http.statusCode"] != 404 || entry.Fields["http.path"] != "/orders/42" {
+		t.Errorf("Expected warn entry for 404 /orders/42, got %s %v", entry.Level, entry.Fields)
+	}
+	if _, ok := entry.Fields["durationMs"].(float64); !ok {
+		t.Errorf("Expected a numeric durationMs, got %v", entry.Fields["durationMs"])
+	}
+	if _, ok := entry.Fields["duration"].(string); !ok {
+		t.Errorf("Expected a readable duration, got %v", entry.Fields["duration"])
+	}
+	if entry.Fields["http.route"] != "/orders/:id" {
+		t.Errorf("Expected route /orders/:id, got %v", entry.Fields["http.route"])
+	}
+	if _, ok := entry.Fields["http.responseBody"]; ok {
+		t.Error("Expected no body capture by default")
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipPaths(t *testing.T) {
+	log, sink := newTestLogger()
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{SkipPaths: []string{"/health"}}))
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].Fields["http.path"] != "/missing" {
+		t.Errorf("Expected only /missing to be logged, got %v", entries)
+	}
+	if _, ok := entries[0].Fields["http.route"]; ok {
+		t.Error("Expected no route for an unmatched request")
+	}
+	if rec.Header().Get(CorrelationIDHeader) == "" {
+		t.Error("Expected skipped requests to still get a correlation ID")
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipsSuccessfulHealthChecks(t *testing.T) {
+	log, sink := newTestLogger()
+	healthy := true
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{SkipSuccessfulHealthChecks: true}))
+	r.GET("/healthz", func(c *gin.Context) {
+		if healthy {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	healthy = false
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].Fields["http.statusCode"] != http.StatusServiceUnavailable {
+		t.Errorf("Expected only the failing check to be logged, got %v", entries)
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipper(t *testing.T) {
+	log, sink := newTestLogger()
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{
+		Skipper: func(c *gin.Context) bool { return c.GetHeader("User-Agent") == "kube-probe/1.29" },
+	}))
+	r.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	probe := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	probe.Header.Set("User-Agent", "kube-probe/1.29")
+	r.ServeHTTP(httptest.NewRecorder(), probe)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if got := len(sink.Entries()); got != 1 {
+		t.Errorf("Expected 1 logged request, got %d", got)
+	}
+}
+
+func TestRequestLoggingMiddlewareRedactsQueryAndHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/orders?token=abc&status=open", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Accept", "application/json")
+
+	_, entry := serve(t, RequestLoggingOptions{LogHeaders: true}, func(r *gin.Engine) {
+		r.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	}, req)
+
+	query, _ := entry.Fields["http.query"].(map[string]interface{})
+	if query["token"] != "***" || query["status"] != "open" {
+		t.Errorf("Expected a masked token in the query, got %v", entry.Fields["http.query"])
+	}
+	headers, _ := entry.Fields["http.headers"].(map[string]interface{})
+	if headers["authorization"] != "***" || headers["accept"] != "application/json" {
+		t.Errorf("Expected a masked authorization header, got %v", entry.Fields["http.headers"])
+	}
+}
+
+func TestRequestLoggingMiddlewareCapturesBodies(t *testing.T) {
+	var handlerBody string
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user":"ana","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	_, entry := serve(t, RequestLoggingOptions{LogRequestBody: true, LogResponseBody: true}, func(r *gin.Engine) {
+		r.POST("/login", func(c *gin.Context) {
+			b, _ := io.ReadAll(c.Request.Body)
+			handlerBody = string(b)
+			c.JSON(http.StatusOK, gin.H{"token": "abc"})
+		})
+	}, req)
+
+	if handlerBody != `{"user":"ana","password":"hunter2"}` {
+		t.Errorf("Expected the handler to read the full body, got %q", handlerBody)
+	}
+	body, ok := entry.Fields["http.requestBody"].(map[string]interface{})
+	if !ok || body["user"] != "ana" || body["password"] == "hunter2" {
+		t.Errorf("Expected a parsed, redacted request body, got %v", entry.Fields["http.requestBody"])
+	}
+	if _, ok := entry.Fields["http.responseBody"].(map[string]interface{}); !ok {
+		t.Errorf("Expected a parsed response body, got %v", entry.Fields["http.responseBody"])
+	}
+}
+
+func TestRequestLoggingMiddlewareTruncatesBodies(t *testing.T) {
+	body := `{"user":"ana","password":"hunter2"}`
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	_, entry := serve(t, RequestLoggingOptions{LogRequestBody: true, LogResponseBody: true, MaxBodyBytes: 30}, func(r *gin.Engine) {
+		r.POST("/items", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"id": "0123456789", "token": "secret-token-value"})
+		})
+	}, req)
+
+	if _, ok := entry.Fields["http.requestBody"]; ok {
+		t.Errorf("Expected an oversized request body to be left out, got %v", entry.Fields["http.requestBody"])
+	}
+	if entry.Fields["http.requestBodyTruncated"] != true || entry.Fields["http.requestBodySize"] != len(body) {
+		t.Errorf("Expected the truncated marker and size, got %v", entry.Fields)
+	}
+	if _, ok := entry.Fields["http.responseBody"]; ok {
+		t.Errorf("Expected an oversized response body to be left out, got %v", entry.Fields["http.responseBody"])
+	}
+	if entry.Fields["http.responseBodyTruncated"] != true {
+		t.Errorf("Expected a truncated response body, got %v", entry.Fields)
+	}
+	if strings.Contains(fmt.Sprint(entry.Fields), "hunter2") || strings.Contains(fmt.Sprint(entry.Fields), "secret-token") {
+		t.Errorf("Expected no secret in the fields, got %v", entry.Fields)
+	}
+}
+
+func TestRequestLoggingMiddlewareLeavesOutInvalidBodies(t *testing.T) {
+	body := `{"password":"hunter2"`
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	_, entry := serve(t, RequestLoggingOptions{LogRequestBody: true}, func(r *gin.Engine) {
+		r.POST("/items", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	}, req)
+
+	if _, ok := entry.Fields["http.requestBody"]; ok {
+		t.Errorf("Expected an invalid body to be left out, got %v", entry.Fields["http.requestBody"])
+	}
+	if entry.Fields["http.requestBodyInvalid"] != true || entry.Fields["http.requestBodySize"] != len(body) {
+		t.Errorf("Expected the invalid marker and size, got %v", entry.Fields)
+	}
+}
+
+func TestRequestLoggingMiddlewareSkipsNonJSONAndStreams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("plain text"))
+	req.Header.Set("Content-Type", "text/plain")
+
+	_, entry := serve(t, RequestLoggingOptions{LogRequestBody: true, LogResponseBody: true}, func(r *gin.Engine) {
+		r.POST("/upload", func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+	}, req)
+	if _, ok := entry.Fields["http.requestBody"]; ok {
+		t.Errorf("Expected no request body for text/plain, got %v", entry.Fields["http.requestBody"])
+	}
+	if _, ok := entry.Fields["http.responseBody"]; ok {
+		t.Errorf("Expected no response body for text/plain, got %v", entry.Fields["http.responseBody"])
+	}
+
+	_, entry = serve(t, RequestLoggingOptions{LogResponseBody: true}, func(r *gin.Engine) {
+		r.GET("/stream", func(c *gin.Context) {
+			c.Header("Content-Type", "application/json")
+			c.Writer.WriteString(`{"chunk":1}`)
+			c.Writer.Flush()
+			c.Writer.WriteString(`{"chunk":2}`)
+		})
+	}, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if _, ok := entry.Fields["http.responseBody"]; ok {
+		t.Errorf("Expected no body for a flushed response, got %v", entry.Fields["http.responseBody"])
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	log, sink := newTestLogger()
+	r := gin.New()
+	r.Use(RequestLoggingMiddleware(log, RequestLoggingOptions{}), RecoveryMiddleware(log))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(CorrelationIDHeader, "cid-panic")
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 after a panic, got %d", rec.Code)
+	}
+	entries := sink.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected the panic and the request to be logged, got %v", entries)
+	}
+	panicked := entries[0]
+	if panicked.Level != types.ErrorLevel || panicked.Fields["panic"] != "boom" || panicked.CorrelationID != "cid-panic" {
+		t.Errorf("Expected an error entry for the panic, got %+v", panicked)
+	}
+	if panicked.Error == nil || panicked.Error.Message != "boom" || !strings.Contains(panicked.Error.Stack, "middleware_test.go") {
+		t.Errorf("Expected the panic as the error with its stack, got %+v", panicked.Error)
+	}
+	if entries[1].Fields["http.statusCode"] != http.StatusInternalServerError {
+		t.Errorf("Expected the request to be logged with a 500, got %v", entries[1].Fields)
+	}
+}
+
+func TestRecoveryMiddlewareAlone(t *testing.T) {
+	log, sink := newTestLogger()
+	r := gin.New()
+	r.Use(RecoveryMiddleware(log))
+	r.GET("/panic", func(c *gin.Context) { panic(io.ErrUnexpectedEOF) })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	entries := sink.Entries()
+	if len(entries) != 1 || entries[0].CorrelationID == "" {
+		t.Fatalf("Expected the panic to be logged with a correlation ID, got %v", entries)
+	}
+	if entries[0].CorrelationID != rec.Header().Get(CorrelationIDHeader) {
+		t.Errorf("Expected the echoed correlation ID, got %q", entries[0].CorrelationID)
+	}
+}
+
+func TestRequestLoggingMiddlewareLogsErrors(t *testing.T) {
+	_, entry := serve(t, RequestLoggingOptions{}, func(r *gin.Engine) {
+		r.POST("/orders", func(c *gin.Context) {
+			_ = c.Error(errors.New("invalid quantity")).SetType(gin.ErrorTypePublic)
+			_ = c.Error(errors.New("db timeout for joao@example.com")).SetMeta(map[string]interface{}{"token": "abc", "query": "orders"})
+			_ = c.Error(errors.New("EOF")).SetType(gin.ErrorTypeBind)
+			c.Status(http.StatusBadRequest)
+		})
+	}, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	public, _ := entry.Fields["http.publicErrors"].([]interface{})
+	if len(public) != 1 {
+		t.Fatalf("Expected one public error, got %v", entry.Fields["http.publicErrors"])
+	}
+	if e := public[0].(map[string]interface{}); e["type"] != "public" || e["message"] != "invalid quantity" {
+		t.Errorf("Expected the public error, got %v", e)
+	}
+
+	private, _ := entry.Fields["http.privateErrors"].([]interface{})
+	if len(private) != 2 {
+		t.Fatalf("Expected two private errors, got %v", entry.Fields["http.privateErrors"])
+	}
+	internal := private[0].(map[string]interface{})
+	if internal["type"] != "private" || internal["message"] != "db timeout for ***" {
+		t.Errorf("Expected the redacted private error, got %v", internal)
+	}
+	if meta := internal["meta"].(map[string]interface{}); meta["token"] != "***" || meta["query"] != "orders" {
+		t.Errorf("Expected the redacted meta, got %v", meta)
+	}
+	if bind := private[1].(map[string]interface{}); bind["type"] != "bind" {
+		t.Errorf("Expected the bind error, got %v", bind)
+	}
+}
+
+func TestRequestLoggingMiddlewareHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(RequestIDHeader, "rid-gin")
+
+	rec, entry := serve(t, RequestLoggingOptions{Headers: CorrelationHeaders{
+		Request:  []string{RequestIDHeader},
+		Response: []string{RequestIDHeader, CorrelationIDHeader},
+	}}, func(r *gin.Engine) {
+		r.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	}, req)
+
+	if entry.CorrelationID != "rid-gin" {
+		t.Errorf("Expected the ID from X-Request-ID, got %q", entry.CorrelationID)
+	}
+	if rec.Header().Get(RequestIDHeader) != "rid-gin" || rec.Header().Get(CorrelationIDHeader) != "rid-gin" {
+		t.Errorf("Expected the ID echoed under both headers, got %v", rec.Header())
+	}
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+)
+
+func TestClientInterceptorPropagatesCorrelationID(t *testing.T) {
+	client, sink := newHealthClient(t)
+
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-client")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := sink.Entries()[0].CorrelationID; got != "cid-client" {
+		t.Errorf("Expected correlation ID propagated to the server, got %q", got)
+	}
+}
+
+func TestOutgoingContext(t *testing.T) {
+	ctx := outgoingContext(logctx.WithCorrelationID(context.Background(), "cid-1"))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got := md.Get(CorrelationIDKey); len(got) != 1 || got[0] != "cid-1" {
+		t.Errorf("Expected correlation ID in outgoing metadata, got %v", got)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), CorrelationIDKey, "explicit")
+	ctx = outgoingContext(logctx.WithCorrelationID(ctx, "cid-2"))
+	md, _ = metadata.FromOutgoingContext(ctx)
+	if got := md.Get(CorrelationIDKey); len(got) != 1 || got[0] != "explicit" {
+		t.Errorf("Expected explicit metadata to be kept, got %v", got)
+	}
+
+	if _, ok := metadata.FromOutgoingContext(outgoingContext(context.Background())); ok {
+		t.Error("Expected no metadata without a correlation ID")
+	}
+}
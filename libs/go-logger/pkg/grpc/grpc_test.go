@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func (s *mockSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
+
+// newHealthClient serves the health service through the interceptors over
+// an in-memory listener and returns a client using the client interceptors.
+func newHealthClient(t *testing.T) (healthpb.HealthClient, *mockSink) {
+	t.Helper()
+	sink := &mockSink{}
+	log := logger.NewLogger(types.LogOptions{Sink: sink})
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(log)),
+		grpc.StreamInterceptor(StreamServerInterceptor(log)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn), sink
+}
+
+// waitForEntries waits until sink holds n entries; stream calls are logged
+// by the server after the client has already returned.
+func waitForEntries(t *testing.T, sink *mockSink, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(sink.Entries()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d entries, got %d", n, len(sink.Entries()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,110 @@
+// Package grpc provides gRPC interceptors that propagate correlation IDs
+// and log every call with its method, status code and duration.
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/reqlog"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// CorrelationIDKey is the metadata key carrying the correlation ID.
+	CorrelationIDKey = "x-correlation-id"
+	// RequestIDKey is used as the correlation ID when CorrelationIDKey is
+	// absent from the incoming metadata.
+	RequestIDKey = "x-request-id"
+)
+
+// UnaryServerInterceptor logs every unary call. The correlation ID is read
+// from the incoming metadata or generated, stored in the context together
+// with a logger bound to it and returned in the trailers.
+func UnaryServerInterceptor(log types.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, id, callLog := withCorrelation(ctx, log)
+		// SetTrailer only fails outside a real server transport.
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(CorrelationIDKey, id))
+
+		resp, err := handler(ctx, req)
+
+		logCall(callLog, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor. The call is logged once the stream ends.
+func StreamServerInterceptor(log types.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, id, callLog := withCorrelation(ss.Context(), log)
+		ss.SetTrailer(metadata.Pairs(CorrelationIDKey, id))
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+
+		logCall(callLog, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// withCorrelation resolves the correlation ID of an incoming call and
+// returns ctx carrying it and a logger bound to it.
+func withCorrelation(ctx context.Context, log types.Logger) (context.Context, string, types.Logger) {
+	id := logctx.SanitizeCorrelationID(incomingValue(ctx, CorrelationIDKey))
+	if id == "" {
+		id = incomingValue(ctx, RequestIDKey)
+	}
+	return reqlog.Bind(ctx, id, log)
+}
+
+func incomingValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// logCall logs a finished call at a level derived from its status code:
+// info for OK, warn for codes caused by the caller and error otherwise.
+func logCall(log types.Logger, method string, err error, duration time.Duration) {
+	code := status.Code(err)
+	fields := map[string]interface{}{
+		"grpc.method": method,
+		"grpc.code":   code.String(),
+		"durationMs":  float64(duration) / float64(time.Millisecond),
+	}
+	msg := method + " " + code.String()
+
+	switch code {
+	case codes.OK:
+		log.Info(msg, fields)
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		log.WithError(err).Warn(msg, fields)
+	default:
+		log.WithError(err).Error(msg, fields)
+	}
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	client, sink := newHealthClient(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), CorrelationIDKey, "cid-grpc")
+	var trailer metadata.MD
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Trailer(&trailer)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := trailer.Get(CorrelationIDKey); len(got) != 1 || got[0] != "cid-grpc" {
+		t.Errorf("Expected correlation ID in trailers, got %v", got)
+	}
+	entries := sink.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.CorrelationID != "cid-grpc" || entry.Level != types.InfoLevel {
+		t.Errorf("Expected info entry with correlation ID, got %+v", entry)
+	}
+	if entry.Fields["grpc.method"] != healthpb.Health_Check_FullMethodName || entry.Fields["grpc.code"] != "OK" {
+		t.Errorf("Expected method and code fields, got %v", entry.Fields)
+	}
+	if _, ok := entry.Fields["durationMs"].(float64); !ok {
+		t.Errorf("Expected durationMs, got %v", entry.Fields["durationMs"])
+	}
+}
+
+func TestUnaryServerInterceptorFailure(t *testing.T) {
+	client, sink := newHealthClient(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDKey, "req-1")
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+
+	entry := sink.Entries()[0]
+	if entry.Level != types.WarnLevel || entry.Fields["grpc.code"] != "NotFound" {
+		t.Errorf("Expected warn entry with NotFound code, got %+v", entry)
+	}
+	if entry.CorrelationID != "req-1" {
+		t.Errorf("Expected x-request-id fallback, got %q", entry.CorrelationID)
+	}
+	if entry.Error == nil {
+		t.Error("Expected the status error to be attached")
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client, sink := newHealthClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cancel()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+
+	waitForEntries(t, sink, 1)
+	entry := sink.Entries()[0]
+	if entry.Fields["grpc.method"] != healthpb.Health_Watch_FullMethodName {
+		t.Errorf("Expected Watch method, got %v", entry.Fields["grpc.method"])
+	}
+	if entry.CorrelationID == "" {
+		t.Error("Expected a generated correlation ID")
+	}
+}
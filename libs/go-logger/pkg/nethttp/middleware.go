@@ -0,0 +1,121 @@
+// Package nethttp provides request logging middleware for net/http
+// compatible routers such as http.ServeMux and chi.
+package nethttp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/internal/reqlog"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// CorrelationIDHeader carries the correlation ID of a request and is
+	// echoed on the response.
+	CorrelationIDHeader = reqlog.CorrelationIDHeader
+	// RequestIDHeader is used as the correlation ID when CorrelationIDHeader
+	// is absent.
+	RequestIDHeader = reqlog.RequestIDHeader
+)
+
+// CorrelationHeaders names the headers the correlation ID is read from,
+// in order, and echoed in. The defaults are CorrelationIDHeader then
+// RequestIDHeader, and CorrelationIDHeader.
+type CorrelationHeaders = reqlog.CorrelationHeaders
+
+// Options configures LoggingMiddlewareWithOptions and
+// ErrorLoggingMiddlewareWithOptions.
+type Options struct {
+	// Route returns the route pattern logged as http.route. Optional.
+	Route RouteFunc
+	// Headers overrides the correlation ID headers, e.g. to read
+	// X-Request-ID first or echo the ID under several names.
+	Headers CorrelationHeaders
+}
+
+// LoggingMiddleware logs every request with its method, path, query,
+// status code, duration and correlation ID. Sensitive query parameters are
+// masked, see RedactQuery. The correlation ID is read from the request
+// headers or generated, stored in the request context together with a
+// logger bound to it (see FromContext in the root package) and echoed on
+// the response.
+func LoggingMiddleware(log types.Logger) func(http.Handler) http.Handler {
+	return LoggingMiddlewareWithRoute(log, nil)
+}
+
+// RouteFunc returns the route pattern that matched r, such as
+// /users/{id}, or "" when it is unknown. It is called after the handler
+// ran, once routing is complete.
+type RouteFunc func(r *http.Request) string
+
+// LoggingMiddlewareWithRoute is LoggingMiddleware that also logs the
+// pattern returned by route as http.route, so requests can be grouped
+// without the cardinality of the concrete path. A nil route or an empty
+// pattern leaves the field out.
+func LoggingMiddlewareWithRoute(log types.Logger, route RouteFunc) func(http.Handler) http.Handler {
+	return LoggingMiddlewareWithOptions(log, Options{Route: route})
+}
+
+// LoggingMiddlewareWithOptions is LoggingMiddleware configured by options.
+func LoggingMiddlewareWithOptions(log types.Logger, options Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r, reqLog := reqlog.BindHTTPHeaders(r, w.Header(), log, options.Headers)
+			rw := newResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			reqlog.LogHTTPRequest(reqLog, r, rw.statusCode, time.Since(start), routeFields(r, options.Route))
+		})
+	}
+}
+
+// routeFields returns the fields holding the route pattern of r, if known.
+func routeFields(r *http.Request, route RouteFunc) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if route != nil {
+		if pattern := route(r); pattern != "" {
+			fields["http.route"] = pattern
+		}
+	}
+	return fields
+}
+
+// ErrorLoggingMiddleware is like LoggingMiddleware but only logs requests
+// that failed with a 4xx or 5xx status. Panics raised by next are
+// recovered, logged with their stack and answered with a 500.
+func ErrorLoggingMiddleware(log types.Logger) func(http.Handler) http.Handler {
+	return ErrorLoggingMiddlewareWithOptions(log, Options{})
+}
+
+// ErrorLoggingMiddlewareWithOptions is ErrorLoggingMiddleware configured
+// by options.
+func ErrorLoggingMiddlewareWithOptions(log types.Logger, options Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r, reqLog := reqlog.BindHTTPHeaders(r, w.Header(), log, options.Headers)
+			rw := newResponseWriter(w)
+
+			defer func() {
+				if p := recover(); p != nil {
+					if p == http.ErrAbortHandler {
+						panic(p)
+					}
+					reqlog.LogPanic(reqLog, r, p)
+					if !rw.wroteHeader {
+						rw.WriteHeader(http.StatusInternalServerError)
+					}
+					return
+				}
+				if rw.statusCode >= http.StatusBadRequest {
+					reqlog.LogHTTPRequest(reqLog, r, rw.statusCode, time.Since(start), routeFields(r, options.Route))
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/logger"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/stack"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func (s *mockSink) Entries() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.LogEntry(nil), s.entries...)
+}
+
+func newTestLogger() (types.Logger, *mockSink) {
+	sink := &mockSink{}
+	return logger.NewLogger(types.LogOptions{Sink: sink}), sink
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	log, sink := newTestLogger()
+	var handlerID string
+	handler := LoggingMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerID = logctx.GetCorrelationID(r.Context())
+		logger.FromContext(r.Context()).Info("inside handler")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set(CorrelationIDHeader, "cid-http")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if handlerID != "cid-http" {
+		t.Errorf("Expected correlation ID in request context, got %q", handlerID)
+	}
+	if got := rec.Header().Get(CorrelationIDHeader); got != "cid-http" {
+		t.Errorf("Expected correlation ID echoed on response, got %q", got)
+	}
+
+	entries := sink.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].CorrelationID != "cid-http" {
+		t.Errorf("Expected context logger bound to the correlation ID, got %q", entries[0].CorrelationID)
+	}
+	entry := entries[1]
+	if entry.Level != types.InfoLevel || entry.CorrelationID != "cid-http" {
+		t.Errorf("Expected info entry with correlation ID, got %+v", entry)
+	}
+	if entry.Fields["http.method"] != "POST" || entry.Fields["http.path"] != "/orders" || entry.Fields["http.statusCode"] != 201 {
+		t.Errorf("Expected request fields, got %v", entry.Fields)
+	}
+	if _, ok := entry.Fields["durationMs"].(float64); !ok {
+		t.Errorf("Expected durationMs, got %v", entry.Fields["durationMs"])
+	}
+	if d, ok := entry.Fields["duration"].(string); !ok || !strings.HasSuffix(d, "s") {
+		t.Errorf("Expected a readable duration, got %v", entry.Fields["duration"])
+	}
+}
+
+func TestLoggingMiddlewareCorrelationIDFallbacks(t *testing.T) {
+	log, _ := newTestLogger()
+	handler := LoggingMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(CorrelationIDHeader); got != "req-1" {
+		t.Errorf("Expected X-Request-ID fallback, got %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get(CorrelationIDHeader); got == "" {
+		t.Error("Expected a generated correlation ID")
+	}
+}
+
+func TestLoggingMiddlewareLevels(t *testing.T) {
+	tests := []struct {
+		status int
+		level  types.LogLevel
+	}{
+		{http.StatusOK, types.InfoLevel},
+		{http.StatusNotFound, types.WarnLevel},
+		{http.StatusBadGateway, types.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			log, sink := newTestLogger()
+			handler := LoggingMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if entries := sink.Entries(); len(entries) != 1 || entries[0].Level != tt.level {
+				t.Errorf("Expected one %s entry, got %+v", tt.level, entries)
+			}
+		})
+	}
+}
+
+func TestErrorLoggingMiddleware(t *testing.T) {
+	log, sink := newTestLogger()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	handler := ErrorLoggingMiddleware(log)(mux)
+
+	for _, path := range []string{"/ok", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 after a panic, got %d", rec.Code)
+	}
+	entries := sink.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Fields["http.path"] != "/missing" || entries[0].Level != types.WarnLevel {
+		t.Errorf("Expected warn entry for /missing, got %+v", entries[0])
+	}
+	if entries[1].Fields["panic"] != "boom" || entries[1].Level != types.ErrorLevel {
+		t.Errorf("Expected error entry for the panic, got %+v", entries[1])
+	}
+	if entries[1].Error == nil || !strings.HasPrefix(entries[1].Error.Stack, "github.com/mateusmacedo/boyscout/go-logger/pkg/nethttp.TestErrorLoggingMiddleware.func") {
+		t.Errorf("Expected the panic as the error with its stack from the handler, got %+v", entries[1].Error)
+	}
+	if frames := strings.Count(entries[1].Error.Stack, "\n\t"); frames > stack.DefaultMaxFrames {
+		t.Errorf("Expected at most %d frames, got %d", stack.DefaultMaxFrames, frames)
+	}
+}
+
+func TestLoggingMiddlewareRedactsQuery(t *testing.T) {
+	log, sink := newTestLogger()
+	handler := LoggingMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search?q=shoes&token=abc&api_key=k1&page=1&page=2", nil))
+
+	query, ok := sink.Entries()[0].Fields["http.query"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a query map, got %v", sink.Entries()[0].Fields["http.query"])
+	}
+	if query["token"] != "***" || query["api_key"] != "***" {
+		t.Errorf("Expected token and api_key to be masked, got %v", query)
+	}
+	if query["q"] != "shoes" {
+		t.Errorf("Expected q to be kept, got %v", query["q"])
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("X-Api-Key", "k1")
+	header.Set("Accept", "application/json")
+
+	got := RedactHeaders(header)
+	if got["authorization"] != "***" || got["x-api-key"] != "***" {
+		t.Errorf("Expected credentials to be masked, got %v", got)
+	}
+	if got["accept"] != "application/json" {
+		t.Errorf("Expected accept to be kept, got %v", got["accept"])
+	}
+}
+
+func TestLoggingMiddlewareWithOptionsHeaders(t *testing.T) {
+	log, sink := newTestLogger()
+	handler := LoggingMiddlewareWithOptions(log, Options{
+		Route: func(*http.Request) string { return "/orders/{id}" },
+		Headers: CorrelationHeaders{
+			Request:  []string{"X-Amzn-Trace-Id", RequestIDHeader},
+			Response: []string{RequestIDHeader, CorrelationIDHeader},
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(CorrelationIDHeader, "ignored")
+	req.Header.Set(RequestIDHeader, "rid-edge")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get(RequestIDHeader) != "rid-edge" || rec.Header().Get(CorrelationIDHeader) != "rid-edge" {
+		t.Errorf("Expected the ID echoed under both headers, got %v", rec.Header())
+	}
+	entry := sink.Entries()[0]
+	if entry.CorrelationID != "rid-edge" || entry.Fields["http.route"] != "/orders/{id}" {
+		t.Errorf("Expected the ID from the configured header and the route, got %+v", entry)
+	}
+}
@@ -0,0 +1,22 @@
+package nethttp
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/mateusmacedo/boyscout/go-logger/internal/reqlog"
+)
+
+// RedactQuery returns query as a field value with the values of sensitive
+// parameters such as token and api_key masked. Being a map, the remaining
+// parameters still go through the logger's redactor key by key. A
+// parameter given once maps to a string, one repeated to a []string.
+func RedactQuery(query url.Values) map[string]interface{} {
+	return reqlog.RedactQuery(query)
+}
+
+// RedactHeaders is RedactQuery for headers, masking Authorization, Cookie
+// and API key headers. Keys are lower-cased.
+func RedactHeaders(header http.Header) map[string]interface{} {
+	return reqlog.RedactHeaders(header)
+}
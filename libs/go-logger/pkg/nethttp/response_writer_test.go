@@ -0,0 +1,60 @@
+package nethttp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestResponseWriterStatusCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newResponseWriter(rec)
+
+	if w.statusCode != http.StatusOK {
+		t.Errorf("Expected default status 200, got %d", w.statusCode)
+	}
+	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(http.StatusTeapot)
+	if w.statusCode != http.StatusAccepted {
+		t.Errorf("Expected first status to win, got %d", w.statusCode)
+	}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var w http.ResponseWriter = newResponseWriter(rec)
+
+	f, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatal("Expected responseWriter to implement http.Flusher")
+	}
+	f.Flush()
+	if !rec.Flushed {
+		t.Error("Expected flush to reach the underlying writer")
+	}
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	if _, _, err := newResponseWriter(rec).Hijack(); err != nil || !rec.hijacked {
+		t.Errorf("Expected hijack to reach the underlying writer, got %v", err)
+	}
+
+	_, _, err := newResponseWriter(httptest.NewRecorder()).Hijack()
+	if !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Expected http.ErrNotSupported, got %v", err)
+	}
+}
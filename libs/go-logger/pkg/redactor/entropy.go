@@ -0,0 +1,41 @@
+package redactor
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultMinEntropyLength is the shortest string checked by the entropy
+// heuristic when RedactorOptions.MinEntropyLength is not set.
+const defaultMinEntropyLength = 20
+
+// shannonEntropy returns the Shannon entropy of s in bits per character,
+// computed over the frequency of its runes.
+func shannonEntropy(s string) float64 {
+	n := utf8.RuneCountInString(s)
+	if n == 0 {
+		return 0
+	}
+	counts := make(map[rune]int, n)
+	for _, c := range s {
+		counts[c]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// looksLikeSecret reports whether s is a single token, at least minLength
+// characters long, whose entropy exceeds minBits per character. Strings
+// containing whitespace are prose, not tokens, and are never matched.
+func looksLikeSecret(s string, minBits float64, minLength int) bool {
+	if utf8.RuneCountInString(s) < minLength || strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		return false
+	}
+	return shannonEntropy(s) > minBits
+}
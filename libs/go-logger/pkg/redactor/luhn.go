@@ -0,0 +1,44 @@
+package redactor
+
+// cardNumberDigits returns the digits of s when s looks like a payment card
+// number: 13 to 19 digits, optionally separated by spaces or dashes.
+func cardNumberDigits(s string) (string, bool) {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '-':
+		default:
+			return "", false
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return "", false
+	}
+	return string(digits), true
+}
+
+// luhnValid reports whether digits passes the Luhn checksum.
+func luhnValid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
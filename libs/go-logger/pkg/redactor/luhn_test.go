@@ -0,0 +1,45 @@
+package redactor
+
+import "testing"
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"5500005555555559", true},
+		{"378282246310005", true},
+		{"4111111111111112", false},
+		{"1234567890123456", false},
+		{"", false},
+		{"41a1", false},
+	}
+
+	for _, tt := range tests {
+		if got := luhnValid(tt.digits); got != tt.want {
+			t.Errorf("luhnValid(%q) = %v, want %v", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestCardNumberDigits(t *testing.T) {
+	tests := []struct {
+		input  string
+		digits string
+		ok     bool
+	}{
+		{"4111 1111 1111 1111", "4111111111111111", true},
+		{"4111-1111-1111-1111", "4111111111111111", true},
+		{"123456789012", "", false},
+		{"12345678901234567890", "", false},
+		{"joao@example.com", "", false},
+	}
+
+	for _, tt := range tests {
+		digits, ok := cardNumberDigits(tt.input)
+		if digits != tt.digits || ok != tt.ok {
+			t.Errorf("cardNumberDigits(%q) = (%q, %v), want (%q, %v)", tt.input, digits, ok, tt.digits, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package redactor
+
+import "github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+
+// nopRedactor returns every value unchanged.
+type nopRedactor struct{}
+
+func (nopRedactor) Redact(value interface{}) interface{} { return value }
+
+// NopRedactor returns a redactor that leaves every value unchanged. Loggers
+// recognize it and skip redaction altogether; see LogOptions.DisableRedaction
+// for the tradeoff.
+func NopRedactor() types.Redactor {
+	return nopRedactor{}
+}
@@ -0,0 +1,138 @@
+package redactor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one element of a parsed RedactorOptions.Paths entry.
+type pathSegment struct {
+	// key is the map key or struct field name, or "*" for any; unused
+	// for index segments.
+	key     string
+	isIndex bool
+	// index is the slice index, or -1 for any.
+	index int
+}
+
+// pathStep is one element of the location of the value being redacted:
+// a key, or a slice index when index is not negative.
+type pathStep struct {
+	key   string
+	index int
+}
+
+// pathStack is the location of the value being redacted.
+type pathStack struct {
+	steps []pathStep
+}
+
+// parsePath parses a path such as payments[*].cardNumber. It reports
+// false for malformed paths.
+func parsePath(path string) ([]pathSegment, bool) {
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		name, rest := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, rest = part[:i], part[i:]
+		}
+		if name != "" {
+			segments = append(segments, pathSegment{key: name})
+		} else if rest == "" {
+			return nil, false
+		}
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, false
+			}
+			index, err := -1, error(nil)
+			if rest[1:end] != "*" {
+				index, err = strconv.Atoi(rest[1:end])
+				if err != nil || index < 0 {
+					return nil, false
+				}
+			}
+			segments = append(segments, pathSegment{isIndex: true, index: index})
+			rest = rest[end+1:]
+		}
+	}
+	return segments, len(segments) > 0
+}
+
+func (s pathSegment) matches(step pathStep) bool {
+	if s.isIndex {
+		return step.index >= 0 && (s.index < 0 || s.index == step.index)
+	}
+	return step.index < 0 && (s.key == "*" || strings.EqualFold(s.key, step.key))
+}
+
+// enterKey appends key to the current path, one step per dotted segment
+// so a flat key such as db.password is located like a nested one. It
+// returns the length to restore with leave, and does nothing when paths
+// are not tracked.
+func (w *walk) enterKey(key string) int {
+	if w.path == nil {
+		return 0
+	}
+	mark := len(w.path.steps)
+	for {
+		i := strings.IndexByte(key, '.')
+		if i < 0 {
+			w.path.steps = append(w.path.steps, pathStep{key: key, index: -1})
+			return mark
+		}
+		w.path.steps = append(w.path.steps, pathStep{key: key[:i], index: -1})
+		key = key[i+1:]
+	}
+}
+
+// enterIndex appends a slice index to the current path, see enterKey.
+func (w *walk) enterIndex(index int) int {
+	if w.path == nil {
+		return 0
+	}
+	mark := len(w.path.steps)
+	w.path.steps = append(w.path.steps, pathStep{index: index})
+	return mark
+}
+
+func (w *walk) leave(mark int) {
+	if w.path != nil {
+		w.path.steps = w.path.steps[:mark]
+	}
+}
+
+// matchesPath reports whether the current path matches one of Paths.
+func (r *rules) matchesPath(w *walk) bool {
+	if w.path == nil {
+		return false
+	}
+	for _, path := range r.paths {
+		if len(path) != len(w.path.steps) {
+			continue
+		}
+		matched := true
+		for i, segment := range path {
+			if !segment.matches(w.path.steps[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// lastKey returns the innermost key of the path, used to pick the mask
+// of a slice element matched by a path.
+func (p *pathStack) lastKey() string {
+	for i := len(p.steps) - 1; i >= 0; i-- {
+		if p.steps[i].index < 0 {
+			return p.steps[i].key
+		}
+	}
+	return ""
+}
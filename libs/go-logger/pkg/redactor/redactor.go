@@ -0,0 +1,984 @@
+// Package redactor masks sensitive data before it reaches a log line.
+package redactor
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMask     = "***"
+	defaultMaxDepth = 5
+)
+
+// Default patterns.
+const (
+	cpfPattern   = `\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`
+	cnpjPattern  = `\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`
+	emailPattern = `(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`
+	hashPattern  = `\b[A-Fa-f0-9]{32,64}\b` // hashes and hex tokens
+	// Phone numbers need a country code or an area code in parentheses,
+	// so bare numbers such as IDs and timestamps are not matched.
+	phonePattern = `(?:\+\d{1,3}[\s.-]?)?\(\d{2,3}\)[\s.-]?\d{4,5}[\s.-]?\d{4}\b|\+\d{1,3}(?:[\s.-]?\d{2,5}){2,4}\b`
+	ssnPattern   = `\b\d{3}[- ]\d{2}[- ]\d{4}\b`
+	ibanPattern  = `\b[A-Z]{2}\d{2}(?:[ ]?[A-Z0-9]{4}){2,7}(?:[ ]?[A-Z0-9]{1,3})?\b`
+	cardPattern  = `\b(?:\d[ -]?){12,18}\d\b`
+)
+
+// patternKinds names the built-in patterns in PII annotations. Other
+// patterns are reported as "pattern".
+var patternKinds = map[string]string{
+	cpfPattern:   "cpf",
+	cnpjPattern:  "cnpj",
+	emailPattern: "email",
+	hashPattern:  "hash",
+	phonePattern: "phone",
+	ssnPattern:   "ssn",
+	ibanPattern:  "iban",
+	cardPattern:  "card",
+}
+
+// Pattern sets selectable with RedactorOptions.PatternSets.
+const (
+	// PatternSetBRDocuments matches CPF and CNPJ numbers.
+	PatternSetBRDocuments = "br-documents"
+	// PatternSetUSDocuments matches social security numbers written with
+	// dashes or spaces.
+	PatternSetUSDocuments = "us-documents"
+	// PatternSetContactInfo matches email addresses and phone numbers.
+	PatternSetContactInfo = "contact-info"
+	// PatternSetBankAccounts matches IBANs.
+	PatternSetBankAccounts = "bank-accounts"
+	// PatternSetPaymentCards matches runs of 13 to 19 digits, optionally
+	// separated by spaces or dashes. It also matches long IDs and
+	// timestamps, so it is meant to be used with ValidateLuhn.
+	PatternSetPaymentCards = "payment-cards"
+	// PatternSetHashes matches hashes and hex tokens of 32 to 64
+	// characters.
+	PatternSetHashes = "hashes"
+)
+
+// patternSets maps the pattern set names to their patterns.
+var patternSets = map[string][]string{
+	PatternSetBRDocuments:  {cpfPattern, cnpjPattern},
+	PatternSetUSDocuments:  {ssnPattern},
+	PatternSetContactInfo:  {emailPattern, phonePattern},
+	PatternSetBankAccounts: {ibanPattern},
+	PatternSetPaymentCards: {cardPattern},
+	PatternSetHashes:       {hashPattern},
+}
+
+// KeyMatch selects how RedactorOptions.Keys are matched against field
+// names. Matching is always case-insensitive.
+type KeyMatch string
+
+const (
+	// KeyMatchExact matches the whole field name.
+	KeyMatchExact KeyMatch = "exact"
+	// KeyMatchGlob treats keys as globs where * matches any run of
+	// characters and ? a single one, e.g. *token* matches access_token.
+	KeyMatchGlob KeyMatch = "glob"
+	// KeyMatchPrefix matches field names starting with the key.
+	KeyMatchPrefix KeyMatch = "prefix"
+)
+
+// MaxDepthAction selects what happens to values nested deeper than
+// RedactorOptions.MaxDepth.
+type MaxDepthAction string
+
+const (
+	// MaxDepthMarker replaces them with a marker describing what was cut,
+	// e.g. "[MaxDepth: map, 3 items]".
+	MaxDepthMarker MaxDepthAction = "marker"
+	// MaxDepthOmit leaves them out: the map entry, struct field or slice
+	// element holding them is dropped.
+	MaxDepthOmit MaxDepthAction = "omit"
+)
+
+// UnsupportedTypeAction selects how values that cannot be redacted
+// structurally, such as channels and functions, are logged.
+type UnsupportedTypeAction string
+
+const (
+	// UnsupportedTypePlaceholder replaces them with their type in
+	// brackets, e.g. "[chan int]".
+	UnsupportedTypePlaceholder UnsupportedTypeAction = "placeholder"
+	// UnsupportedTypeOmit leaves them out: the map entry, struct field or
+	// slice element holding them is dropped.
+	UnsupportedTypeOmit UnsupportedTypeAction = "omit"
+	// UnsupportedTypeStringify logs them formatted with %v, with Patterns
+	// applied to the result.
+	UnsupportedTypeStringify UnsupportedTypeAction = "stringify"
+)
+
+// RedactorOptions configures a Redactor.
+type RedactorOptions struct {
+	// Keys are field names whose values are always masked, matched
+	// according to KeyMatch.
+	Keys []string
+	// KeyMatch selects how Keys are matched. Defaults to KeyMatchExact.
+	// KeyMasks are always matched exactly.
+	KeyMatch KeyMatch
+	// Patterns are regular expressions masked wherever they appear inside
+	// string values, at any depth and under any key. Numbers are checked
+	// through their decimal form and replaced by a masked string on a match.
+	Patterns []string
+	// PatternSets adds the patterns of built-in sets, such as
+	// PatternSetContactInfo, to Patterns. Patterns listed in both are
+	// compiled once. Unknown names are ignored.
+	PatternSets []string
+	// Mask replaces redacted values. Defaults to "***".
+	Mask string
+	// MaxDepth bounds the recursion into nested values. Defaults to 5.
+	MaxDepth int
+	// MaxDepthAction selects what replaces values beyond MaxDepth.
+	// Defaults to MaxDepthMarker.
+	MaxDepthAction MaxDepthAction
+	// UnsupportedTypeAction selects what replaces channels, functions and
+	// other values that are neither scalars nor containers. Defaults to
+	// UnsupportedTypePlaceholder.
+	UnsupportedTypeAction UnsupportedTypeAction
+	// RevealPrefix and RevealSuffix keep that many leading and trailing
+	// characters of masked strings visible, e.g. "sec***ef". Strings not
+	// longer than RevealPrefix+RevealSuffix are fully masked so nothing
+	// leaks. Non-string values always use Mask.
+	RevealPrefix int
+	RevealSuffix int
+	// ValidateLuhn only masks pattern matches that look like card numbers
+	// (13 to 19 digits) when they pass the Luhn checksum, keeping order IDs
+	// and counters visible. Other matches are masked as usual.
+	ValidateLuhn bool
+	// KeyMasks sets a specific mask per field name, matched
+	// case-insensitively like Keys. Listed keys are redacted even when
+	// absent from Keys; keys only in Keys use Mask.
+	KeyMasks map[string]string
+	// AnnotatePII keeps values that would be masked and appends a marker
+	// instead, e.g. "joao@example.com [PII:email]", so developers see
+	// what production would hide.
+	//
+	// WARNING: it disables all masking, so passwords, tokens and personal
+	// data are logged in clear. Never set it outside development. The
+	// default redactor only sets it when LogOptions.Environment is
+	// "development", and NewLoggerChecked rejects a Redactor with it set
+	// in any other environment.
+	AnnotatePII bool
+	// DropRedactedKeys omits map entries and struct fields whose key
+	// matches Keys or KeyMasks instead of masking them, so not even the
+	// presence of the field is logged. It takes precedence over KeyMasks
+	// and AnnotatePII; values caught by Patterns are still masked in place.
+	DropRedactedKeys bool
+	// DescendRawJSON parses json.RawMessage values and redacts the result
+	// like any other value, instead of logging them as "[JSON]". Malformed
+	// JSON is still logged as "[JSON]".
+	DescendRawJSON bool
+	// MinEntropyBits enables masking string values that look like random
+	// secrets even when no key or pattern matches, e.g. an API key in a
+	// generic value field: strings of at least MinEntropyLength characters,
+	// without whitespace, whose Shannon entropy exceeds MinEntropyBits per
+	// character are masked whole. Random base64 tokens of 32 characters
+	// score around 4.5 bits and hex ones around 3.7, while identifiers
+	// such as order-service-production score lower.
+	// Disabled when zero.
+	MinEntropyBits float64
+	// MinEntropyLength is the shortest string checked by MinEntropyBits.
+	// Defaults to 20.
+	MinEntropyLength int
+	// Paths mask values at specific locations, given as dotted paths from
+	// the redacted value such as user.credentials.password, where [N] or
+	// [*] select slice elements, as in payments[*].cardNumber, and * any
+	// key. Keys are matched case-insensitively and dotted keys, such as
+	// the ones produced by Logger.WithPrefix, count as nested. Matched
+	// values are handled like the values of Keys. Malformed paths are
+	// ignored.
+	Paths []string
+	// AllowKeys are field names, matched exactly and case-insensitively,
+	// whose values are emitted verbatim. The allowlist wins over
+	// everything else: an allowed key is never masked or dropped, even
+	// when it also matches Keys or KeyMasks, and Patterns are not applied
+	// anywhere inside its value.
+	AllowKeys []string
+	// StringerTypes are rendered with their String method instead of
+	// being reflected into, e.g. decimal types from third party libraries
+	// with unexported fields. Each type, or a pointer to it, must
+	// implement fmt.Stringer; other types are ignored. The rendered text
+	// is still matched against the patterns. Types implementing
+	// encoding.TextMarshaler, such as *big.Int, are already rendered as
+	// their text and need not be listed.
+	StringerTypes []reflect.Type
+}
+
+// DefaultRedactorOptions returns the keys and patterns used when no
+// explicit configuration is provided.
+func DefaultRedactorOptions() RedactorOptions {
+	return RedactorOptions{
+		Keys: []string{
+			"password", "passwd", "pass", "pwd",
+			"token", "access_token", "refresh_token",
+			"authorization", "auth", "secret",
+			"apiKey", "api_key", "apikey", "client_secret",
+			"card", "cardNumber", "cvv", "cvc",
+			"ssn", "cpf", "cnpj",
+		},
+		Patterns: []string{cpfPattern, cnpjPattern, emailPattern, hashPattern, phonePattern, ssnPattern, ibanPattern},
+		Mask:     defaultMask,
+		MaxDepth: defaultMaxDepth,
+	}
+}
+
+// Redactor walks arbitrary values and masks sensitive keys and patterns.
+// The input is never modified; maps and structs are rebuilt as
+// map[string]interface{} and slices as []interface{}. Map keys that are not
+// strings are converted the way encoding/json does, through MarshalText
+// when implemented, except that a String method is used before falling
+// back to their default format, so enum keys match Keys by name. Distinct
+// keys converting to the same string keep only one of their values.
+//
+// A Redactor is safe for concurrent use and can be reconfigured while in
+// use, see Reconfigure.
+type Redactor struct {
+	// mu serializes Reconfigure; Redact reads rules without locking.
+	mu    sync.Mutex
+	rules atomic.Pointer[rules]
+}
+
+// Reconfigurable is a redactor whose rules can be inspected and replaced
+// at runtime, e.g. from an admin endpoint, without rebuilding the loggers
+// using it. *Redactor implements it.
+type Reconfigurable interface {
+	Redact(value interface{}) interface{}
+	Options() RedactorOptions
+	Reconfigure(options RedactorOptions)
+}
+
+// rules is an immutable set of compiled redaction rules.
+type rules struct {
+	options     RedactorOptions
+	keyMatchers []*regexp.Regexp
+	keyMasks    map[string]string
+	allowKeys   map[string]bool
+	patterns    []pattern
+	paths       [][]pathSegment
+	stringers   map[reflect.Type]bool
+	// passThrough is set when no rule can mask anything, see NewRedactor.
+	passThrough bool
+
+	// structPlans caches a []structField per reflect.Type. Plans depend on
+	// the key rules, so they are discarded with them on Reconfigure.
+	structPlans sync.Map
+}
+
+// pattern is a compiled value pattern and the kind reported for it.
+type pattern struct {
+	re   *regexp.Regexp
+	kind string
+}
+
+// structField is the cached redaction plan for one exported struct field.
+type structField struct {
+	index  int
+	name   string
+	allow  bool
+	redact bool
+}
+
+// NewRedactor compiles the given options. Invalid patterns are ignored.
+//
+// Options with no keys, key masks, paths, valid patterns or entropy
+// threshold cannot mask anything. When MaxDepth, MaxDepthAction,
+// UnsupportedTypeAction and DescendRawJSON are left unset as well, Redact
+// skips the traversal and returns values as they are, copying a top-level
+// map[string]interface{}. Values are then not converted either: structs,
+// times and other special types reach the output as encoding/json renders
+// them, and cycles are not detected.
+func NewRedactor(options RedactorOptions) *Redactor {
+	r := &Redactor{}
+	r.rules.Store(compile(options))
+	return r
+}
+
+// Options returns a copy of the options currently in effect, with
+// defaults applied.
+func (r *Redactor) Options() RedactorOptions {
+	return r.rules.Load().options.clone()
+}
+
+// Reconfigure compiles options and makes them the rules of every
+// subsequent Redact call, including calls from loggers already using r.
+// Calls in flight finish with the previous rules. Invalid patterns are
+// ignored, as in NewRedactor.
+func (r *Redactor) Reconfigure(options RedactorOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules.Store(compile(options.clone()))
+}
+
+// clone returns a copy of o that shares no slices or maps with it.
+func (o RedactorOptions) clone() RedactorOptions {
+	o.Keys = append([]string(nil), o.Keys...)
+	o.Patterns = append([]string(nil), o.Patterns...)
+	o.PatternSets = append([]string(nil), o.PatternSets...)
+	o.AllowKeys = append([]string(nil), o.AllowKeys...)
+	o.Paths = append([]string(nil), o.Paths...)
+	o.StringerTypes = append([]reflect.Type(nil), o.StringerTypes...)
+	if o.KeyMasks != nil {
+		masks := make(map[string]string, len(o.KeyMasks))
+		for k, v := range o.KeyMasks {
+			masks[k] = v
+		}
+		o.KeyMasks = masks
+	}
+	return o
+}
+
+// compile builds the rules for options.
+func compile(options RedactorOptions) *rules {
+	// Only the zero traversal options allow the pass-through below.
+	defaultShape := options.MaxDepth <= 0 && options.MaxDepthAction == "" &&
+		options.UnsupportedTypeAction == "" && !options.DescendRawJSON
+	if options.Mask == "" {
+		options.Mask = defaultMask
+	}
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = defaultMaxDepth
+	}
+	if options.MinEntropyBits > 0 && options.MinEntropyLength <= 0 {
+		options.MinEntropyLength = defaultMinEntropyLength
+	}
+	if options.RevealPrefix < 0 {
+		options.RevealPrefix = 0
+	}
+	if options.RevealSuffix < 0 {
+		options.RevealSuffix = 0
+	}
+
+	r := &rules{options: options, stringers: stringerSet(options.StringerTypes)}
+	for _, key := range options.Keys {
+		r.keyMatchers = append(r.keyMatchers, regexp.MustCompile(keyExpr(key, options.KeyMatch)))
+	}
+	if len(options.KeyMasks) > 0 {
+		r.keyMasks = make(map[string]string, len(options.KeyMasks))
+		for key, mask := range options.KeyMasks {
+			r.keyMasks[strings.ToLower(key)] = mask
+		}
+	}
+	if len(options.AllowKeys) > 0 {
+		r.allowKeys = make(map[string]bool, len(options.AllowKeys))
+		for _, key := range options.AllowKeys {
+			r.allowKeys[strings.ToLower(key)] = true
+		}
+	}
+	for _, path := range options.Paths {
+		if segments, ok := parsePath(path); ok {
+			r.paths = append(r.paths, segments)
+		}
+	}
+	for _, expr := range expandPatterns(options) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		kind, ok := patternKinds[expr]
+		if !ok {
+			kind = "pattern"
+		}
+		r.patterns = append(r.patterns, pattern{re: re, kind: kind})
+	}
+	r.passThrough = defaultShape && len(r.keyMatchers) == 0 && len(r.keyMasks) == 0 && len(r.paths) == 0 &&
+		len(r.patterns) == 0 && len(r.stringers) == 0 && options.MinEntropyBits <= 0
+	return r
+}
+
+// expandPatterns returns Patterns followed by the patterns of
+// PatternSets, each expression once.
+func expandPatterns(options RedactorOptions) []string {
+	exprs := make([]string, 0, len(options.Patterns))
+	seen := make(map[string]bool, len(options.Patterns))
+	add := func(expr string) {
+		if !seen[expr] {
+			seen[expr] = true
+			exprs = append(exprs, expr)
+		}
+	}
+	for _, expr := range options.Patterns {
+		add(expr)
+	}
+	for _, name := range options.PatternSets {
+		for _, expr := range patternSets[name] {
+			add(expr)
+		}
+	}
+	return exprs
+}
+
+// keyExpr returns the case-insensitive regular expression matching key in
+// the given mode.
+func keyExpr(key string, mode KeyMatch) string {
+	switch mode {
+	case KeyMatchGlob:
+		var b strings.Builder
+		b.WriteString("(?i)^")
+		for _, c := range key {
+			switch c {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(c)))
+			}
+		}
+		b.WriteString("$")
+		return b.String()
+	case KeyMatchPrefix:
+		return "(?i)^" + regexp.QuoteMeta(key)
+	default:
+		return "(?i)^" + regexp.QuoteMeta(key) + "$"
+	}
+}
+
+// DefaultRedactor returns a Redactor built from DefaultRedactorOptions.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactorOptions())
+}
+
+// Summary reports how much data a single Redact call masked.
+type Summary struct {
+	// KeysRedacted counts values masked because their key matched.
+	KeysRedacted int
+	// CharsRedacted counts the characters hidden from string values,
+	// whether masked by key or by pattern.
+	CharsRedacted int
+}
+
+// walk holds the state of a single traversal.
+type walk struct {
+	seen    map[visit]bool
+	summary Summary
+	// path locates the value being redacted. It is only allocated when
+	// Paths are configured.
+	path *pathStack
+}
+
+// visit identifies a map, slice or pointer on the path being redacted.
+// Only ancestors of a value are marked, so a value shared by siblings is
+// redacted once per parent. The type and length tell apart values that
+// share an address without containing each other, such as a struct and
+// its first field or a slice and a shorter slice of it.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// Redact returns a redacted copy of value. value itself is never modified.
+func (r *Redactor) Redact(value interface{}) interface{} {
+	result, _ := r.RedactWithSummary(value)
+	return result
+}
+
+// RedactWithSummary returns a redacted copy of value along with counts of
+// what was masked.
+func (r *Redactor) RedactWithSummary(value interface{}) (result interface{}, summary Summary) {
+	return r.rules.Load().redact(value)
+}
+
+func (r *rules) redact(value interface{}) (result interface{}, summary Summary) {
+	if r.passThrough {
+		return shallowCopy(value), Summary{}
+	}
+	w := &walk{seen: make(map[visit]bool)}
+	if len(r.paths) > 0 {
+		w.path = &pathStack{}
+	}
+	defer func() {
+		if recover() != nil {
+			result, summary = unredactable, w.summary
+		}
+	}()
+	result = r.redactValue(value, 0, w)
+	if result == omitted {
+		result = nil
+	}
+	return result, w.summary
+}
+
+func (r *rules) redactValue(value interface{}, depth int, w *walk) interface{} {
+	if value == nil {
+		return nil
+	}
+	if raw, ok := value.(json.RawMessage); ok && r.options.DescendRawJSON {
+		var parsed interface{}
+		if err := json.Unmarshal(raw, &parsed); err != nil {
+			return "[JSON]"
+		}
+		return r.redactValue(parsed, depth, w)
+	}
+	if special, ok := r.handleSpecialTypes(value, w); ok {
+		return special
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.String:
+		return r.redactString(val.String(), w)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return r.redactNumber(val, w)
+	case reflect.Bool, reflect.Complex64, reflect.Complex128:
+		return value
+	case reflect.Ptr:
+		return r.redactIndirect(val, depth, w)
+	}
+
+	if depth >= r.options.MaxDepth {
+		return r.truncate(val)
+	}
+
+	switch val.Kind() {
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		v := visit{ptr: val.Pointer(), typ: val.Type()}
+		if w.seen[v] {
+			return "[Circular]"
+		}
+		w.seen[v] = true
+		defer delete(w.seen, v)
+		return r.redactMap(val, depth, w)
+	case reflect.Slice:
+		if val.IsNil() {
+			return nil
+		}
+		if val.Len() > 0 {
+			v := visit{ptr: val.Pointer(), typ: val.Type(), len: val.Len()}
+			if w.seen[v] {
+				return "[Circular]"
+			}
+			w.seen[v] = true
+			defer delete(w.seen, v)
+		}
+		return r.redactSlice(val, depth, w)
+	case reflect.Array:
+		return r.redactSlice(val, depth, w)
+	case reflect.Struct:
+		return r.redactStruct(val, depth, w)
+	}
+
+	return r.unsupported(val, w)
+}
+
+// unsupported returns what replaces val, a value of a kind that cannot be
+// redacted structurally.
+func (r *rules) unsupported(val reflect.Value, w *walk) interface{} {
+	switch r.options.UnsupportedTypeAction {
+	case UnsupportedTypeOmit:
+		return omitted
+	case UnsupportedTypeStringify:
+		return r.redactString(fmt.Sprintf("%v", val.Interface()), w)
+	default:
+		return fmt.Sprintf("[%s]", val.Type().String())
+	}
+}
+
+// unredactable replaces a value whose methods panicked while redacting it.
+const unredactable = "[Unredactable]"
+
+// omittedValue marks a value dropped by MaxDepthOmit; containers skip it.
+type omittedValue struct{}
+
+var omitted interface{} = omittedValue{}
+
+// truncate returns what replaces val, found beyond MaxDepth: omitted, or
+// a marker naming its kind and, for containers, its length.
+func (r *rules) truncate(val reflect.Value) interface{} {
+	if r.options.MaxDepthAction == MaxDepthOmit {
+		return omitted
+	}
+	switch val.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		unit := "items"
+		if val.Len() == 1 {
+			unit = "item"
+		}
+		return fmt.Sprintf("[MaxDepth: %s, %d %s]", val.Kind(), val.Len(), unit)
+	case reflect.Ptr, reflect.Interface:
+		return "[MaxDepth: pointer chain]"
+	default:
+		return fmt.Sprintf("[MaxDepth: %s]", val.Type())
+	}
+}
+
+// redactIndirect follows a chain of pointer and interface indirections in
+// a loop rather than recursively. Indirections do not count as nesting
+// levels, but a chain of more than MaxDepth pointers is cut like a value
+// beyond MaxDepth, so pathological values such as *interface{} wrapping
+// *interface{} cannot make a single traversal unbounded.
+func (r *rules) redactIndirect(val reflect.Value, depth int, w *walk) interface{} {
+	var visited []visit
+	defer func() {
+		for _, v := range visited {
+			delete(w.seen, v)
+		}
+	}()
+
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		if val.Kind() == reflect.Ptr {
+			if len(visited) >= r.options.MaxDepth {
+				return r.truncate(val)
+			}
+			v := visit{ptr: val.Pointer(), typ: val.Type()}
+			if w.seen[v] {
+				return "[Circular]"
+			}
+			w.seen[v] = true
+			visited = append(visited, v)
+		}
+		val = val.Elem()
+	}
+	return r.redactValue(val.Interface(), depth, w)
+}
+
+// shallowCopy returns value, with a top-level map[string]interface{}
+// copied so callers adding keys to the result leave the input alone.
+func shallowCopy(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// handleSpecialTypes renders well-known types that should not be reflected
+// into. Types with a text form, such as uuid.UUID, net.IP or netip.Addr,
+// are logged as that text rather than as their internal bytes or fields,
+// and so are math/big numbers and the StringerTypes. A panic in one of
+// their methods only replaces value with "[Unredactable]".
+func (r *rules) handleSpecialTypes(value interface{}, w *walk) (result interface{}, ok bool) {
+	defer func() {
+		if recover() != nil {
+			result, ok = unredactable, true
+		}
+	}()
+	if s, ok := r.stringerString(value); ok {
+		return r.redactString(s, w), true
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339), true
+	case time.Duration:
+		return v.String(), true
+	case json.RawMessage:
+		return "[JSON]", true
+	case []byte:
+		return "[Buffer]", true
+	case big.Int:
+		return r.redactString(v.String(), w), true
+	case big.Float:
+		return r.redactString(v.Text('g', -1), w), true
+	case big.Rat:
+		return r.redactString(v.RatString(), w), true
+	case error:
+		if val := reflect.ValueOf(v); val.Kind() == reflect.Ptr && val.IsNil() {
+			return nil, true
+		}
+		return r.redactString(v.Error(), w), true
+	case encoding.TextMarshaler:
+		if val := reflect.ValueOf(v); val.Kind() == reflect.Ptr && val.IsNil() {
+			return nil, true
+		}
+		text, err := v.MarshalText()
+		if err != nil {
+			return fmt.Sprintf("[%T]", v), true
+		}
+		return r.redactString(string(text), w), true
+	}
+	return nil, false
+}
+
+func (r *rules) redactMap(val reflect.Value, depth int, w *walk) map[string]interface{} {
+	out := make(map[string]interface{}, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		key := mapKey(iter.Key())
+		if r.isAllowedKey(key) {
+			out[key] = iter.Value().Interface()
+			continue
+		}
+		if v := r.redactMember(key, iter.Value().Interface(), r.shouldRedactKey(key), depth, w); v != omitted {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// redactMember redacts the value of a map entry or struct field, masking
+// or omitting it when its key or path matches.
+func (r *rules) redactMember(key string, value interface{}, redactKey bool, depth int, w *walk) interface{} {
+	mark := w.enterKey(key)
+	defer w.leave(mark)
+	if redactKey || r.matchesPath(w) {
+		if r.options.DropRedactedKeys {
+			w.summary.KeysRedacted++
+			return omitted
+		}
+		return r.maskValue(key, value, w)
+	}
+	return r.redactValue(value, depth+1, w)
+}
+
+// mapKey converts a map key to the string matched against the key rules
+// and used in the rebuilt map.
+func mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	switch {
+	case !key.IsValid():
+		return "<nil>"
+	case key.Kind() == reflect.String:
+		return key.String()
+	case key.Kind() == reflect.Ptr && key.IsNil():
+		return fmt.Sprintf("%v", key.Interface())
+	}
+	switch k := key.Interface().(type) {
+	case encoding.TextMarshaler:
+		if text, err := k.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return k.String()
+	}
+	return fmt.Sprintf("%v", key.Interface())
+}
+
+func (r *rules) redactSlice(val reflect.Value, depth int, w *walk) []interface{} {
+	out := make([]interface{}, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		if v := r.redactElement(i, val.Index(i).Interface(), depth, w); v != omitted {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// redactElement redacts the slice element at index, masking or omitting
+// it when its path matches.
+func (r *rules) redactElement(index int, value interface{}, depth int, w *walk) interface{} {
+	mark := w.enterIndex(index)
+	defer w.leave(mark)
+	if r.matchesPath(w) {
+		if r.options.DropRedactedKeys {
+			w.summary.KeysRedacted++
+			return omitted
+		}
+		return r.maskValue(w.path.lastKey(), value, w)
+	}
+	return r.redactValue(value, depth+1, w)
+}
+
+// redactStruct converts a struct into a map keyed by the field's JSON
+// name, falling back to the Go field name when there is no json tag.
+// Fields tagged json:"-" and unexported fields are skipped.
+func (r *rules) redactStruct(val reflect.Value, depth int, w *walk) map[string]interface{} {
+	plan := r.structPlan(val.Type())
+	out := make(map[string]interface{}, len(plan))
+	for _, field := range plan {
+		value := val.Field(field.index).Interface()
+		if field.allow {
+			out[field.name] = value
+			continue
+		}
+		if v := r.redactMember(field.name, value, field.redact, depth, w); v != omitted {
+			out[field.name] = v
+		}
+	}
+	return out
+}
+
+// structPlan returns the exported fields of typ with their resolved names
+// and key-match decision, reflecting over typ only the first time.
+func (r *rules) structPlan(typ reflect.Type) []structField {
+	if plan, ok := r.structPlans.Load(typ); ok {
+		return plan.([]structField)
+	}
+
+	plan := make([]structField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		plan = append(plan, structField{index: i, name: name, allow: r.isAllowedKey(name), redact: r.shouldRedactKey(name)})
+	}
+
+	actual, _ := r.structPlans.LoadOrStore(typ, plan)
+	return actual.([]structField)
+}
+
+// fieldName resolves the key used for a struct field. It reports false
+// when the field is excluded with json:"-".
+func fieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return field.Name, true
+	}
+	if tag == "-" {
+		return "", false
+	}
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		tag = tag[:idx]
+	}
+	if tag == "" {
+		return field.Name, true
+	}
+	return tag, true
+}
+
+func (r *rules) redactString(s string, w *walk) string {
+	if r.options.MinEntropyBits > 0 && looksLikeSecret(s, r.options.MinEntropyBits, r.options.MinEntropyLength) {
+		if r.options.AnnotatePII {
+			return annotate(s, "entropy")
+		}
+		return r.maskString(s, r.options.Mask, w)
+	}
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if r.options.ValidateLuhn {
+				if digits, ok := cardNumberDigits(match); ok && !luhnValid(digits) {
+					return match
+				}
+			}
+			if r.options.AnnotatePII {
+				return annotate(match, p.kind)
+			}
+			return r.maskString(match, r.options.Mask, w)
+		})
+	}
+	return s
+}
+
+// redactNumber applies the value patterns to the decimal form of a number,
+// so e.g. a CPF or card number stored as an integer is still caught. The
+// number is returned unchanged, keeping its type, when nothing matches.
+func (r *rules) redactNumber(val reflect.Value, w *walk) interface{} {
+	if len(r.patterns) == 0 {
+		return val.Interface()
+	}
+	var s string
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(val.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(val.Float(), 'f', -1, 64)
+	default:
+		s = strconv.FormatUint(val.Uint(), 10)
+	}
+	if redacted := r.redactString(s, w); redacted != s {
+		return redacted
+	}
+	return val.Interface()
+}
+
+// maskValue returns the replacement for the value of a matched key.
+func (r *rules) maskValue(key string, value interface{}, w *walk) string {
+	w.summary.KeysRedacted++
+	if r.options.AnnotatePII {
+		return annotate(fmt.Sprintf("%v", value), key)
+	}
+	mask := r.maskFor(key)
+	if val := reflect.ValueOf(value); val.Kind() == reflect.String {
+		return r.maskString(val.String(), mask, w)
+	}
+	return mask
+}
+
+// annotate marks value as suspected PII of the given kind.
+func annotate(value, kind string) string {
+	return value + " [PII:" + kind + "]"
+}
+
+// maskFor returns the mask configured for key in KeyMasks, or Mask.
+func (r *rules) maskFor(key string) string {
+	for _, k := range keyCandidates(key) {
+		if mask, ok := r.keyMasks[strings.ToLower(k)]; ok {
+			return mask
+		}
+	}
+	return r.options.Mask
+}
+
+// maskString replaces s with mask, revealing the configured prefix and
+// suffix when s is long enough to keep the remainder hidden.
+func (r *rules) maskString(s, mask string, w *walk) string {
+	runes := []rune(s)
+	prefix, suffix := r.options.RevealPrefix, r.options.RevealSuffix
+	if (prefix <= 0 && suffix <= 0) || len(runes) <= prefix+suffix {
+		w.summary.CharsRedacted += len(runes)
+		return mask
+	}
+	w.summary.CharsRedacted += len(runes) - prefix - suffix
+	return string(runes[:prefix]) + mask + string(runes[len(runes)-suffix:])
+}
+
+func (r *rules) shouldRedactKey(key string) bool {
+	if r.isAllowedKey(key) {
+		return false
+	}
+	for _, k := range keyCandidates(key) {
+		if _, ok := r.keyMasks[strings.ToLower(k)]; ok {
+			return true
+		}
+		for _, re := range r.keyMatchers {
+			if re.MatchString(k) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAllowedKey reports whether key, or the last segment of a dotted key,
+// is in AllowKeys.
+func (r *rules) isAllowedKey(key string) bool {
+	if r.allowKeys == nil {
+		return false
+	}
+	for _, k := range keyCandidates(key) {
+		if r.allowKeys[strings.ToLower(k)] {
+			return true
+		}
+	}
+	return false
+}
+
+// keyCandidates returns key and, for dotted keys such as the ones produced
+// by Logger.WithPrefix, its last segment, so "cache.password" is matched by
+// the "password" rule.
+func keyCandidates(key string) []string {
+	if i := strings.LastIndexByte(key, '.'); i >= 0 && i < len(key)-1 {
+		return []string{key, key[i+1:]}
+	}
+	return []string{key}
+}
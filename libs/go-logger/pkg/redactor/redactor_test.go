@@ -0,0 +1,1205 @@
+package redactor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRedactMapKeys(t *testing.T) {
+	r := DefaultRedactor()
+
+	out := r.Redact(map[string]interface{}{
+		"username": "joao",
+		"Password": "hunter2",
+		"nested":   map[string]interface{}{"token": "abc"},
+	}).(map[string]interface{})
+
+	if out["username"] != "joao" {
+		t.Errorf("Expected username to be kept, got %v", out["username"])
+	}
+	if out["Password"] != "***" {
+		t.Errorf("Expected Password to be masked, got %v", out["Password"])
+	}
+	if nested := out["nested"].(map[string]interface{}); nested["token"] != "***" {
+		t.Errorf("Expected nested token to be masked, got %v", nested["token"])
+	}
+}
+
+type credentialKind int
+
+const (
+	credentialUser credentialKind = iota
+	credentialPassword
+)
+
+func (k credentialKind) String() string {
+	return [...]string{"user", "password"}[k]
+}
+
+type headerName string
+
+func TestRedactNonStringMapKeys(t *testing.T) {
+	r := DefaultRedactor()
+
+	byInt := r.Redact(map[int]string{1: "joao@example.com", 2: "plain"}).(map[string]interface{})
+	if byInt["1"] != "***" || byInt["2"] != "plain" {
+		t.Errorf("Expected int keys as strings with patterns applied to values, got %v", byInt)
+	}
+
+	byEnum := r.Redact(map[credentialKind]string{credentialUser: "joao", credentialPassword: "hunter2"}).(map[string]interface{})
+	if byEnum["user"] != "joao" || byEnum["password"] != "***" {
+		t.Errorf("Expected enum keys to match by name, got %v", byEnum)
+	}
+
+	byNamedString := r.Redact(map[headerName]string{"token": "abc"}).(map[string]interface{})
+	if byNamedString["token"] != "***" {
+		t.Errorf("Expected named string keys to match, got %v", byNamedString)
+	}
+
+	byInterface := r.Redact(map[interface{}]interface{}{credentialPassword: "hunter2", nil: "x"}).(map[string]interface{})
+	if byInterface["password"] != "***" || byInterface["<nil>"] != "x" {
+		t.Errorf("Expected interface keys to use their dynamic type, got %v", byInterface)
+	}
+}
+
+// textKey renders differently as text and with %v, to tell which is used.
+type textKey struct{ id int }
+
+func (k textKey) MarshalText() ([]byte, error) { return []byte(fmt.Sprintf("key-%d", k.id)), nil }
+
+func (k textKey) String() string { return "stringer" }
+
+func TestRedactTextMarshalerMapKeys(t *testing.T) {
+	out := DefaultRedactor().Redact(map[textKey]int{{id: 1}: 1}).(map[string]interface{})
+	if out["key-1"] != 1 {
+		t.Errorf("Expected the key rendered with MarshalText, got %v", out)
+	}
+	addr := DefaultRedactor().Redact(map[netip.Addr]string{netip.MustParseAddr("10.0.0.1"): "up"}).(map[string]interface{})
+	if addr["10.0.0.1"] != "up" {
+		t.Errorf("Expected the address as key, got %v", addr)
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	r := DefaultRedactor()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"cpf", "cpf 123.456.789-09 informed", "cpf *** informed"},
+		{"cnpj", "cnpj 12.345.678/0001-90", "cnpj ***"},
+		{"email", "contact joao@example.com now", "contact *** now"},
+		{"hash", "sha d41d8cd98f00b204e9800998ecf8427e", "sha ***"},
+		{"plain", "nothing to hide", "nothing to hide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.input); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactPatternsOnEveryLeaf(t *testing.T) {
+	type contact struct {
+		Channel string
+		Value   interface{}
+	}
+	r := NewRedactor(RedactorOptions{Patterns: []string{cpfPattern}})
+
+	out := r.Redact(map[string]interface{}{
+		"contact":  contact{Channel: "doc", Value: int64(12345678909)},
+		"document": uint64(12345678909),
+		"float":    12345678909.0,
+		"amount":   42,
+		"ratio":    0.5,
+		"active":   true,
+	}).(map[string]interface{})
+
+	if c := out["contact"].(map[string]interface{}); c["Value"] != "***" {
+		t.Errorf("Expected numeric CPF in a struct to be masked, got %v", c["Value"])
+	}
+	if out["document"] != "***" || out["float"] != "***" {
+		t.Errorf("Expected numeric CPFs in a map to be masked, got %v and %v", out["document"], out["float"])
+	}
+	if out["amount"] != 42 || out["ratio"] != 0.5 || out["active"] != true {
+		t.Errorf("Expected unmatched scalars to keep their type, got %v", out)
+	}
+}
+
+func TestRedactNumbersWithoutPatterns(t *testing.T) {
+	r := NewRedactor(RedactorOptions{Keys: []string{"password"}})
+
+	if got := r.Redact(int64(12345678909)); got != int64(12345678909) {
+		t.Errorf("Expected numbers to be untouched without patterns, got %v", got)
+	}
+}
+
+func TestRedactStructUsesJSONTags(t *testing.T) {
+	type credentials struct {
+		User     string `json:"user"`
+		Password string `json:"password,omitempty"`
+		Internal string `json:"-"`
+		Note     string
+		Dash     string `json:"-,"`
+		secret   string
+	}
+
+	r := NewRedactor(RedactorOptions{Keys: []string{"password"}})
+	out := r.Redact(credentials{
+		User:     "joao",
+		Password: "hunter2",
+		Internal: "skip me",
+		Note:     "plain",
+		Dash:     "dash",
+		secret:   "hidden",
+	}).(map[string]interface{})
+
+	want := map[string]interface{}{
+		"user":     "joao",
+		"password": "***",
+		"Note":     "plain",
+		"-":        "dash",
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Expected %v, got %v", want, out)
+	}
+}
+
+func TestRedactStructPointer(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Token string `json:"token"`
+	}
+
+	out := DefaultRedactor().Redact(&user{Name: "ana", Token: "t"}).(map[string]interface{})
+	if out["name"] != "ana" || out["token"] != "***" {
+		t.Errorf("Unexpected output %v", out)
+	}
+}
+
+func TestRedactSlice(t *testing.T) {
+	out := DefaultRedactor().Redact([]interface{}{
+		"joao@example.com",
+		map[string]interface{}{"secret": "s"},
+		42,
+	}).([]interface{})
+
+	if out[0] != "***" {
+		t.Errorf("Expected email to be masked, got %v", out[0])
+	}
+	if out[1].(map[string]interface{})["secret"] != "***" {
+		t.Errorf("Expected secret to be masked, got %v", out[1])
+	}
+	if out[2] != 42 {
+		t.Errorf("Expected 42 to be kept, got %v", out[2])
+	}
+}
+
+func TestRedactCircular(t *testing.T) {
+	type node struct {
+		Name string `json:"name"`
+		Next *node  `json:"next"`
+	}
+	n := &node{Name: "a"}
+	n.Next = n
+
+	out := DefaultRedactor().Redact(n).(map[string]interface{})
+	if out["next"] != "[Circular]" {
+		t.Errorf("Expected [Circular], got %v", out["next"])
+	}
+}
+
+func TestRedactSharedValues(t *testing.T) {
+	type creds struct {
+		User  string `json:"user"`
+		Token string `json:"token"`
+	}
+	sharedMap := map[string]interface{}{"password": "p", "host": "db"}
+	sharedSlice := []interface{}{"joao@example.com", 1}
+	sharedPtr := &creds{User: "ana", Token: "t"}
+
+	out := DefaultRedactor().Redact(map[string]interface{}{
+		"primary":   sharedMap,
+		"replica":   sharedMap,
+		"to":        sharedSlice,
+		"cc":        sharedSlice,
+		"list":      []interface{}{sharedMap, sharedMap},
+		"login":     sharedPtr,
+		"lastLogin": sharedPtr,
+	}).(map[string]interface{})
+
+	for _, key := range []string{"primary", "replica"} {
+		m, ok := out[key].(map[string]interface{})
+		if !ok || m["password"] != "***" || m["host"] != "db" {
+			t.Errorf("Expected %s to be redacted independently, got %v", key, out[key])
+		}
+	}
+	for _, key := range []string{"to", "cc"} {
+		s, ok := out[key].([]interface{})
+		if !ok || s[0] != "***" || s[1] != 1 {
+			t.Errorf("Expected %s to be redacted independently, got %v", key, out[key])
+		}
+	}
+	for i, v := range out["list"].([]interface{}) {
+		m, ok := v.(map[string]interface{})
+		if !ok || m["password"] != "***" {
+			t.Errorf("Expected list[%d] to be redacted independently, got %v", i, v)
+		}
+	}
+	for _, key := range []string{"login", "lastLogin"} {
+		m, ok := out[key].(map[string]interface{})
+		if !ok || m["user"] != "ana" || m["token"] != "***" {
+			t.Errorf("Expected %s to be redacted independently, got %v", key, out[key])
+		}
+	}
+}
+
+func TestRedactSharedAddressIsNotCircular(t *testing.T) {
+	type inner struct {
+		Token string `json:"token"`
+	}
+	type outer struct {
+		Inner inner  `json:"inner"`
+		First *inner `json:"first"`
+		All   []int  `json:"all"`
+		Head  []int  `json:"head"`
+	}
+	o := &outer{Inner: inner{Token: "t"}, All: []int{1, 2}}
+	// First points into o, and Head is a prefix of All: they share an
+	// address with their container or sibling without being cycles.
+	o.First = &o.Inner
+	o.Head = o.All[:1]
+
+	out := DefaultRedactor().Redact(o).(map[string]interface{})
+
+	if first, ok := out["first"].(map[string]interface{}); !ok || first["token"] != "***" {
+		t.Errorf("Expected first to be redacted, got %v", out["first"])
+	}
+	if !reflect.DeepEqual(out["head"], []interface{}{1}) {
+		t.Errorf("Expected head to be [1], got %v", out["head"])
+	}
+}
+
+func TestRedactMaxDepth(t *testing.T) {
+	r := NewRedactor(RedactorOptions{MaxDepth: 2})
+
+	out := r.Redact(map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": 1},
+		},
+	}).(map[string]interface{})
+
+	if got := out["a"].(map[string]interface{})["b"]; got != "[MaxDepth: map, 1 item]" {
+		t.Errorf("Expected [MaxDepth: map, 1 item], got %v", got)
+	}
+}
+
+func TestRedactMaxDepthMarkers(t *testing.T) {
+	type node struct{ Name string }
+	r := NewRedactor(RedactorOptions{MaxDepth: 1})
+
+	out := r.Redact(map[string]interface{}{
+		"list":   []int{1, 2, 3},
+		"node":   node{Name: "n"},
+		"scalar": 7,
+	}).(map[string]interface{})
+
+	if out["list"] != "[MaxDepth: slice, 3 items]" || out["node"] != "[MaxDepth: redactor.node]" {
+		t.Errorf("Expected markers naming what was cut, got %v", out)
+	}
+	if out["scalar"] != 7 {
+		t.Errorf("Expected scalars below MaxDepth to be kept, got %v", out["scalar"])
+	}
+}
+
+func TestRedactMaxDepthOmit(t *testing.T) {
+	r := NewRedactor(RedactorOptions{MaxDepth: 2, MaxDepthAction: MaxDepthOmit})
+
+	out := r.Redact(map[string]interface{}{
+		"a":    map[string]interface{}{"deep": map[string]interface{}{"c": 1}, "flat": 2},
+		"list": []interface{}{[]int{1}, "x"},
+	}).(map[string]interface{})
+
+	a := out["a"].(map[string]interface{})
+	if _, ok := a["deep"]; ok || a["flat"] != 2 {
+		t.Errorf("Expected the deep entry to be dropped, got %v", a)
+	}
+	if list := out["list"].([]interface{}); len(list) != 1 || list[0] != "x" {
+		t.Errorf("Expected the deep element to be dropped, got %v", list)
+	}
+	if got := NewRedactor(RedactorOptions{MaxDepth: 1, MaxDepthAction: MaxDepthOmit}).Redact([]interface{}{[]int{1}}); len(got.([]interface{})) != 0 {
+		t.Errorf("Expected an empty slice, got %v", got)
+	}
+}
+
+type worker struct {
+	Name string      `json:"name"`
+	Jobs chan int    `json:"jobs"`
+	Meta interface{} `json:"meta"`
+}
+
+func TestRedactUnsupportedTypes(t *testing.T) {
+	w := worker{Name: "joao@example.com", Jobs: make(chan int)}
+
+	tests := []struct {
+		action UnsupportedTypeAction
+		check  func(jobs interface{}, ok bool) bool
+	}{
+		{"", func(jobs interface{}, ok bool) bool { return jobs == "[chan int]" }},
+		{UnsupportedTypePlaceholder, func(jobs interface{}, ok bool) bool { return jobs == "[chan int]" }},
+		{UnsupportedTypeOmit, func(jobs interface{}, ok bool) bool { return !ok }},
+		{UnsupportedTypeStringify, func(jobs interface{}, ok bool) bool {
+			s, _ := jobs.(string)
+			return strings.HasPrefix(s, "0x")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			out := NewRedactor(RedactorOptions{Patterns: []string{emailPattern}, UnsupportedTypeAction: tt.action}).Redact(w).(map[string]interface{})
+			jobs, ok := out["jobs"]
+			if !tt.check(jobs, ok) {
+				t.Errorf("Unexpected jobs value %v (present %v)", jobs, ok)
+			}
+			if out["name"] != "***" {
+				t.Errorf("Expected the other fields to be redacted, got %v", out["name"])
+			}
+		})
+	}
+}
+
+func TestRedactInterfaceHoldingSensitiveMap(t *testing.T) {
+	var meta interface{} = map[string]interface{}{"password": "hunter2", "plan": "pro"}
+
+	out := DefaultRedactor().Redact(worker{Meta: meta}).(map[string]interface{})
+
+	inner, ok := out["meta"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected the boxed map to be redacted as a map, got %v", out["meta"])
+	}
+	if inner["password"] != "***" || inner["plan"] != "pro" {
+		t.Errorf("Expected the password inside the interface to be masked, got %v", inner)
+	}
+}
+
+func TestRedactKeyMatchModes(t *testing.T) {
+	fields := map[string]interface{}{
+		"access_token": "a",
+		"tokenType":    "b",
+		"id_token":     "c",
+		"user":         "d",
+	}
+	cases := []struct {
+		mode   KeyMatch
+		keys   []string
+		masked []string
+	}{
+		{KeyMatchExact, []string{"token", "id_token"}, []string{"id_token"}},
+		{"", []string{"TOKEN*"}, nil},
+		{KeyMatchGlob, []string{"*TOKEN*"}, []string{"access_token", "tokenType", "id_token"}},
+		{KeyMatchGlob, []string{"??_token"}, []string{"id_token"}},
+		{KeyMatchPrefix, []string{"token"}, []string{"tokenType"}},
+	}
+	for _, c := range cases {
+		out := NewRedactor(RedactorOptions{Keys: c.keys, KeyMatch: c.mode}).Redact(fields).(map[string]interface{})
+		masked := map[string]bool{}
+		for _, k := range c.masked {
+			masked[k] = true
+		}
+		for k, v := range out {
+			if got := v == defaultMask; got != masked[k] {
+				t.Errorf("Expected %s masked=%v with %q %v, got %v", k, masked[k], c.mode, c.keys, v)
+			}
+		}
+	}
+}
+
+func TestRedactAllowKeys(t *testing.T) {
+	type billing struct {
+		Account string `json:"account"`
+		Card    string `json:"card"`
+	}
+	r := NewRedactor(RedactorOptions{
+		Keys:      []string{"acc*", "card"},
+		KeyMatch:  KeyMatchGlob,
+		KeyMasks:  map[string]string{"Account": "[ACCT]"},
+		Patterns:  []string{`\d{4}-\d{4}`},
+		AllowKeys: []string{"ACCOUNT"},
+	})
+
+	out := r.Redact(map[string]interface{}{
+		"account":         "1234-5678",
+		"billing.account": "acct-1",
+		"accountId":       "x",
+		"billing":         billing{Account: "1234-5678", Card: "4111"},
+	}).(map[string]interface{})
+
+	if out["account"] != "1234-5678" || out["billing.account"] != "acct-1" {
+		t.Errorf("Expected allowed keys verbatim, got %v", out)
+	}
+	if out["accountId"] != defaultMask {
+		t.Errorf("Expected accountId to stay masked, got %v", out["accountId"])
+	}
+	if b := out["billing"].(map[string]interface{}); b["account"] != "1234-5678" || b["card"] != defaultMask {
+		t.Errorf("Expected the allowlist to apply to struct fields, got %v", b)
+	}
+}
+
+func TestRedactPreservesScalarTypes(t *testing.T) {
+	type order struct {
+		ID     int     `json:"id"`
+		Total  float64 `json:"total"`
+		Paid   bool    `json:"paid"`
+		Count  uint16  `json:"count"`
+		Placed time.Time
+	}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	out := DefaultRedactor().Redact(map[string]interface{}{
+		"id":     42,
+		"paid":   true,
+		"total":  19.99,
+		"order":  order{ID: 7, Total: 1.5, Paid: true, Count: 3, Placed: ts},
+		"nested": map[string]interface{}{"ids": []int{1, 2}},
+	}).(map[string]interface{})
+
+	if out["id"] != 42 || out["paid"] != true || out["total"] != 19.99 {
+		t.Errorf("Expected top-level scalars unchanged, got %v", out)
+	}
+	o := out["order"].(map[string]interface{})
+	if o["id"] != 7 || o["total"] != 1.5 || o["paid"] != true || o["count"] != uint16(3) || o["Placed"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Expected struct fields to keep their types, got %#v", o)
+	}
+	if ids := out["nested"].(map[string]interface{})["ids"].([]interface{}); ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("Expected slice elements to keep their types, got %#v", ids)
+	}
+}
+
+func TestRedactTextMarshalers(t *testing.T) {
+	id := uuid.MustParse("0f8fad5b-d9cb-469f-a165-70867728950e")
+	var nilAddr *netip.Addr
+
+	out := DefaultRedactor().Redact(map[string]interface{}{
+		"id":   id,
+		"addr": netip.MustParseAddr("10.0.0.1"),
+		"ip":   net.ParseIP("10.0.0.2"),
+		"nil":  nilAddr,
+	}).(map[string]interface{})
+
+	if out["id"] != id.String() {
+		t.Errorf("Expected the UUID as text, got %#v", out["id"])
+	}
+	if out["addr"] != "10.0.0.1" || out["ip"] != "10.0.0.2" {
+		t.Errorf("Expected addresses as text, got %#v and %#v", out["addr"], out["ip"])
+	}
+	if out["nil"] != nil {
+		t.Errorf("Expected nil, got %#v", out["nil"])
+	}
+}
+
+func TestRedactDescendRawJSON(t *testing.T) {
+	opts := DefaultRedactorOptions()
+	opts.DescendRawJSON = true
+	r := NewRedactor(opts)
+
+	out := r.Redact(map[string]interface{}{
+		"payload": json.RawMessage(`{"event":"paid","amount":10,"card":{"token":"tok_1"},"email":"joao@example.com"}`),
+		"broken":  json.RawMessage(`{"event":`),
+	}).(map[string]interface{})
+
+	payload, ok := out["payload"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected the payload to be parsed, got %v", out["payload"])
+	}
+	if payload["event"] != "paid" || payload["amount"] != 10.0 || payload["card"] != "***" || payload["email"] != "***" {
+		t.Errorf("Expected the payload redacted by key and pattern, got %v", payload)
+	}
+	if out["broken"] != "[JSON]" {
+		t.Errorf("Expected [JSON] for malformed JSON, got %v", out["broken"])
+	}
+}
+
+func TestRedactSpecialTypes(t *testing.T) {
+	r := DefaultRedactor()
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	out := r.Redact(map[string]interface{}{
+		"at":    ts,
+		"raw":   json.RawMessage(`{"a":1}`),
+		"bytes": []byte("data"),
+		"err":   errors.New("user joao@example.com not found"),
+		"ch":    make(chan int),
+	}).(map[string]interface{})
+
+	if out["at"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Expected RFC3339 time, got %v", out["at"])
+	}
+	if out["raw"] != "[JSON]" {
+		t.Errorf("Expected [JSON], got %v", out["raw"])
+	}
+	if out["bytes"] != "[Buffer]" {
+		t.Errorf("Expected [Buffer], got %v", out["bytes"])
+	}
+	if out["err"] != "user *** not found" {
+		t.Errorf("Expected redacted error message, got %v", out["err"])
+	}
+	if out["ch"] != "[chan int]" {
+		t.Errorf("Expected [chan int], got %v", out["ch"])
+	}
+}
+
+func TestRedactDoesNotMutateInput(t *testing.T) {
+	input := map[string]interface{}{"password": "hunter2"}
+
+	DefaultRedactor().Redact(input)
+
+	if input["password"] != "hunter2" {
+		t.Errorf("Expected input to be untouched, got %v", input["password"])
+	}
+}
+
+func TestRedactNeverMutatesNestedInput(t *testing.T) {
+	type account struct {
+		Owner  string            `json:"owner"`
+		Secret string            `json:"secret"`
+		Tags   map[string]string `json:"tags"`
+	}
+	newInput := func() map[string]interface{} {
+		shared := map[string]interface{}{"api_key": "k", "region": "eu"}
+		return map[string]interface{}{
+			"a":        shared,
+			"b":        shared,
+			"password": "hunter2",
+			"items":    []interface{}{map[string]interface{}{"token": "t"}, "joao@example.com"},
+			"account":  &account{Owner: "ana", Secret: "s", Tags: map[string]string{"password": "p"}},
+			"raw":      json.RawMessage(`{"password":"p"}`),
+		}
+	}
+	input, want := newInput(), newInput()
+
+	options := DefaultRedactorOptions()
+	options.DescendRawJSON = true
+	out := NewRedactor(options).Redact(input).(map[string]interface{})
+	out["a"].(map[string]interface{})["region"] = "changed"
+	out["items"].([]interface{})[1] = "changed"
+
+	if !reflect.DeepEqual(input, want) {
+		t.Errorf("Expected input to be untouched, got %v", input)
+	}
+}
+
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+type panickingError struct{}
+
+func (panickingError) Error() string { panic("boom") }
+
+func TestRedactTypedNilAndPanickingErrors(t *testing.T) {
+	out := DefaultRedactor().Redact(map[string]interface{}{
+		"user":  "bob",
+		"err":   (*nilError)(nil),
+		"cause": panickingError{},
+		"ok":    &nilError{msg: "failed"},
+	}).(map[string]interface{})
+
+	if out["user"] != "bob" || out["ok"] != "failed" {
+		t.Errorf("Expected the other fields to survive, got %v", out)
+	}
+	if v, ok := out["err"]; !ok || v != nil {
+		t.Errorf("Expected a typed-nil error to be logged as nil, got %#v", out["err"])
+	}
+	if out["cause"] != "[Unredactable]" {
+		t.Errorf("Expected only the panicking value to be unredactable, got %#v", out["cause"])
+	}
+}
+
+func TestRedactRevealPrefixSuffix(t *testing.T) {
+	r := NewRedactor(RedactorOptions{
+		Keys:         []string{"token", "pin"},
+		Patterns:     []string{`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`},
+		RevealPrefix: 3,
+		RevealSuffix: 2,
+	})
+
+	out := r.Redact(map[string]interface{}{
+		"token":   "secret-token-abcdef",
+		"pin":     12345,
+		"short":   map[string]interface{}{"token": "abcde"},
+		"contact": "mail joao@example.com please",
+	}).(map[string]interface{})
+
+	if out["token"] != "sec***ef" {
+		t.Errorf("Expected 'sec***ef', got %v", out["token"])
+	}
+	if out["pin"] != "***" {
+		t.Errorf("Expected non-string value to be fully masked, got %v", out["pin"])
+	}
+	if got := out["short"].(map[string]interface{})["token"]; got != "***" {
+		t.Errorf("Expected short value to be fully masked, got %v", got)
+	}
+	if out["contact"] != "mail joa***om please" {
+		t.Errorf("Expected pattern match to be partially revealed, got %v", out["contact"])
+	}
+}
+
+func TestRedactWithSummary(t *testing.T) {
+	r := NewRedactor(RedactorOptions{
+		Keys:         []string{"password", "pin"},
+		Patterns:     []string{`\d{3}\.\d{3}\.\d{3}-\d{2}`},
+		RevealPrefix: 2,
+	})
+
+	_, summary := r.RedactWithSummary(map[string]interface{}{
+		"password": "hunter2",
+		"pin":      1234,
+		"note":     "cpf 123.456.789-09",
+	})
+
+	if summary.KeysRedacted != 2 {
+		t.Errorf("Expected 2 keys redacted, got %d", summary.KeysRedacted)
+	}
+	// hunter2 reveals 2 of 7, the CPF reveals 2 of 14.
+	if summary.CharsRedacted != 17 {
+		t.Errorf("Expected 17 chars redacted, got %d", summary.CharsRedacted)
+	}
+}
+
+func TestRedactValidateLuhn(t *testing.T) {
+	card := `\b(?:\d[ -]?){12,18}\d\b`
+
+	tests := []struct {
+		name     string
+		validate bool
+		input    string
+		want     string
+	}{
+		{"valid card", true, "paid with 4111 1111 1111 1111", "paid with ***"},
+		{"invalid card kept", true, "order 1234567890123456", "order 1234567890123456"},
+		{"validation disabled", false, "order 1234567890123456", "order ***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRedactor(RedactorOptions{Patterns: []string{card}, ValidateLuhn: tt.validate})
+			if got := r.Redact(tt.input); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactDottedKeys(t *testing.T) {
+	r := NewRedactor(RedactorOptions{
+		Keys:     []string{"password"},
+		KeyMasks: map[string]string{"ssn": "[SSN]"},
+	})
+
+	out := r.Redact(map[string]interface{}{
+		"cache.password":   "hunter2",
+		"user.profile.ssn": "123-45-6789",
+		"passwords.count":  2,
+		"trailing.":        "kept",
+	}).(map[string]interface{})
+
+	if out["cache.password"] != "***" {
+		t.Errorf("Expected prefixed password to be masked, got %v", out["cache.password"])
+	}
+	if out["user.profile.ssn"] != "[SSN]" {
+		t.Errorf("Expected prefixed ssn to use its mask, got %v", out["user.profile.ssn"])
+	}
+	if out["passwords.count"] != 2 || out["trailing."] != "kept" {
+		t.Errorf("Expected unrelated dotted keys to be kept, got %v", out)
+	}
+}
+
+func TestRedactKeyMasks(t *testing.T) {
+	type person struct {
+		SSN      string `json:"ssn"`
+		Password string `json:"password"`
+	}
+
+	r := NewRedactor(RedactorOptions{
+		Keys:     []string{"password", "ssn"},
+		Mask:     "[REDACTED]",
+		KeyMasks: map[string]string{"SSN": "[SSN]", "email": "[EMAIL]"},
+	})
+
+	out := r.Redact(map[string]interface{}{
+		"email":    "joao@example.com",
+		"password": "hunter2",
+		"person":   person{SSN: "123-45-6789", Password: "secret"},
+	}).(map[string]interface{})
+
+	if out["email"] != "[EMAIL]" {
+		t.Errorf("Expected email to use its own mask, got %v", out["email"])
+	}
+	if out["password"] != "[REDACTED]" {
+		t.Errorf("Expected password to fall back to the global mask, got %v", out["password"])
+	}
+	p := out["person"].(map[string]interface{})
+	if p["ssn"] != "[SSN]" || p["password"] != "[REDACTED]" {
+		t.Errorf("Expected struct fields to honor KeyMasks, got %v", p)
+	}
+}
+
+func TestRedactDropRedactedKeys(t *testing.T) {
+	type person struct {
+		Name string `json:"name"`
+		SSN  string `json:"ssn"`
+	}
+
+	r := NewRedactor(RedactorOptions{
+		Keys:             []string{"password"},
+		Patterns:         []string{emailPattern},
+		KeyMasks:         map[string]string{"ssn": "[SSN]"},
+		DropRedactedKeys: true,
+	})
+
+	out, summary := r.RedactWithSummary(map[string]interface{}{
+		"password": "hunter2",
+		"contact":  "joao@example.com",
+		"person":   person{Name: "Joao", SSN: "123-45-6789"},
+	})
+	m := out.(map[string]interface{})
+
+	if _, ok := m["password"]; ok {
+		t.Errorf("Expected password to be dropped, got %v", m)
+	}
+	p := m["person"].(map[string]interface{})
+	if _, ok := p["ssn"]; ok || p["name"] != "Joao" {
+		t.Errorf("Expected ssn to be dropped despite its KeyMask, got %v", p)
+	}
+	if m["contact"] != "***" {
+		t.Errorf("Expected pattern matches to stay masked in place, got %v", m["contact"])
+	}
+	if summary.KeysRedacted != 2 {
+		t.Errorf("Expected dropped keys to be counted, got %d", summary.KeysRedacted)
+	}
+
+	annotating := NewRedactor(RedactorOptions{Keys: []string{"password"}, AnnotatePII: true, DropRedactedKeys: true})
+	if m := annotating.Redact(map[string]interface{}{"password": "x"}).(map[string]interface{}); len(m) != 0 {
+		t.Errorf("Expected dropping to take precedence over AnnotatePII, got %v", m)
+	}
+}
+
+func TestRedactStructPlanIsCached(t *testing.T) {
+	type account struct {
+		ID       int    `json:"id"`
+		Password string `json:"password"`
+		hidden   string
+	}
+
+	r := DefaultRedactor()
+	for i := 0; i < 2; i++ {
+		out := r.Redact(account{ID: i, Password: "p", hidden: "h"}).(map[string]interface{})
+		if out["id"] != i || out["password"] != "***" || len(out) != 2 {
+			t.Errorf("Unexpected output on call %d: %v", i, out)
+		}
+	}
+
+	plan, ok := r.rules.Load().structPlans.Load(reflect.TypeOf(account{}))
+	if !ok {
+		t.Fatal("Expected the struct plan to be cached")
+	}
+	want := []structField{{index: 0, name: "id"}, {index: 1, name: "password", redact: true}}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("Expected plan %v, got %v", want, plan)
+	}
+}
+
+type benchUser struct {
+	ID       int               `json:"id"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Password string            `json:"password"`
+	Tags     []string          `json:"tags"`
+	Meta     map[string]string `json:"meta"`
+}
+
+func BenchmarkRedactStruct(b *testing.B) {
+	r := DefaultRedactor()
+	user := benchUser{
+		ID:       1,
+		Name:     "Joao",
+		Email:    "joao@example.com",
+		Password: "hunter2",
+		Tags:     []string{"admin", "beta"},
+		Meta:     map[string]string{"plan": "pro"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Redact(user)
+	}
+}
+
+func TestRedactAnnotatePII(t *testing.T) {
+	opts := DefaultRedactorOptions()
+	opts.AnnotatePII = true
+	opts.Patterns = append(opts.Patterns, `\bACC-\d+\b`)
+	r := NewRedactor(opts)
+
+	out := r.Redact(map[string]interface{}{
+		"contact":  "write to joao@example.com",
+		"password": "hunter2",
+		"cvv":      123,
+		"account":  "ACC-991",
+	}).(map[string]interface{})
+
+	want := map[string]interface{}{
+		"contact":  "write to joao@example.com [PII:email]",
+		"password": "hunter2 [PII:password]",
+		"cvv":      "123 [PII:cvv]",
+		"account":  "ACC-991 [PII:pattern]",
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Expected %v, got %v", want, out)
+	}
+}
+
+// interfaceChain returns n nested *interface{} indirections around leaf.
+func interfaceChain(leaf interface{}, n int) interface{} {
+	v := leaf
+	for i := 0; i < n; i++ {
+		inner := v
+		v = &inner
+	}
+	return v
+}
+
+func TestRedactBoundsIndirectionChains(t *testing.T) {
+	tests := []struct {
+		name   string
+		action MaxDepthAction
+		length int
+		cut    bool
+	}{
+		{"chain within MaxDepth", MaxDepthMarker, 5, false},
+		{"chain one past MaxDepth", MaxDepthMarker, 6, true},
+		{"very long chain", MaxDepthMarker, 200000, true},
+		{"very long chain omitted", MaxDepthOmit, 200000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRedactor(RedactorOptions{Keys: []string{"password"}, MaxDepth: 5, MaxDepthAction: tt.action})
+
+			out := r.Redact(map[string]interface{}{
+				"chain": interfaceChain(map[string]interface{}{"password": "x"}, tt.length),
+			}).(map[string]interface{})
+
+			got, ok := out["chain"]
+			switch {
+			case !tt.cut:
+				if inner, _ := got.(map[string]interface{}); inner["password"] != "***" {
+					t.Errorf("Expected the chain to be unwrapped and redacted, got %v", got)
+				}
+			case tt.action == MaxDepthOmit:
+				if ok {
+					t.Errorf("Expected the chain to be omitted, got %v", got)
+				}
+			default:
+				if got != "[MaxDepth: pointer chain]" {
+					t.Errorf("Expected [MaxDepth: pointer chain], got %v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactNestedInterfacesHitMaxDepth(t *testing.T) {
+	r := NewRedactor(RedactorOptions{MaxDepth: 3})
+
+	var nested interface{} = "leaf"
+	for i := 0; i < 1000; i++ {
+		nested = []interface{}{interfaceChain(nested, 1)}
+	}
+
+	out := r.Redact(nested)
+	for level := 0; level < 3; level++ {
+		slice, ok := out.([]interface{})
+		if !ok {
+			t.Fatalf("Expected slice at level %d, got %v", level, out)
+		}
+		out = slice[0]
+	}
+	if out != "[MaxDepth: slice, 1 item]" {
+		t.Errorf("Expected [MaxDepth: slice, 1 item] at level 3, got %v", out)
+	}
+}
+
+func BenchmarkRedactPathologicalChain(b *testing.B) {
+	r := DefaultRedactor()
+	value := map[string]interface{}{"payload": interfaceChain(map[string]interface{}{"a": 1}, 10000)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Redact(value)
+	}
+}
+
+func TestRedactEmptyOptionsPassThrough(t *testing.T) {
+	r := NewRedactor(RedactorOptions{})
+	nested := map[string]interface{}{"password": "hunter2"}
+	placed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	value := map[string]interface{}{"user": nested, "placed": placed}
+
+	out := r.Redact(value).(map[string]interface{})
+	out["extra"] = true
+
+	if _, ok := value["extra"]; ok {
+		t.Error("Expected the top-level map to be copied")
+	}
+	if reflect.ValueOf(out["user"]).Pointer() != reflect.ValueOf(nested).Pointer() || out["placed"] != placed {
+		t.Errorf("Expected nested values to be returned as they are, got %v", out)
+	}
+
+	r.Reconfigure(RedactorOptions{Keys: []string{"password"}})
+	if got := r.Redact(value).(map[string]interface{})["user"].(map[string]interface{}); got["password"] != "***" {
+		t.Errorf("Expected reconfigured keys to be redacted, got %v", got)
+	}
+}
+
+func benchmarkRedactNoRules(b *testing.B, options RedactorOptions) {
+	r := NewRedactor(options)
+	value := map[string]interface{}{
+		"orderId": "42",
+		"user":    benchUser{ID: 1, Name: "Joao", Tags: []string{"admin"}, Meta: map[string]string{"plan": "pro"}},
+		"items":   []interface{}{map[string]interface{}{"sku": "A1", "qty": 2}},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Redact(value)
+	}
+}
+
+// BenchmarkRedactEmptyOptions takes the pass-through path;
+// BenchmarkRedactNoRulesTraversal sets MaxDepth to force the full
+// traversal with the same lack of rules.
+func BenchmarkRedactEmptyOptions(b *testing.B) {
+	benchmarkRedactNoRules(b, RedactorOptions{})
+}
+
+func BenchmarkRedactNoRulesTraversal(b *testing.B) {
+	benchmarkRedactNoRules(b, RedactorOptions{MaxDepth: defaultMaxDepth})
+}
+
+func TestNopRedactor(t *testing.T) {
+	value := map[string]interface{}{"password": "hunter2"}
+	if got := NopRedactor().Redact(value); !reflect.DeepEqual(got, value) {
+		t.Errorf("Expected the value unchanged, got %v", got)
+	}
+}
+
+func TestRedactorReconfigure(t *testing.T) {
+	type session struct {
+		SessionID string `json:"sessionId"`
+	}
+	r := DefaultRedactor()
+	if out := r.Redact(session{SessionID: "s-1"}).(map[string]interface{}); out["sessionId"] != "s-1" {
+		t.Fatalf("Expected sessionId to be visible before reconfiguring, got %v", out)
+	}
+
+	opts := r.Options()
+	opts.Keys = append(opts.Keys, "sessionId")
+	r.Reconfigure(opts)
+
+	if out := r.Redact(session{SessionID: "s-1"}).(map[string]interface{}); out["sessionId"] != "***" {
+		t.Errorf("Expected sessionId to be masked after reconfiguring, got %v", out)
+	}
+	if out := r.Redact(map[string]interface{}{"password": "p"}).(map[string]interface{}); out["password"] != "***" {
+		t.Errorf("Expected the previous keys to be kept, got %v", out)
+	}
+}
+
+func TestRedactorOptionsIsACopy(t *testing.T) {
+	r := NewRedactor(RedactorOptions{Keys: []string{"password"}})
+
+	opts := r.Options()
+	opts.Keys[0] = "email"
+
+	if got := r.Options().Keys[0]; got != "password" {
+		t.Errorf("Expected the redactor options to be unchanged, got %q", got)
+	}
+	if got := r.Options().Mask; got != "***" {
+		t.Errorf("Expected defaults to be applied, got mask %q", got)
+	}
+}
+
+func TestRedactorReconfigureConcurrently(t *testing.T) {
+	r := DefaultRedactor()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Redact(map[string]interface{}{"password": "p", "email": "joao@example.com"})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Reconfigure(DefaultRedactorOptions())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRedactPaths(t *testing.T) {
+	type credentials struct {
+		Password string `json:"password"`
+	}
+	type user struct {
+		Credentials credentials `json:"credentials"`
+	}
+	r := NewRedactor(RedactorOptions{Paths: []string{
+		"user.credentials.password",
+		"payments[*].cardNumber",
+		"users[0].token",
+		"cache.*.secret",
+		"invalid[",
+	}})
+
+	out := r.Redact(map[string]interface{}{
+		"user":     user{Credentials: credentials{Password: "hunter2"}},
+		"password": "top-level",
+		"payments": []map[string]interface{}{
+			{"cardNumber": "4111", "amount": 10},
+			{"cardNumber": "5500", "amount": 20},
+		},
+		"users":       []map[string]interface{}{{"token": "a"}, {"token": "b"}},
+		"cache.redis": map[string]interface{}{"secret": "s", "host": "h"},
+	}).(map[string]interface{})
+
+	if got := out["user"].(map[string]interface{})["credentials"].(map[string]interface{})["password"]; got != "***" {
+		t.Errorf("Expected user.credentials.password to be masked, got %v", got)
+	}
+	if out["password"] != "top-level" {
+		t.Errorf("Expected password outside the paths to be kept, got %v", out["password"])
+	}
+	for i, p := range out["payments"].([]interface{}) {
+		payment := p.(map[string]interface{})
+		if payment["cardNumber"] != "***" || payment["amount"] == "***" {
+			t.Errorf("Expected only the card number of payment %d to be masked, got %v", i, payment)
+		}
+	}
+	users := out["users"].([]interface{})
+	if users[0].(map[string]interface{})["token"] != "***" || users[1].(map[string]interface{})["token"] != "b" {
+		t.Errorf("Expected only the token of the first user to be masked, got %v", users)
+	}
+	if cache := out["cache.redis"].(map[string]interface{}); cache["secret"] != "***" || cache["host"] != "h" {
+		t.Errorf("Expected dotted keys to be matched like nested ones, got %v", cache)
+	}
+}
+
+func TestRedactPathsSliceElements(t *testing.T) {
+	r := NewRedactor(RedactorOptions{Paths: []string{"tokens[*]"}})
+
+	out := r.Redact(map[string]interface{}{"tokens": []string{"a", "b"}, "ids": []string{"c"}}).(map[string]interface{})
+
+	if tokens := out["tokens"].([]interface{}); tokens[0] != "***" || tokens[1] != "***" {
+		t.Errorf("Expected every token to be masked, got %v", tokens)
+	}
+	if ids := out["ids"].([]interface{}); ids[0] != "c" {
+		t.Errorf("Expected other slices to be kept, got %v", ids)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []pathSegment
+		ok   bool
+	}{
+		{"a.b", []pathSegment{{key: "a"}, {key: "b"}}, true},
+		{"a[*].b", []pathSegment{{key: "a"}, {isIndex: true, index: -1}, {key: "b"}}, true},
+		{"[2][*]", []pathSegment{{isIndex: true, index: 2}, {isIndex: true, index: -1}}, true},
+		{"a..b", nil, false},
+		{"a[x]", nil, false},
+		{"a[1", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parsePath(tt.path)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePath(%q) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRedactDefaultInternationalPatterns(t *testing.T) {
+	r := DefaultRedactor()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"call +55 11 91234-5678", "call ***"},
+		{"call (11) 91234-5678", "call ***"},
+		{"call +1 415-555-0132", "call ***"},
+		{"ssn 123-45-6789", "ssn ***"},
+		{"ssn 123 45 6789", "ssn ***"},
+		{"iban DE89 3704 0044 0532 0130 00", "iban ***"},
+		{"iban GB82WEST12345698765432", "iban ***"},
+		{"order 1700000000123", "order 1700000000123"},
+		{"at 2024-05-01T12:00:00+03:00", "at 2024-05-01T12:00:00+03:00"},
+	}
+
+	for _, tt := range tests {
+		if got := r.Redact(tt.input); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRedactPatternSets(t *testing.T) {
+	r := NewRedactor(RedactorOptions{PatternSets: []string{PatternSetPaymentCards, PatternSetContactInfo, "unknown"}, ValidateLuhn: true})
+
+	if got := r.Redact("card 4111 1111 1111 1111"); got != "card ***" {
+		t.Errorf("Expected the card number to be masked, got %q", got)
+	}
+	if got := r.Redact("order 1700000000123"); got != "order 1700000000123" {
+		t.Errorf("Expected a number failing Luhn to be kept, got %q", got)
+	}
+	if got := r.Redact("joao@example.com"); got != "***" {
+		t.Errorf("Expected the email to be masked, got %q", got)
+	}
+	if got := r.Redact("cpf 123.456.789-09"); got != "cpf 123.456.789-09" {
+		t.Errorf("Expected sets not selected to be skipped, got %q", got)
+	}
+}
+
+func TestRedactPatternSetsCompileOnce(t *testing.T) {
+	r := NewRedactor(RedactorOptions{
+		Patterns:    []string{emailPattern},
+		PatternSets: []string{PatternSetContactInfo, PatternSetContactInfo},
+	})
+
+	if got := len(r.rules.Load().patterns); got != 2 {
+		t.Errorf("Expected email and phone patterns once each, got %d", got)
+	}
+}
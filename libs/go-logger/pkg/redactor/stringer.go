@@ -0,0 +1,47 @@
+package redactor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// stringerSet returns the StringerTypes that, or whose pointer, implement
+// fmt.Stringer, or nil when there are none. Other types are ignored, like
+// invalid patterns.
+func stringerSet(types []reflect.Type) map[reflect.Type]bool {
+	var set map[reflect.Type]bool
+	for _, t := range types {
+		if t == nil || (!t.Implements(stringerType) && !reflect.PointerTo(t).Implements(stringerType)) {
+			continue
+		}
+		if set == nil {
+			set = make(map[reflect.Type]bool, len(types))
+		}
+		set[t] = true
+	}
+	return set
+}
+
+// stringerString returns the String of value when its type is one of the
+// StringerTypes.
+func (r *rules) stringerString(value interface{}) (string, bool) {
+	if r.stringers == nil {
+		return "", false
+	}
+	t := reflect.TypeOf(value)
+	if !r.stringers[t] {
+		return "", false
+	}
+	if s, ok := value.(fmt.Stringer); ok {
+		if val := reflect.ValueOf(value); val.Kind() == reflect.Ptr && val.IsNil() {
+			return "<nil>", true
+		}
+		return s.String(), true
+	}
+	// String has a pointer receiver: call it on an addressable copy.
+	ptr := reflect.New(t)
+	ptr.Elem().Set(reflect.ValueOf(value))
+	return ptr.Interface().(fmt.Stringer).String(), true
+}
@@ -0,0 +1,86 @@
+package redactor
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// decimal mimics decimal types such as shopspring's Decimal: unexported
+// fields and a String method with a pointer receiver.
+type decimal struct {
+	value *big.Int
+	exp   int32
+}
+
+func (d *decimal) String() string {
+	s := d.value.String()
+	point := len(s) + int(d.exp)
+	return s[:point] + "." + s[point:]
+}
+
+func TestRedactStringerTypes(t *testing.T) {
+	price := decimal{value: big.NewInt(1999), exp: -2}
+
+	if out := DefaultRedactor().Redact(map[string]interface{}{"price": price}).(map[string]interface{}); out["price"] == "19.99" {
+		t.Fatalf("Expected the unlisted decimal to be reflected into, got %#v", out["price"])
+	}
+
+	options := DefaultRedactorOptions()
+	options.StringerTypes = []reflect.Type{reflect.TypeOf(decimal{}), reflect.TypeOf(&decimal{})}
+	out := NewRedactor(options).Redact(map[string]interface{}{
+		"price": price,
+		"total": &decimal{value: big.NewInt(4250), exp: -2},
+		"order": struct{ Amount decimal }{price},
+	}).(map[string]interface{})
+	if out["price"] != "19.99" || out["total"] != "42.50" {
+		t.Errorf("Expected decimals rendered with String, got %#v and %#v", out["price"], out["total"])
+	}
+	if order := out["order"].(map[string]interface{}); order["Amount"] != "19.99" {
+		t.Errorf("Expected a decimal struct field rendered with String, got %#v", order["Amount"])
+	}
+}
+
+func TestRedactStringerTypesWithoutOtherRules(t *testing.T) {
+	r := NewRedactor(RedactorOptions{StringerTypes: []reflect.Type{reflect.TypeOf(decimal{})}})
+
+	out := r.Redact(map[string]interface{}{"price": decimal{value: big.NewInt(500), exp: -2}}).(map[string]interface{})
+	if out["price"] != "5.00" {
+		t.Errorf("Expected the decimal rendered with String, got %#v", out["price"])
+	}
+}
+
+func TestRedactStringerTypesIgnoresNonStringers(t *testing.T) {
+	type point struct{ X int }
+	r := NewRedactor(RedactorOptions{StringerTypes: []reflect.Type{reflect.TypeOf(point{}), nil}, Keys: []string{"secret"}})
+
+	out := r.Redact(map[string]interface{}{"p": point{X: 1}}).(map[string]interface{})
+	if p, ok := out["p"].(map[string]interface{}); !ok || p["X"] != 1 {
+		t.Errorf("Expected a type without String to be reflected into, got %#v", out["p"])
+	}
+}
+
+func TestRedactBigNumbers(t *testing.T) {
+	amount, _ := new(big.Float).SetString("1234.5678")
+
+	out := DefaultRedactor().Redact(map[string]interface{}{
+		"int":      *big.NewInt(98765),
+		"intPtr":   big.NewInt(-42),
+		"float":    *amount,
+		"floatPtr": amount,
+		"rat":      *big.NewRat(1, 3),
+	}).(map[string]interface{})
+
+	want := map[string]interface{}{
+		"int":      "98765",
+		"intPtr":   "-42",
+		"float":    "1234.5678",
+		"floatPtr": "1234.5678",
+		"rat":      "1/3",
+	}
+	for k, v := range want {
+		if out[k] != v {
+			t.Errorf("Expected %s=%v, got %#v", k, v, out[k])
+		}
+	}
+}
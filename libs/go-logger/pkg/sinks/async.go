@@ -0,0 +1,224 @@
+// Package sinks provides types.Sink implementations and combinators.
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	defaultBufferSize    = 1000
+	defaultFlushInterval = 5 * time.Second
+	// maxKeptFailures caps the write errors kept for DrainAndWait, so a
+	// sink whose backend stays down without ever being drained does not
+	// grow without bound. Further failures are only counted.
+	maxKeptFailures = 10
+)
+
+// ErrSinkClosed is returned when writing to a closed sink.
+var ErrSinkClosed = errors.New("sink is closed")
+
+// BatchWriter is implemented by sinks that write several entries at once
+// more efficiently than one by one, such as network sinks. AsyncSink hands
+// them each flushed batch in a single call. The slice is reused after the
+// call returns and must not be retained.
+type BatchWriter interface {
+	WriteBatch(entries []types.LogEntry) error
+}
+
+// AsyncSink buffers entries on a channel and writes them to the wrapped
+// sink from a background goroutine, flushing every FlushInterval or
+// whenever BufferSize entries are pending.
+type AsyncSink struct {
+	inner   types.Sink
+	options types.SinkOptions
+	entries chan types.LogEntry
+	drains  chan chan error
+	done    chan struct{}
+	stopped chan struct{}
+	dropped uint64
+
+	// failures collects the first write errors since the last drain and
+	// failed counts all of them. Only the background goroutine touches
+	// them.
+	failures []error
+	failed   int
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncSink starts an AsyncSink around inner. A zero BufferSize or
+// FlushInterval uses 1000 entries and 5 seconds.
+func NewAsyncSink(inner types.Sink, options types.SinkOptions) *AsyncSink {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+
+	s := &AsyncSink{
+		inner:   inner,
+		options: options,
+		entries: make(chan types.LogEntry, options.BufferSize),
+		drains:  make(chan chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write enqueues entry. When the buffer is full it blocks if
+// EnableBackpressure is set, otherwise it drops the oldest pending entry.
+func (s *AsyncSink) Write(entry types.LogEntry) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrSinkClosed
+	}
+
+	if s.options.EnableBackpressure {
+		s.entries <- entry
+		return nil
+	}
+	for {
+		select {
+		case s.entries <- entry:
+			return nil
+		default:
+		}
+		select {
+		case <-s.entries:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+	}
+}
+
+// Dropped returns how many entries were discarded because the buffer was full.
+func (s *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// DrainAndWait flushes every entry written before the call and blocks
+// until the wrapped sink has received them or ctx is done. It returns the
+// joined errors of the writes that failed since the previous drain, the
+// first ten of them followed by the count of the others, which makes it
+// suitable for deterministic test teardown.
+func (s *AsyncSink) DrainAndWait(ctx context.Context) error {
+	reply := make(chan error, 1)
+	select {
+	case s.drains <- reply:
+	case <-s.stopped:
+		return ErrSinkClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close drains the buffer, stops the background goroutine and closes the
+// wrapped sink.
+func (s *AsyncSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	<-s.stopped
+	return s.inner.Close()
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.options.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]types.LogEntry, 0, s.options.BufferSize)
+	for {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+			if len(batch) >= s.options.BufferSize {
+				batch = s.flush(batch)
+			}
+		case <-ticker.C:
+			batch = s.flush(batch)
+		case reply := <-s.drains:
+			batch = s.flush(s.drainPending(batch))
+			reply <- s.takeFailures()
+		case <-s.done:
+			s.flush(s.drainPending(batch))
+			return
+		}
+	}
+}
+
+// drainPending moves every entry currently buffered in the channel into batch.
+func (s *AsyncSink) drainPending(batch []types.LogEntry) []types.LogEntry {
+	for {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+}
+
+// flush writes batch to the wrapped sink and returns it emptied for reuse.
+func (s *AsyncSink) flush(batch []types.LogEntry) []types.LogEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+	if bw, ok := s.inner.(BatchWriter); ok {
+		if err := bw.WriteBatch(batch); err != nil {
+			s.recordFailure(err)
+		}
+		return batch[:0]
+	}
+	for _, entry := range batch {
+		if err := s.inner.Write(entry); err != nil {
+			s.recordFailure(err)
+		}
+	}
+	return batch[:0]
+}
+
+// recordFailure counts err, keeping it if fewer than maxKeptFailures were.
+func (s *AsyncSink) recordFailure(err error) {
+	s.failed++
+	if len(s.failures) < maxKeptFailures {
+		s.failures = append(s.failures, err)
+	}
+}
+
+// takeFailures returns the joined failures since the last drain, noting
+// how many more were not kept, and resets them.
+func (s *AsyncSink) takeFailures() error {
+	errs := s.failures
+	if more := s.failed - len(errs); more > 0 {
+		errs = append(errs, fmt.Errorf("and %d more write errors", more))
+	}
+	s.failures, s.failed = nil, 0
+	return errors.Join(errs...)
+}
@@ -0,0 +1,241 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func asyncOptions(backpressure bool, size int, interval time.Duration) types.SinkOptions {
+	return types.SinkOptions{EnableBackpressure: backpressure, BufferSize: size, FlushInterval: interval}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Condition not met before deadline")
+}
+
+func TestAsyncSinkFlushesOnInterval(t *testing.T) {
+	inner := &mockSink{}
+	s := NewAsyncSink(inner, asyncOptions(false, 100, 10*time.Millisecond))
+	defer s.Close()
+
+	_ = s.Write(entryWithMethod("a"))
+
+	waitFor(t, func() bool { return len(inner.Entries()) == 1 })
+}
+
+func TestAsyncSinkFlushesWhenBufferFills(t *testing.T) {
+	inner := &mockSink{}
+	s := NewAsyncSink(inner, asyncOptions(false, 2, time.Hour))
+	defer s.Close()
+
+	_ = s.Write(entryWithMethod("a"))
+	_ = s.Write(entryWithMethod("b"))
+
+	waitFor(t, func() bool { return len(inner.Entries()) == 2 })
+}
+
+func TestAsyncSinkUsesBatchWriter(t *testing.T) {
+	inner := &batchSink{}
+	s := NewAsyncSink(inner, asyncOptions(true, 3, time.Hour))
+	defer s.Close()
+
+	for _, m := range []string{"a", "b", "c", "d"} {
+		_ = s.Write(entryWithMethod(m))
+	}
+	if err := s.DrainAndWait(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	batches := inner.Batches()
+	if len(batches) != 2 || !reflect.DeepEqual(methods(batches[0]), []string{"a", "b", "c"}) || !reflect.DeepEqual(methods(batches[1]), []string{"d"}) {
+		t.Errorf("Expected batches [a b c] and [d], got %v", batches)
+	}
+	if len(inner.Entries()) != 0 {
+		t.Error("Expected Write not to be used for a BatchWriter")
+	}
+}
+
+func TestAsyncSinkDropsOldestWithoutBackpressure(t *testing.T) {
+	inner := newBlockingSink()
+	s := NewAsyncSink(inner, asyncOptions(false, 2, time.Hour))
+
+	_ = s.Write(entryWithMethod("e1"))
+	_ = s.Write(entryWithMethod("e2"))
+	<-inner.started // the worker is now blocked flushing e1 and e2
+
+	_ = s.Write(entryWithMethod("e3"))
+	_ = s.Write(entryWithMethod("e4"))
+	_ = s.Write(entryWithMethod("e5"))
+
+	if got := s.Dropped(); got != 1 {
+		t.Errorf("Expected 1 dropped entry, got %d", got)
+	}
+
+	close(inner.release)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"e1", "e2", "e4", "e5"}
+	if got := methods(inner.Entries()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestAsyncSinkBlocksWithBackpressure(t *testing.T) {
+	inner := newBlockingSink()
+	s := NewAsyncSink(inner, asyncOptions(true, 1, time.Hour))
+
+	_ = s.Write(entryWithMethod("e1"))
+	<-inner.started
+	_ = s.Write(entryWithMethod("e2"))
+
+	written := make(chan struct{})
+	go func() {
+		_ = s.Write(entryWithMethod("e3"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("Expected Write to block while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.release)
+	<-written
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := methods(inner.Entries()); len(got) != 3 || s.Dropped() != 0 {
+		t.Errorf("Expected all 3 entries and no drops, got %v (dropped %d)", got, s.Dropped())
+	}
+}
+
+func TestAsyncSinkCloseDrains(t *testing.T) {
+	inner := &mockSink{}
+	s := NewAsyncSink(inner, asyncOptions(false, 100, time.Hour))
+
+	for i := 0; i < 10; i++ {
+		_ = s.Write(entryWithMethod("m"))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(inner.Entries()); got != 10 {
+		t.Errorf("Expected 10 entries after Close, got %d", got)
+	}
+	if !inner.Closed() {
+		t.Error("Expected inner sink to be closed")
+	}
+	if err := s.Write(entryWithMethod("late")); err != ErrSinkClosed {
+		t.Errorf("Expected ErrSinkClosed, got %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Expected second Close to be a no-op, got %v", err)
+	}
+}
+
+func TestAsyncSinkDrainAndWait(t *testing.T) {
+	inner := &mockSink{}
+	s := NewAsyncSink(inner, asyncOptions(false, 1000, time.Hour))
+	defer s.Close()
+
+	const n = 250
+	for i := 0; i < n; i++ {
+		_ = s.Write(entryWithMethod("m"))
+	}
+
+	if err := s.DrainAndWait(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := len(inner.Entries()); got != n {
+		t.Errorf("Expected exactly %d entries, got %d", n, got)
+	}
+}
+
+func TestAsyncSinkDrainAndWaitReportsFailures(t *testing.T) {
+	inner := &mockSink{writeErr: errors.New("backend down")}
+	s := NewAsyncSink(inner, asyncOptions(false, 10, time.Hour))
+	defer s.Close()
+
+	_ = s.Write(entryWithMethod("m"))
+
+	err := s.DrainAndWait(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "backend down") {
+		t.Errorf("Expected write failure, got %v", err)
+	}
+
+	inner.mu.Lock()
+	inner.writeErr = nil
+	inner.mu.Unlock()
+	_ = s.Write(entryWithMethod("m"))
+	if err := s.DrainAndWait(context.Background()); err != nil {
+		t.Errorf("Expected failures to reset after a drain, got %v", err)
+	}
+}
+
+func TestAsyncSinkCapsKeptFailures(t *testing.T) {
+	inner := &mockSink{writeErr: errors.New("backend down")}
+	s := NewAsyncSink(inner, asyncOptions(true, 1, time.Hour))
+
+	for i := 0; i < 25; i++ {
+		_ = s.Write(entryWithMethod("m"))
+	}
+	err := s.DrainAndWait(context.Background())
+	if err == nil || strings.Count(err.Error(), "backend down") != maxKeptFailures || !strings.Contains(err.Error(), "and 15 more write errors") {
+		t.Errorf("Expected %d failures and a count of the others, got %v", maxKeptFailures, err)
+	}
+
+	// Without a drain, failures must not pile up.
+	for i := 0; i < 1000; i++ {
+		_ = s.Write(entryWithMethod("m"))
+	}
+	_ = s.Close()
+	if len(s.failures) != maxKeptFailures || s.failed != 1000 {
+		t.Errorf("Expected %d kept failures out of 1000, got %d of %d", maxKeptFailures, len(s.failures), s.failed)
+	}
+}
+
+func TestAsyncSinkDrainAndWaitHonorsContext(t *testing.T) {
+	inner := newBlockingSink()
+	s := NewAsyncSink(inner, asyncOptions(false, 1, time.Hour))
+	defer func() {
+		close(inner.release)
+		s.Close()
+	}()
+
+	_ = s.Write(entryWithMethod("m"))
+	<-inner.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.DrainAndWait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestAsyncSinkDrainAndWaitAfterClose(t *testing.T) {
+	s := NewAsyncSink(&mockSink{}, asyncOptions(false, 1, time.Hour))
+	s.Close()
+
+	if err := s.DrainAndWait(context.Background()); err != ErrSinkClosed {
+		t.Errorf("Expected ErrSinkClosed, got %v", err)
+	}
+}
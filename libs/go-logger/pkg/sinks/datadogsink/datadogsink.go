@@ -0,0 +1,300 @@
+// Package datadogsink ships log entries to the Datadog HTTP log intake,
+// with the trace and span IDs Datadog uses to link logs to APM traces.
+package datadogsink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/sinks"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	// DefaultEndpoint is the log intake of the US1 Datadog site.
+	DefaultEndpoint = "https://http-intake.logs.datadoghq.com/api/v2/logs"
+
+	defaultSource       = "go"
+	defaultBatchSize    = 100
+	maxBatchSize        = 1000 // entries accepted by the intake per request
+	defaultMaxWait      = time.Second
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 500 * time.Millisecond
+)
+
+// Options configures a DatadogSink.
+type Options struct {
+	// APIKey authenticates the requests.
+	APIKey string
+	// Endpoint is the intake URL of the Datadog site. Defaults to
+	// DefaultEndpoint.
+	Endpoint string
+	// Service, Env and Version are used when an entry lacks the service,
+	// environment and version fields set from types.LogOptions. Env and
+	// Version also become the env and version tags.
+	Service string
+	Env     string
+	Version string
+	// Tags are added to the ddtags of every entry, e.g. "team:payments".
+	Tags []string
+	// Source is sent as ddsource. Defaults to "go".
+	Source string
+	// Hostname is sent as hostname when set.
+	Hostname string
+	// BatchSize is the number of entries posted per request, at most
+	// 1000, and MaxWait the longest an entry waits before being posted.
+	// They default to 100 entries and one second.
+	BatchSize int
+	MaxWait   time.Duration
+	// EnableBackpressure makes writers block while a batch is being posted
+	// and the buffer is full, instead of dropping the oldest entry.
+	EnableBackpressure bool
+	// MaxRetries is how many times a request failing with a 5xx, a 429 or
+	// a network error is retried, waiting RetryBackoff and then twice as
+	// long before each new attempt. They default to 3 and 500ms; a
+	// negative MaxRetries disables retries.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// DatadogSink posts entries to the Datadog log intake. Entries are batched
+// by a sinks.AsyncSink. Levels are mapped to Datadog statuses, and the
+// trace_id and span_id fields set by WithContext are sent as dd.trace_id
+// and dd.span_id in the decimal form Datadog expects.
+type DatadogSink struct {
+	*sinks.AsyncSink
+}
+
+// NewDatadogSink starts a DatadogSink. Close flushes the pending batch.
+func NewDatadogSink(options Options) *DatadogSink {
+	if options.Endpoint == "" {
+		options.Endpoint = DefaultEndpoint
+	}
+	if options.Source == "" {
+		options.Source = defaultSource
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	} else if options.BatchSize > maxBatchSize {
+		options.BatchSize = maxBatchSize
+	}
+	if options.MaxWait <= 0 {
+		options.MaxWait = defaultMaxWait
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	} else if options.MaxRetries == 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = defaultRetryBackoff
+	}
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	return &DatadogSink{AsyncSink: sinks.NewAsyncSink(&client{options: options}, types.SinkOptions{
+		EnableBackpressure: options.EnableBackpressure,
+		BufferSize:         options.BatchSize,
+		FlushInterval:      options.MaxWait,
+	})}
+}
+
+// client posts batches to the intake.
+type client struct {
+	options Options
+}
+
+func (c *client) Write(entry types.LogEntry) error {
+	return c.WriteBatch([]types.LogEntry{entry})
+}
+
+func (c *client) WriteBatch(entries []types.LogEntry) error {
+	logs := make([]json.RawMessage, len(entries))
+	for i, entry := range entries {
+		logs[i] = c.encodeJSON(entry)
+	}
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return fmt.Errorf("datadog: encoding entries: %w", err)
+	}
+
+	backoff := c.options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.post(body)
+		if err == nil || !retry || attempt >= c.options.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (c *client) Close() error { return nil }
+
+// encodeJSON returns the intake representation of entry as JSON. An entry
+// whose fields cannot be encoded, e.g. because one holds a channel, is
+// sent without them, with an encodingError attribute, so it does not fail
+// the rest of the batch.
+func (c *client) encodeJSON(entry types.LogEntry) json.RawMessage {
+	line, err := json.Marshal(c.encode(entry))
+	if err == nil {
+		return line
+	}
+	entry.Fields = nil
+	log := c.encode(entry)
+	log["encodingError"] = err.Error()
+	line, _ = json.Marshal(log)
+	return line
+}
+
+// encode returns the intake representation of entry: its fields as
+// attributes next to the reserved Datadog attributes.
+func (c *client) encode(entry types.LogEntry) map[string]interface{} {
+	log := make(map[string]interface{}, len(entry.Fields)+10)
+	for k, v := range entry.Fields {
+		log[k] = v
+	}
+	delete(log, "trace_id")
+	delete(log, "span_id")
+
+	service := stringField(entry, "service", c.options.Service)
+	env := stringField(entry, "environment", c.options.Env)
+	version := stringField(entry, "version", c.options.Version)
+	log["message"] = entry.Message
+	log["status"] = status(entry.Level)
+	log["timestamp"] = entry.Timestamp.UnixMilli()
+	log["ddsource"] = c.options.Source
+	log["ddtags"] = c.tags(env, version)
+	if service != "" {
+		log["service"] = service
+	}
+	if c.options.Hostname != "" {
+		log["hostname"] = c.options.Hostname
+	}
+	if entry.CorrelationID != "" {
+		log["correlationId"] = entry.CorrelationID
+	}
+	traceID, spanID := datadogIDs(entry.Fields["trace_id"], entry.Fields["span_id"])
+	if traceID != "" {
+		log["dd.trace_id"] = traceID
+	}
+	if spanID != "" {
+		log["dd.span_id"] = spanID
+	}
+	if entry.Error != nil {
+		log["error.kind"] = entry.Error.Name
+		log["error.message"] = entry.Error.Message
+		if entry.Error.Stack != "" {
+			log["error.stack"] = entry.Error.Stack
+		}
+	}
+	return log
+}
+
+// tags returns the ddtags of an entry: the configured tags followed by
+// env and version when known.
+func (c *client) tags(env, version string) string {
+	tags := append([]string(nil), c.options.Tags...)
+	if env != "" {
+		tags = append(tags, "env:"+env)
+	}
+	if version != "" {
+		tags = append(tags, "version:"+version)
+	}
+	return strings.Join(tags, ",")
+}
+
+// post sends one request, reporting whether a failure is worth retrying.
+func (c *client) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.options.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("datadog: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", c.options.APIKey)
+
+	resp, err := c.options.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("datadog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("datadog: intake failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+// status maps a level to a Datadog log status.
+func status(level types.LogLevel) string {
+	switch level {
+	case types.TraceLevel, types.DebugLevel:
+		return "debug"
+	case types.WarnLevel:
+		return "warning"
+	case types.ErrorLevel:
+		return "error"
+	case types.FatalLevel:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// stringField returns the string field key of entry, or fallback.
+func stringField(entry types.LogEntry, key, fallback string) string {
+	if v, ok := entry.Fields[key].(string); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// datadogIDs converts W3C trace and span IDs, in hex, to the unsigned
+// decimal Datadog correlates on: the low 64 bits for 128-bit trace IDs.
+// A 16-digit decimal ID is also valid hex, so IDs are only converted when
+// the trace ID is a 32-character W3C one; the span ID then comes from the
+// same W3C context. Other IDs, such as Datadog's own decimal ones, are
+// kept as they are.
+func datadogIDs(trace, span interface{}) (traceID, spanID string) {
+	traceID, _ = trace.(string)
+	spanID, _ = span.(string)
+	if !isHex(traceID, 32) {
+		return traceID, spanID
+	}
+	traceID = hexToDecimal(traceID[16:])
+	if isHex(spanID, 16) {
+		spanID = hexToDecimal(spanID)
+	}
+	return traceID, spanID
+}
+
+// isHex reports whether s is made of n hex digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := strconv.ParseUint(s[:n/2], 16, 64)
+	if err != nil {
+		return false
+	}
+	_, err = strconv.ParseUint(s[n/2:], 16, 64)
+	return err == nil
+}
+
+// hexToDecimal converts 16 hex digits to an unsigned decimal.
+func hexToDecimal(s string) string {
+	n, _ := strconv.ParseUint(s, 16, 64)
+	return strconv.FormatUint(n, 10)
+}
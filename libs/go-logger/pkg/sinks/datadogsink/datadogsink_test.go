@@ -0,0 +1,202 @@
+package datadogsink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// intakeServer records the posted batches and answers with the queued
+// statuses, then 202.
+type intakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	batches  [][]map[string]interface{}
+	statuses []int
+}
+
+func newIntakeServer(t *testing.T, statuses ...int) *intakeServer {
+	s := &intakeServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != "key" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		var batch []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.batches = append(s.batches, batch)
+		status := http.StatusAccepted
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *intakeServer) Batches() [][]map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]map[string]interface{}(nil), s.batches...)
+}
+
+func TestDatadogSinkPostsEntries(t *testing.T) {
+	server := newIntakeServer(t)
+	s := NewDatadogSink(Options{
+		APIKey:   "key",
+		Endpoint: server.URL,
+		Service:  "fallback",
+		Env:      "prod",
+		Tags:     []string{"team:payments"},
+		MaxWait:  time.Hour,
+	})
+
+	_ = s.Write(types.LogEntry{
+		Timestamp:     time.UnixMilli(1700000000123),
+		Level:         types.WarnLevel,
+		Message:       "slow order",
+		CorrelationID: "cid-1",
+		Fields: map[string]interface{}{
+			"service":  "orders",
+			"version":  "1.2.0",
+			"orderId":  "42",
+			"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":  "00f067aa0ba902b7",
+		},
+		Error: &types.LogError{Name: "*errors.errorString", Message: "timeout"},
+	})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	batches := server.Batches()
+	if len(batches) != 1 || len(batches[0]) != 1 {
+		t.Fatalf("Expected Close to flush a single entry, got %v", batches)
+	}
+	log := batches[0][0]
+	want := map[string]interface{}{
+		"message":       "slow order",
+		"status":        "warning",
+		"service":       "orders",
+		"ddsource":      "go",
+		"ddtags":        "team:payments,env:prod,version:1.2.0",
+		"timestamp":     float64(1700000000123),
+		"correlationId": "cid-1",
+		"orderId":       "42",
+		"dd.trace_id":   "11803532876627986230",
+		"dd.span_id":    "67667974448284343",
+		"error.kind":    "*errors.errorString",
+		"error.message": "timeout",
+	}
+	for k, v := range want {
+		if log[k] != v {
+			t.Errorf("Expected %s=%v, got %v", k, v, log[k])
+		}
+	}
+	if _, ok := log["trace_id"]; ok {
+		t.Error("Expected trace_id to be replaced by dd.trace_id")
+	}
+}
+
+func TestDatadogSinkReplacesUnencodableEntries(t *testing.T) {
+	server := newIntakeServer(t)
+	s := NewDatadogSink(Options{APIKey: "key", Endpoint: server.URL, Service: "orders", MaxWait: time.Hour})
+
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "first"})
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "bad", Fields: map[string]interface{}{"ch": make(chan int)}})
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "last"})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	batches := server.Batches()
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("Expected the 3 entries in a single batch, got %v", batches)
+	}
+	bad := batches[0][1]
+	if bad["message"] != "bad" || bad["service"] != "orders" || bad["encodingError"] == nil {
+		t.Errorf("Expected the bad entry replaced with an encoding error, got %v", bad)
+	}
+	if _, ok := bad["ch"]; ok {
+		t.Errorf("Expected the fields of the bad entry to be dropped, got %v", bad)
+	}
+}
+
+func TestDatadogSinkFallsBackToOptions(t *testing.T) {
+	c := &client{options: Options{Service: "orders", Version: "2.0.0", Source: "go"}}
+
+	log := c.encode(types.LogEntry{Level: types.FatalLevel})
+
+	if log["service"] != "orders" || log["ddtags"] != "version:2.0.0" || log["status"] != "critical" {
+		t.Errorf("Expected service, tags and status from the options, got %v", log)
+	}
+}
+
+func TestDatadogSinkRetriesServerErrors(t *testing.T) {
+	server := newIntakeServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	s := NewDatadogSink(Options{APIKey: "key", Endpoint: server.URL, MaxWait: time.Hour, RetryBackoff: time.Millisecond})
+	defer s.Close()
+
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "hi"})
+
+	if err := s.DrainAndWait(context.Background()); err != nil {
+		t.Fatalf("Expected the request to succeed after retries, got %v", err)
+	}
+	if got := len(server.Batches()); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestDatadogSinkDoesNotRetryClientErrors(t *testing.T) {
+	server := newIntakeServer(t, http.StatusForbidden)
+	s := NewDatadogSink(Options{APIKey: "key", Endpoint: server.URL, MaxWait: time.Hour, RetryBackoff: time.Millisecond})
+	defer s.Close()
+
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "hi"})
+
+	err := s.DrainAndWait(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("Expected a 403 failure, got %v", err)
+	}
+	if got := len(server.Batches()); got != 1 {
+		t.Errorf("Expected a single attempt, got %d", got)
+	}
+}
+
+func TestDatadogIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		trace, span interface{}
+		traceID     string
+		spanID      string
+	}{
+		{"none", nil, nil, "", ""},
+		{"w3c", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", "11803532876627986230", "67667974448284343"},
+		{"w3c trace only", "4bf92f3577b34da6a3ce929d0e0e4736", nil, "11803532876627986230", ""},
+		{"decimal", "1234567890123456", "6543210987654321", "1234567890123456", "6543210987654321"},
+		{"short decimal", "1234567890", "42", "1234567890", "42"},
+		{"hex span without w3c trace", nil, "00f067aa0ba902b7", "", "00f067aa0ba902b7"},
+		{"malformed trace", "4bf92f3577b34da6a3ce929d0e0e473z", "00f067aa0ba902b7", "4bf92f3577b34da6a3ce929d0e0e473z", "00f067aa0ba902b7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID := datadogIDs(tt.trace, tt.span)
+			if traceID != tt.traceID || spanID != tt.spanID {
+				t.Errorf("Expected %q and %q, got %q and %q", tt.traceID, tt.spanID, traceID, spanID)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package kafkasink produces log entries to a Kafka topic. It lives apart
+// from package sinks so that only applications importing it depend on the
+// Kafka client.
+package kafkasink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/sinks"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultLinger      = 100 * time.Millisecond
+	defaultMaxAttempts = 10
+)
+
+// Options configures a KafkaSink.
+type Options struct {
+	// Brokers lists the addresses of the bootstrap brokers.
+	Brokers []string
+	// Topic receives the entries.
+	Topic string
+	// BatchSize is the number of entries sent in one request. Defaults to
+	// 100.
+	BatchSize int
+	// Linger bounds how long an incomplete batch waits for more entries
+	// before being sent. Defaults to 100ms.
+	Linger time.Duration
+	// MaxAttempts bounds the attempts to deliver a batch, retries
+	// included. Defaults to 10.
+	MaxAttempts int
+	// Marshal encodes the entries. Defaults to encoding/json.
+	Marshal sinks.MarshalFunc
+	// Transport overrides the client transport, e.g. to configure TLS or
+	// SASL. Defaults to kafka.DefaultTransport.
+	Transport kafka.RoundTripper
+}
+
+// producer is the part of *kafka.Writer used by KafkaSink.
+type producer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaSink produces every entry as a JSON message keyed by its
+// correlation ID, so the lines of one request land in the same partition
+// and stay ordered. Entries without a correlation ID are spread over the
+// partitions. Messages are batched and sent in the background; delivery
+// failures, once retries are exhausted, are reported on stderr.
+type KafkaSink struct {
+	producer producer
+	marshal  sinks.MarshalFunc
+}
+
+// NewKafkaSink returns a KafkaSink producing to options.Topic.
+func NewKafkaSink(options Options) *KafkaSink {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.Linger <= 0 {
+		options.Linger = defaultLinger
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(options.Brokers...),
+		Topic:        options.Topic,
+		Balancer:     &kafka.Hash{},
+		BatchSize:    options.BatchSize,
+		BatchTimeout: options.Linger,
+		MaxAttempts:  options.MaxAttempts,
+		Transport:    options.Transport,
+		Async:        true,
+		Completion:   reportFailure,
+	}
+	return newKafkaSink(w, options.Marshal)
+}
+
+func newKafkaSink(p producer, marshal sinks.MarshalFunc) *KafkaSink {
+	if marshal == nil {
+		marshal = func(entry types.LogEntry) ([]byte, error) { return json.Marshal(entry) }
+	}
+	return &KafkaSink{producer: p, marshal: marshal}
+}
+
+// reportFailure is called by the writer once a batch was delivered or
+// given up on.
+func reportFailure(messages []kafka.Message, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %d entries to kafka, %v\n", len(messages), err)
+	}
+}
+
+// Write queues entry for delivery. Only encoding errors are returned.
+func (s *KafkaSink) Write(entry types.LogEntry) error {
+	value, err := s.marshal(entry)
+	if err != nil {
+		return err
+	}
+	msg := kafka.Message{Value: value, Time: entry.Timestamp}
+	if entry.CorrelationID != "" {
+		msg.Key = []byte(entry.CorrelationID)
+	}
+	return s.producer.WriteMessages(context.Background(), msg)
+}
+
+// Close sends the queued entries, waiting for their delivery, and closes
+// the producer.
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
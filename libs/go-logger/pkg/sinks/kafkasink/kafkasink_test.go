@@ -0,0 +1,63 @@
+package kafkasink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockProducer struct {
+	messages []kafka.Message
+	closed   bool
+}
+
+func (p *mockProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.messages = append(p.messages, msgs...)
+	return nil
+}
+
+func (p *mockProducer) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestKafkaSinkKeysByCorrelationID(t *testing.T) {
+	p := &mockProducer{}
+	sink := newKafkaSink(p, nil)
+
+	sink.Write(types.LogEntry{Level: types.InfoLevel, CorrelationID: "cid-1", Message: "hello"})
+	sink.Write(types.LogEntry{Level: types.WarnLevel})
+
+	if len(p.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(p.messages))
+	}
+	if string(p.messages[0].Key) != "cid-1" || p.messages[1].Key != nil {
+		t.Errorf("Expected only the first message to be keyed, got %q and %q", p.messages[0].Key, p.messages[1].Key)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(p.messages[0].Value, &decoded); err != nil || decoded["correlationId"] != "cid-1" {
+		t.Errorf("Expected a JSON entry, got %s (%v)", p.messages[0].Value, err)
+	}
+}
+
+func TestKafkaSinkCloseClosesProducer(t *testing.T) {
+	p := &mockProducer{}
+	if err := newKafkaSink(p, nil).Close(); err != nil || !p.closed {
+		t.Errorf("Expected the producer to be closed, got %v", err)
+	}
+}
+
+func TestNewKafkaSinkDefaults(t *testing.T) {
+	sink := NewKafkaSink(Options{Brokers: []string{"localhost:9092"}, Topic: "logs"})
+	w := sink.producer.(*kafka.Writer)
+	if w.Topic != "logs" || w.BatchSize != defaultBatchSize || w.BatchTimeout != defaultLinger || w.MaxAttempts != defaultMaxAttempts || !w.Async {
+		t.Errorf("Expected an async writer with the defaults, got %+v", w)
+	}
+	if _, ok := w.Balancer.(*kafka.Hash); !ok {
+		t.Errorf("Expected a key hash balancer, got %T", w.Balancer)
+	}
+}
@@ -0,0 +1,221 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	lokiPushPath            = "/loki/api/v1/push"
+	defaultLokiBatchSize    = 100
+	defaultLokiMaxWait      = time.Second
+	defaultLokiMaxRetries   = 3
+	defaultLokiRetryBackoff = 500 * time.Millisecond
+)
+
+// lokiLabelFields are the entry fields promoted to stream labels.
+var lokiLabelFields = []string{"service", "environment", "version"}
+
+// LokiOptions configures a LokiSink.
+type LokiOptions struct {
+	// URL is the base URL of Loki, e.g. http://loki:3100.
+	URL string
+	// Labels are added to every stream, next to level and the service,
+	// environment and version fields of the entries.
+	Labels map[string]string
+	// BatchSize is the number of entries pushed per request and MaxWait
+	// the longest an entry waits before being pushed. They default to 100
+	// entries and one second.
+	BatchSize int
+	MaxWait   time.Duration
+	// EnableBackpressure makes writers block while a batch is being pushed
+	// and the buffer is full, instead of dropping the oldest entry.
+	EnableBackpressure bool
+	// MaxRetries is how many times a push failing with a 5xx, a 429 or a
+	// network error is retried, waiting RetryBackoff and then twice as
+	// long before each new attempt. They default to 3 and 500ms; a
+	// negative MaxRetries disables retries.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// LokiSink pushes entries to Grafana Loki. Entries are batched by an
+// AsyncSink and grouped into streams labelled with the level and the
+// service, environment and version fields. The correlation ID travels as
+// structured metadata, which needs Loki 2.9 or later, so it does not
+// create a stream per request.
+type LokiSink struct {
+	*AsyncSink
+}
+
+// NewLokiSink starts a LokiSink. Close flushes the pending batch.
+func NewLokiSink(options LokiOptions) *LokiSink {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultLokiBatchSize
+	}
+	if options.MaxWait <= 0 {
+		options.MaxWait = defaultLokiMaxWait
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	} else if options.MaxRetries == 0 {
+		options.MaxRetries = defaultLokiMaxRetries
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = defaultLokiRetryBackoff
+	}
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	client := &lokiClient{options: options, url: strings.TrimRight(options.URL, "/") + lokiPushPath}
+	return &LokiSink{AsyncSink: NewAsyncSink(client, types.SinkOptions{
+		EnableBackpressure: options.EnableBackpressure,
+		BufferSize:         options.BatchSize,
+		FlushInterval:      options.MaxWait,
+	})}
+}
+
+// lokiClient pushes batches to the Loki HTTP API.
+type lokiClient struct {
+	options LokiOptions
+	url     string
+}
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][]interface{}   `json:"values"`
+}
+
+func (c *lokiClient) Write(entry types.LogEntry) error {
+	return c.WriteBatch([]types.LogEntry{entry})
+}
+
+func (c *lokiClient) WriteBatch(entries []types.LogEntry) error {
+	body, err := c.encode(entries)
+	if err != nil {
+		return err
+	}
+
+	backoff := c.options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.push(body)
+		if err == nil || !retry || attempt >= c.options.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (c *lokiClient) Close() error { return nil }
+
+// encodeEntry returns entry as JSON. An entry that cannot be encoded, e.g.
+// because a field holds a channel, is replaced by a copy without its
+// fields, arguments and result, with an encodingError field, so it does
+// not fail the rest of the batch.
+func encodeEntry(entry types.LogEntry) []byte {
+	line, err := json.Marshal(entry)
+	if err == nil {
+		return line
+	}
+	entry.Args, entry.Result = nil, nil
+	entry.Fields = map[string]interface{}{"encodingError": err.Error()}
+	line, _ = json.Marshal(entry)
+	return line
+}
+
+// encode groups entries into streams by label set, keeping their order.
+func (c *lokiClient) encode(entries []types.LogEntry) ([]byte, error) {
+	var (
+		streams []lokiStream
+		index   = map[string]int{}
+	)
+	for _, entry := range entries {
+		line := encodeEntry(entry)
+		value := []interface{}{strconv.FormatInt(entry.Timestamp.UnixNano(), 10), string(line)}
+		if entry.CorrelationID != "" {
+			value = append(value, map[string]string{"correlationId": entry.CorrelationID})
+		}
+
+		labels := c.labels(entry)
+		key := labelKey(labels)
+		i, ok := index[key]
+		if !ok {
+			i = len(streams)
+			index[key] = i
+			streams = append(streams, lokiStream{Stream: labels})
+		}
+		streams[i].Values = append(streams[i].Values, value)
+	}
+	return json.Marshal(lokiPush{Streams: streams})
+}
+
+func (c *lokiClient) labels(entry types.LogEntry) map[string]string {
+	labels := make(map[string]string, len(c.options.Labels)+len(lokiLabelFields)+1)
+	for k, v := range c.options.Labels {
+		labels[k] = v
+	}
+	labels["level"] = string(entry.Level)
+	for _, field := range lokiLabelFields {
+		if v, ok := entry.Fields[field].(string); ok && v != "" {
+			labels[field] = v
+		}
+	}
+	return labels
+}
+
+// labelKey returns a canonical representation of labels.
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// push sends one request, reporting whether a failure is worth retrying.
+func (c *lokiClient) push(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("loki: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.options.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("loki: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("loki: push failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
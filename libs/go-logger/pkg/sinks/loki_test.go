@@ -0,0 +1,197 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// lokiServer records push requests and answers with the queued statuses,
+// then 204.
+type lokiServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	pushes   []lokiPush
+	statuses []int
+}
+
+func newLokiServer(t *testing.T, statuses ...int) *lokiServer {
+	s := &lokiServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var push lokiPush
+		if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+			t.Errorf("Invalid push body: %v", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.pushes = append(s.pushes, push)
+		status := http.StatusNoContent
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *lokiServer) Pushes() []lokiPush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]lokiPush(nil), s.pushes...)
+}
+
+func lokiEntry(level types.LogLevel, service, correlationID string) types.LogEntry {
+	return types.LogEntry{
+		Timestamp:     time.Unix(1700000000, 42),
+		Level:         level,
+		CorrelationID: correlationID,
+		Message:       "hi",
+		Fields:        map[string]interface{}{"service": service, "environment": "prod"},
+	}
+}
+
+func TestLokiSinkPushesStreams(t *testing.T) {
+	server := newLokiServer(t)
+	s := NewLokiSink(LokiOptions{URL: server.URL + "/", Labels: map[string]string{"app": "boyscout"}, BatchSize: 10, MaxWait: time.Hour, EnableBackpressure: true})
+
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", "cid-1"))
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+	_ = s.Write(lokiEntry(types.ErrorLevel, "orders", "cid-2"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pushes := server.Pushes()
+	if len(pushes) != 1 {
+		t.Fatalf("Expected Close to flush a single push, got %d", len(pushes))
+	}
+	streams := pushes[0].Streams
+	if len(streams) != 2 {
+		t.Fatalf("Expected 2 streams, got %d", len(streams))
+	}
+	info := streams[0]
+	want := map[string]string{"app": "boyscout", "level": "info", "service": "orders", "environment": "prod"}
+	for k, v := range want {
+		if info.Stream[k] != v {
+			t.Errorf("Expected label %s=%s, got %v", k, v, info.Stream)
+		}
+	}
+	if len(info.Values) != 2 {
+		t.Fatalf("Expected 2 values in the info stream, got %d", len(info.Values))
+	}
+	first := info.Values[0]
+	if first[0] != "1700000000000000042" {
+		t.Errorf("Expected nanosecond timestamp, got %v", first[0])
+	}
+	if line, _ := first[1].(string); !strings.Contains(line, `"message":"hi"`) {
+		t.Errorf("Expected JSON entry as the line, got %v", first[1])
+	}
+	if len(first) != 3 || first[2].(map[string]interface{})["correlationId"] != "cid-1" {
+		t.Errorf("Expected correlationId as structured metadata, got %v", first)
+	}
+	if len(info.Values[1]) != 2 {
+		t.Errorf("Expected no metadata without a correlation ID, got %v", info.Values[1])
+	}
+}
+
+func TestLokiSinkReplacesUnencodableEntries(t *testing.T) {
+	server := newLokiServer(t)
+	s := NewLokiSink(LokiOptions{URL: server.URL, BatchSize: 10, MaxWait: time.Hour, EnableBackpressure: true})
+
+	bad := lokiEntry(types.InfoLevel, "orders", "")
+	bad.Message = "bad"
+	bad.Fields["ch"] = make(chan int)
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+	_ = s.Write(bad)
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pushes := server.Pushes()
+	if len(pushes) != 1 {
+		t.Fatalf("Expected a single push, got %d", len(pushes))
+	}
+	var lines []string
+	for _, stream := range pushes[0].Streams {
+		for _, value := range stream.Values {
+			lines = append(lines, value[1].(string))
+		}
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected the 3 entries to be pushed, got %v", lines)
+	}
+	if !strings.Contains(lines[1], `"message":"bad"`) || !strings.Contains(lines[1], `"encodingError":`) {
+		t.Errorf("Expected the bad entry replaced with an encoding error, got %s", lines[1])
+	}
+}
+
+func TestLokiSinkBatchSize(t *testing.T) {
+	server := newLokiServer(t)
+	s := NewLokiSink(LokiOptions{URL: server.URL, BatchSize: 2, MaxWait: time.Hour, EnableBackpressure: true})
+	defer s.Close()
+
+	for i := 0; i < 4; i++ {
+		_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+	}
+
+	waitFor(t, func() bool { return len(server.Pushes()) == 2 })
+}
+
+func TestLokiSinkRetriesServerErrors(t *testing.T) {
+	server := newLokiServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	s := NewLokiSink(LokiOptions{URL: server.URL, MaxWait: time.Hour, RetryBackoff: time.Millisecond})
+	defer s.Close()
+
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+
+	if err := s.DrainAndWait(context.Background()); err != nil {
+		t.Fatalf("Expected the push to succeed after retries, got %v", err)
+	}
+	if got := len(server.Pushes()); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestLokiSinkDoesNotRetryClientErrors(t *testing.T) {
+	server := newLokiServer(t, http.StatusBadRequest)
+	s := NewLokiSink(LokiOptions{URL: server.URL, MaxWait: time.Hour, RetryBackoff: time.Millisecond})
+	defer s.Close()
+
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+
+	err := s.DrainAndWait(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("Expected a 400 failure, got %v", err)
+	}
+	if got := len(server.Pushes()); got != 1 {
+		t.Errorf("Expected a single attempt, got %d", got)
+	}
+}
+
+func TestLokiSinkGivesUpAfterMaxRetries(t *testing.T) {
+	server := newLokiServer(t, 500, 500, 500)
+	s := NewLokiSink(LokiOptions{URL: server.URL, MaxWait: time.Hour, MaxRetries: 1, RetryBackoff: time.Millisecond})
+	defer s.Close()
+
+	_ = s.Write(lokiEntry(types.InfoLevel, "orders", ""))
+
+	if err := s.DrainAndWait(context.Background()); err == nil {
+		t.Error("Expected an error after exhausting retries")
+	}
+	if got := len(server.Pushes()); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Package metricssink derives Prometheus metrics from log entries. It lives
+// apart from package sinks so that only applications importing it depend on
+// the Prometheus client.
+package metricssink
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// Options configures a MetricsSink.
+type Options struct {
+	// Registerer registers the collectors. Defaults to
+	// prometheus.DefaultRegisterer. Collectors already registered by another
+	// MetricsSink are reused.
+	Registerer prometheus.Registerer
+	// Namespace prefixes the metric names, e.g. "api" gives
+	// api_log_entries_total.
+	Namespace string
+	// Service is the service label of entries without a service field.
+	Service string
+	// Durations observes the duration of method-execution entries, those
+	// with an outcome as set by the decorators, into
+	// log_method_duration_seconds. Lines whose scope only records their
+	// caller, see LogOptions.IncludeCaller, are not observed.
+	Durations bool
+	// Buckets are the histogram buckets in seconds. Defaults to
+	// prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// MetricsSink counts entries in log_entries_total{level,service} and, when
+// enabled, observes method durations in
+// log_method_duration_seconds{service,method,outcome}. It writes nothing
+// else, so combine it with sinks.NewMultiSink to keep the log output.
+type MetricsSink struct {
+	service   string
+	entries   *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+// NewMetricsSink returns a MetricsSink whose collectors are registered with
+// options.Registerer.
+func NewMetricsSink(options Options) (*MetricsSink, error) {
+	if options.Registerer == nil {
+		options.Registerer = prometheus.DefaultRegisterer
+	}
+	if options.Buckets == nil {
+		options.Buckets = prometheus.DefBuckets
+	}
+
+	entries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: options.Namespace,
+		Name:      "log_entries_total",
+		Help:      "Log entries written, by level and service.",
+	}, []string{"level", "service"})
+	if err := register(options.Registerer, &entries); err != nil {
+		return nil, err
+	}
+	s := &MetricsSink{service: options.Service, entries: entries}
+
+	if options.Durations {
+		durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: options.Namespace,
+			Name:      "log_method_duration_seconds",
+			Help:      "Duration of logged method executions, by service, method and outcome.",
+			Buckets:   options.Buckets,
+		}, []string{"service", "method", "outcome"})
+		if err := register(options.Registerer, &durations); err != nil {
+			return nil, err
+		}
+		s.durations = durations
+	}
+	return s, nil
+}
+
+// register registers *collector, replacing it with the collector already
+// registered under the same description if there is one.
+func register[C prometheus.Collector](r prometheus.Registerer, collector *C) error {
+	err := r.Register(*collector)
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(C); ok {
+			*collector = existing
+			return nil
+		}
+	}
+	return err
+}
+
+// Write counts entry and observes its duration when it is a method
+// execution.
+func (s *MetricsSink) Write(entry types.LogEntry) error {
+	service := s.service
+	if name, ok := entry.Fields["service"].(string); ok && name != "" {
+		service = name
+	}
+	s.entries.WithLabelValues(string(entry.Level), service).Inc()
+
+	if s.durations != nil && entry.Outcome != "" {
+		method := entry.Scope.MethodName
+		if entry.Scope.ClassName != "" {
+			method = entry.Scope.ClassName + "." + method
+		}
+		seconds := entry.DurationMs * float64(time.Millisecond) / float64(time.Second)
+		s.durations.WithLabelValues(service, method, entry.Outcome).Observe(seconds)
+	}
+	return nil
+}
+
+// Close is a no-op; the metrics stay registered.
+func (s *MetricsSink) Close() error {
+	return nil
+}
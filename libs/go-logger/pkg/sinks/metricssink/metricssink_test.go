@@ -0,0 +1,118 @@
+package metricssink
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/sinks"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+type mockSink struct {
+	entries []types.LogEntry
+}
+
+func (s *mockSink) Write(entry types.LogEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func TestMetricsSinkCountsByLevelAndService(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sink, err := NewMetricsSink(Options{Registerer: reg, Service: "api"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out := &mockSink{}
+	multi := sinks.NewMultiSink(sink, out)
+
+	multi.Write(types.LogEntry{Level: types.ErrorLevel})
+	multi.Write(types.LogEntry{Level: types.ErrorLevel})
+	multi.Write(types.LogEntry{Level: types.InfoLevel, Fields: map[string]interface{}{"service": "worker"}})
+
+	if got := testutil.ToFloat64(sink.entries.WithLabelValues("error", "api")); got != 2 {
+		t.Errorf("Expected 2 error entries for api, got %v", got)
+	}
+	if got := testutil.ToFloat64(sink.entries.WithLabelValues("info", "worker")); got != 1 {
+		t.Errorf("Expected 1 info entry for worker, got %v", got)
+	}
+	if len(out.entries) != 3 {
+		t.Errorf("Expected the entries to reach the other sink, got %d", len(out.entries))
+	}
+}
+
+func TestMetricsSinkObservesMethodDurations(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sink, err := NewMetricsSink(Options{Registerer: reg, Durations: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sink.Write(types.LogEntry{
+		Level:      types.InfoLevel,
+		Scope:      types.LogScope{ClassName: "UserService", MethodName: "Create"},
+		Outcome:    "success",
+		DurationMs: 250,
+	})
+	sink.Write(types.LogEntry{Level: types.InfoLevel, DurationMs: 10})
+
+	if got := testutil.CollectAndCount(sink.durations); got != 1 {
+		t.Errorf("Expected 1 observed method, got %d", got)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "log_method_duration_seconds" {
+			continue
+		}
+		metric := family.GetMetric()[0]
+		if h := metric.GetHistogram(); h.GetSampleCount() != 1 || h.GetSampleSum() != 0.25 {
+			t.Errorf("Expected one 0.25s observation, got %v", h)
+		}
+		if metric.GetLabel()[0].GetValue() != "UserService.Create" {
+			t.Errorf("Expected method UserService.Create, got %v", metric.GetLabel())
+		}
+	}
+}
+
+func TestMetricsSinkIgnoresCallerScopedLines(t *testing.T) {
+	sink, err := NewMetricsSink(Options{Registerer: prometheus.NewRegistry(), Durations: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// IncludeCaller sets the scope of ordinary lines, without an outcome.
+	sink.Write(types.LogEntry{
+		Level: types.InfoLevel,
+		Scope: types.LogScope{MethodName: "github.com/acme/orders.(*Handler).Serve", File: "handler.go", Line: 42},
+	})
+
+	if got := testutil.CollectAndCount(sink.durations); got != 0 {
+		t.Errorf("Expected no observation, got %d", got)
+	}
+}
+
+func TestNewMetricsSinkReusesRegisteredCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := NewMetricsSink(Options{Registerer: reg})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := NewMetricsSink(Options{Registerer: reg})
+	if err != nil {
+		t.Fatalf("Expected the second sink to reuse the collectors, got %v", err)
+	}
+
+	first.Write(types.LogEntry{Level: types.WarnLevel})
+	second.Write(types.LogEntry{Level: types.WarnLevel})
+
+	if got := testutil.ToFloat64(first.entries.WithLabelValues("warn", "")); got != 2 {
+		t.Errorf("Expected both sinks to share the counter, got %v", got)
+	}
+}
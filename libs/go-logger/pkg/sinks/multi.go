@@ -0,0 +1,47 @@
+package sinks
+
+import (
+	"errors"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// MultiSink fans every entry out to several sinks.
+type MultiSink struct {
+	sinks []types.Sink
+}
+
+// NewMultiSink returns a sink writing to each of sinks in order. Nil sinks
+// are ignored.
+func NewMultiSink(sinks ...types.Sink) *MultiSink {
+	m := &MultiSink{sinks: make([]types.Sink, 0, len(sinks))}
+	for _, s := range sinks {
+		if s != nil {
+			m.sinks = append(m.sinks, s)
+		}
+	}
+	return m
+}
+
+// Write sends entry to every sink. A failing sink does not prevent the
+// others from receiving the entry; all failures are joined.
+func (m *MultiSink) Write(entry types.LogEntry) error {
+	var errs []error
+	for _, s := range m.sinks {
+		if err := s.Write(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink and joins their errors.
+func (m *MultiSink) Close() error {
+	var errs []error
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
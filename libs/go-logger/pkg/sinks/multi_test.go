@@ -0,0 +1,42 @@
+package sinks
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiSinkWritesToAll(t *testing.T) {
+	first, second := &mockSink{}, &mockSink{}
+	m := NewMultiSink(first, nil, second)
+
+	if err := m.Write(entryWithMethod("a")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(first.Entries()) != 1 || len(second.Entries()) != 1 {
+		t.Errorf("Expected both sinks to receive the entry, got %d and %d", len(first.Entries()), len(second.Entries()))
+	}
+}
+
+func TestMultiSinkCollectsErrors(t *testing.T) {
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	failingA := &mockSink{writeErr: errA, closeErr: errA}
+	healthy := &mockSink{}
+	failingB := &mockSink{writeErr: errB}
+	m := NewMultiSink(failingA, healthy, failingB)
+
+	err := m.Write(entryWithMethod("a"))
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Expected joined errors, got %v", err)
+	}
+	if len(healthy.Entries()) != 1 || len(failingB.Entries()) != 1 {
+		t.Error("Expected a failing sink not to block the others")
+	}
+
+	if err := m.Close(); !errors.Is(err, errA) {
+		t.Errorf("Expected close error to be reported, got %v", err)
+	}
+	if !failingA.Closed() || !healthy.Closed() || !failingB.Closed() {
+		t.Error("Expected every sink to be closed")
+	}
+}
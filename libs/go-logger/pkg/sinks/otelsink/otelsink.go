@@ -0,0 +1,213 @@
+// Package otelsink exports log entries through the OpenTelemetry logs API.
+// It lives apart from package sinks so that only applications importing it
+// depend on OpenTelemetry logs.
+package otelsink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const (
+	defaultName         = "github.com/mateusmacedo/boyscout/go-logger"
+	defaultFlushTimeout = 5 * time.Second
+)
+
+// severities maps levels to OpenTelemetry severity numbers.
+var severities = map[types.LogLevel]log.Severity{
+	types.TraceLevel: log.SeverityTrace,
+	types.DebugLevel: log.SeverityDebug,
+	types.InfoLevel:  log.SeverityInfo,
+	types.WarnLevel:  log.SeverityWarn,
+	types.ErrorLevel: log.SeverityError,
+	types.FatalLevel: log.SeverityFatal,
+}
+
+// Options configures an OTelSink.
+type Options struct {
+	// LoggerProvider receives the records, typically an SDK provider
+	// exporting to an OTLP collector. Required.
+	LoggerProvider log.LoggerProvider
+	// Name is the instrumentation scope of the records. Defaults to the
+	// module path of this logger.
+	Name string
+	// FlushTimeout bounds the provider flush done by Close. Defaults to
+	// five seconds.
+	FlushTimeout time.Duration
+}
+
+// flusher is implemented by SDK logger providers.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// OTelSink emits every entry as an OpenTelemetry log record. The message
+// becomes the body, the level the severity and the fields attributes; the
+// trace_id and span_id fields, when present, link the record to its span.
+type OTelSink struct {
+	provider     log.LoggerProvider
+	logger       log.Logger
+	flushTimeout time.Duration
+}
+
+// NewOTelSink returns an OTelSink emitting through options.LoggerProvider.
+func NewOTelSink(options Options) *OTelSink {
+	if options.Name == "" {
+		options.Name = defaultName
+	}
+	if options.FlushTimeout <= 0 {
+		options.FlushTimeout = defaultFlushTimeout
+	}
+	return &OTelSink{
+		provider:     options.LoggerProvider,
+		logger:       options.LoggerProvider.Logger(options.Name),
+		flushTimeout: options.FlushTimeout,
+	}
+}
+
+// Write emits entry. Entries below the level enabled by the provider are
+// skipped without building a record.
+func (s *OTelSink) Write(entry types.LogEntry) error {
+	ctx := spanContext(entry.Fields)
+	severity := severities[entry.Level]
+
+	var param log.EnabledParameters
+	param.SetSeverity(severity)
+	if !s.logger.Enabled(ctx, param) {
+		return nil
+	}
+
+	var record log.Record
+	record.SetTimestamp(entry.Timestamp)
+	record.SetSeverity(severity)
+	record.SetSeverityText(string(entry.Level))
+	record.AddAttributes(attributes(entry)...)
+	if entry.Message != "" {
+		record.SetBody(log.StringValue(entry.Message))
+	}
+	s.logger.Emit(ctx, record)
+	return nil
+}
+
+// Close flushes the provider when it supports flushing. The provider is
+// not shut down, as it may be shared with other instrumentation.
+func (s *OTelSink) Close() error {
+	f, ok := s.provider.(flusher)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.flushTimeout)
+	defer cancel()
+	return f.ForceFlush(ctx)
+}
+
+// spanContext returns a context carrying the span identified by the
+// trace_id and span_id fields, or a background context when they are
+// missing or malformed.
+func spanContext(fields map[string]interface{}) context.Context {
+	ctx := context.Background()
+	traceHex, _ := fields["trace_id"].(string)
+	spanHex, _ := fields["span_id"].(string)
+	traceID, err := trace.TraceIDFromHex(traceHex)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(spanHex)
+	if err != nil {
+		return ctx
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, Remote: true})
+	return trace.ContextWithSpanContext(ctx, sc)
+}
+
+// attributes converts the fields, correlation ID, scope and error of
+// entry into record attributes, using the OpenTelemetry semantic
+// conventions for the code and exception ones.
+func attributes(entry types.LogEntry) []log.KeyValue {
+	attrs := make([]log.KeyValue, 0, len(entry.Fields)+6)
+	for k, v := range entry.Fields {
+		switch k {
+		case "trace_id", "span_id":
+			continue
+		}
+		attrs = append(attrs, log.KeyValue{Key: k, Value: value(v)})
+	}
+	if _, ok := entry.Fields["correlationId"]; !ok && entry.CorrelationID != "" {
+		attrs = append(attrs, log.String("correlationId", entry.CorrelationID))
+	}
+	if entry.Scope.MethodName != "" {
+		attrs = append(attrs, log.String("code.function", entry.Scope.MethodName))
+	}
+	if entry.Scope.ClassName != "" {
+		attrs = append(attrs, log.String("code.namespace", entry.Scope.ClassName))
+	}
+	if entry.Scope.File != "" {
+		attrs = append(attrs, log.String("code.filepath", entry.Scope.File), log.Int("code.lineno", entry.Scope.Line))
+	}
+	if entry.Error != nil {
+		attrs = append(attrs, log.String("exception.type", entry.Error.Name), log.String("exception.message", entry.Error.Message))
+		if entry.Error.Stack != "" {
+			attrs = append(attrs, log.String("exception.stacktrace", entry.Error.Stack))
+		}
+	}
+	return attrs
+}
+
+// value converts a field value into a log value. Types without an
+// OpenTelemetry counterpart are formatted with fmt.
+func value(v interface{}) log.Value {
+	switch v := v.(type) {
+	case nil:
+		return log.Value{}
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int8:
+		return log.Int64Value(int64(v))
+	case int16:
+		return log.Int64Value(int64(v))
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint8:
+		return log.Int64Value(int64(v))
+	case uint16:
+		return log.Int64Value(int64(v))
+	case uint32:
+		return log.Int64Value(int64(v))
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case []byte:
+		return log.BytesValue(v)
+	case []interface{}:
+		values := make([]log.Value, len(v))
+		for i, item := range v {
+			values[i] = value(item)
+		}
+		return log.SliceValue(values...)
+	case map[string]interface{}:
+		kvs := make([]log.KeyValue, 0, len(v))
+		for k, item := range v {
+			kvs = append(kvs, log.KeyValue{Key: k, Value: value(item)})
+		}
+		return log.MapValue(kvs...)
+	case error:
+		return log.StringValue(v.Error())
+	case fmt.Stringer:
+		return log.StringValue(v.String())
+	default:
+		return log.StringValue(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,116 @@
+package otelsink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/logtest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func records(t *testing.T, rec *logtest.Recorder) []logtest.EmittedRecord {
+	t.Helper()
+	var out []logtest.EmittedRecord
+	for _, scope := range rec.Result() {
+		out = append(out, scope.Records...)
+	}
+	return out
+}
+
+func attrs(r log.Record) map[string]log.Value {
+	out := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		out[kv.Key] = kv.Value
+		return true
+	})
+	return out
+}
+
+func TestOTelSinkMapsEntry(t *testing.T) {
+	rec := logtest.NewRecorder()
+	s := NewOTelSink(Options{LoggerProvider: rec})
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := s.Write(types.LogEntry{
+		Timestamp:     ts,
+		Level:         types.WarnLevel,
+		CorrelationID: "cid-1",
+		Message:       "slow query",
+		Error:         &types.LogError{Name: "*errors.errorString", Message: "boom"},
+		Fields: map[string]interface{}{
+			"correlationId": "cid-1",
+			"db.ms":         120,
+			"trace_id":      "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":       "00f067aa0ba902b7",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got := records(t, rec)
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(got))
+	}
+	r := got[0]
+	if r.Body().AsString() != "slow query" || r.Severity() != log.SeverityWarn || r.SeverityText() != "warn" || !r.Timestamp().Equal(ts) {
+		t.Errorf("Expected warn record \"slow query\" at %v, got %v %v %q at %v", ts, r.Severity(), r.SeverityText(), r.Body().AsString(), r.Timestamp())
+	}
+
+	a := attrs(r.Record)
+	if a["db.ms"].AsInt64() != 120 || a["correlationId"].AsString() != "cid-1" || a["exception.message"].AsString() != "boom" {
+		t.Errorf("Expected fields and error as attributes, got %v", a)
+	}
+	if _, ok := a["message"]; ok {
+		t.Error("Expected message to be the body only")
+	}
+
+	sc := trace.SpanContextFromContext(r.Context())
+	if sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the span from the trace fields, got %v", sc)
+	}
+}
+
+func TestOTelSinkSkipsDisabledSeverities(t *testing.T) {
+	rec := logtest.NewRecorder(logtest.WithEnabledFunc(func(_ context.Context, p log.EnabledParameters) bool {
+		severity, _ := p.Severity()
+		return severity >= log.SeverityWarn
+	}))
+	s := NewOTelSink(Options{LoggerProvider: rec})
+
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "skipped"})
+	_ = s.Write(types.LogEntry{Level: types.ErrorLevel, Message: "kept"})
+
+	if got := records(t, rec); len(got) != 1 || got[0].Body().AsString() != "kept" {
+		t.Errorf("Expected only the error record, got %v", got)
+	}
+}
+
+// flushingProvider records ForceFlush calls.
+type flushingProvider struct {
+	*logtest.Recorder
+	flushed  bool
+	flushErr error
+}
+
+func (p *flushingProvider) ForceFlush(context.Context) error {
+	p.flushed = true
+	return p.flushErr
+}
+
+func TestOTelSinkCloseFlushesProvider(t *testing.T) {
+	p := &flushingProvider{Recorder: logtest.NewRecorder(), flushErr: errors.New("collector down")}
+	s := NewOTelSink(Options{LoggerProvider: p})
+
+	if err := s.Close(); err == nil || !p.flushed {
+		t.Errorf("Expected Close to flush and report the error, got %v", err)
+	}
+	if err := NewOTelSink(Options{LoggerProvider: logtest.NewRecorder()}).Close(); err != nil {
+		t.Errorf("Expected no error without a flushable provider, got %v", err)
+	}
+}
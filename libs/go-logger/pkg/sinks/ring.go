@@ -0,0 +1,75 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// RingBufferSink keeps the most recent entries in memory, so a crash or
+// panic handler can dump the last lines of a process even when they never
+// reached durable storage. Combine it with the regular sink through
+// NewMultiSink.
+type RingBufferSink struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+	// next is where the next entry is stored; the buffer is full once
+	// full is set, and next then points at the oldest entry.
+	next int
+	full bool
+}
+
+// NewRingBufferSink returns a RingBufferSink retaining capacity entries.
+// A capacity of zero or less retains 1000.
+func NewRingBufferSink(capacity int) *RingBufferSink {
+	if capacity <= 0 {
+		capacity = defaultBufferSize
+	}
+	return &RingBufferSink{entries: make([]types.LogEntry, capacity)}
+}
+
+// Write stores entry, overwriting the oldest one when the buffer is full.
+func (s *RingBufferSink) Write(entry types.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[s.next] = entry
+	s.next++
+	if s.next == len(s.entries) {
+		s.next = 0
+		s.full = true
+	}
+	return nil
+}
+
+// Snapshot returns a copy of the retained entries, oldest first. The
+// entries share their Fields maps with the ones written, which the logger
+// never modifies afterwards.
+func (s *RingBufferSink) Snapshot() []types.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return append([]types.LogEntry(nil), s.entries[:s.next]...)
+	}
+	out := make([]types.LogEntry, 0, len(s.entries))
+	out = append(out, s.entries[s.next:]...)
+	return append(out, s.entries[:s.next]...)
+}
+
+// Dump writes the retained entries to w as JSON lines, oldest first, e.g.
+// to stderr from a panic handler.
+func (s *RingBufferSink) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range s.Snapshot() {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is a no-op: the entries stay available for Snapshot and Dump.
+func (s *RingBufferSink) Close() error {
+	return nil
+}
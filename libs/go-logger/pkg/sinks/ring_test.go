@@ -0,0 +1,83 @@
+package sinks
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRingBufferSinkKeepsMostRecent(t *testing.T) {
+	s := NewRingBufferSink(3)
+
+	if got := s.Snapshot(); len(got) != 0 {
+		t.Fatalf("Expected an empty snapshot, got %d entries", len(got))
+	}
+	for _, m := range []string{"a", "b"} {
+		_ = s.Write(entryWithMethod(m))
+	}
+	if got := strings.Join(methods(s.Snapshot()), ""); got != "ab" {
+		t.Errorf("Expected ab before wrapping, got %s", got)
+	}
+	for _, m := range []string{"c", "d", "e"} {
+		_ = s.Write(entryWithMethod(m))
+	}
+	if got := strings.Join(methods(s.Snapshot()), ""); got != "cde" {
+		t.Errorf("Expected the 3 most recent entries oldest first, got %s", got)
+	}
+}
+
+func TestRingBufferSinkSnapshotIsACopy(t *testing.T) {
+	s := NewRingBufferSink(2)
+	_ = s.Write(entryWithMethod("a"))
+
+	snapshot := s.Snapshot()
+	_ = s.Write(entryWithMethod("b"))
+	_ = s.Write(entryWithMethod("c"))
+
+	if got := strings.Join(methods(snapshot), ""); got != "a" {
+		t.Errorf("Expected the snapshot to be unaffected by later writes, got %s", got)
+	}
+}
+
+func TestRingBufferSinkDump(t *testing.T) {
+	s := NewRingBufferSink(0)
+	m := NewMultiSink(&mockSink{}, s)
+	_ = m.Write(entryWithMethod("a"))
+	_ = m.Write(entryWithMethod("b"))
+	_ = m.Close()
+
+	var buf bytes.Buffer
+	if err := s.Dump(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"methodName":"a"`) {
+		t.Errorf("Expected the entries as JSON lines after Close, got %q", buf.String())
+	}
+}
+
+func TestRingBufferSinkConcurrentAccess(t *testing.T) {
+	s := NewRingBufferSink(16)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = s.Write(entryWithMethod("a"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(s.Snapshot()); got != 16 {
+		t.Errorf("Expected a full buffer, got %d entries", got)
+	}
+}
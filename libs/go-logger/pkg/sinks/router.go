@@ -0,0 +1,68 @@
+package sinks
+
+import (
+	"errors"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// LevelRouterOptions configures a LevelRouterSink.
+type LevelRouterOptions struct {
+	// Base receives every entry, or only those below Threshold when
+	// Exclusive is set.
+	Base types.Sink
+	// High receives the entries at or above Threshold, e.g. an alerting
+	// pipeline.
+	High types.Sink
+	// Threshold is the least severe level sent to High. Defaults to warn.
+	Threshold types.LogLevel
+	// Exclusive keeps entries sent to High out of Base.
+	Exclusive bool
+}
+
+// LevelRouterSink routes entries to a base and a high severity sink by
+// level. Either sink may be nil.
+type LevelRouterSink struct {
+	options LevelRouterOptions
+}
+
+// NewLevelRouterSink returns a LevelRouterSink configured by options.
+func NewLevelRouterSink(options LevelRouterOptions) *LevelRouterSink {
+	if options.Threshold == "" {
+		options.Threshold = types.WarnLevel
+	}
+	return &LevelRouterSink{options: options}
+}
+
+// Write sends entry to High when its level is at or above the threshold
+// and to Base unless it went to High and the router is exclusive. Both
+// failures are joined.
+func (r *LevelRouterSink) Write(entry types.LogEntry) error {
+	var errs []error
+	high := entry.Level.Enabled(r.options.Threshold)
+	if high && r.options.High != nil {
+		if err := r.options.High.Write(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !(high && r.options.Exclusive) && r.options.Base != nil {
+		if err := r.options.Base.Write(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes both sinks and joins their errors.
+func (r *LevelRouterSink) Close() error {
+	var errs []error
+	for _, s := range []types.Sink{r.options.Base, r.options.High} {
+		if s == nil {
+			continue
+		}
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
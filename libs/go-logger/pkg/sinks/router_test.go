@@ -0,0 +1,57 @@
+package sinks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func entryAt(level types.LogLevel) types.LogEntry {
+	return types.LogEntry{Level: level, Scope: types.LogScope{MethodName: string(level)}}
+}
+
+func TestLevelRouterSinkRoutesByThreshold(t *testing.T) {
+	base, high := &mockSink{}, &mockSink{}
+	r := NewLevelRouterSink(LevelRouterOptions{Base: base, High: high})
+
+	for _, level := range []types.LogLevel{types.DebugLevel, types.InfoLevel, types.WarnLevel, types.ErrorLevel} {
+		_ = r.Write(entryAt(level))
+	}
+
+	if got := methods(base.Entries()); len(got) != 4 {
+		t.Errorf("Expected base to receive every entry, got %v", got)
+	}
+	if got := methods(high.Entries()); len(got) != 2 || got[0] != "warn" || got[1] != "error" {
+		t.Errorf("Expected high to receive warn and error, got %v", got)
+	}
+}
+
+func TestLevelRouterSinkExclusive(t *testing.T) {
+	base, high := &mockSink{}, &mockSink{}
+	r := NewLevelRouterSink(LevelRouterOptions{Base: base, High: high, Threshold: types.ErrorLevel, Exclusive: true})
+
+	_ = r.Write(entryAt(types.WarnLevel))
+	_ = r.Write(entryAt(types.FatalLevel))
+
+	if got := methods(base.Entries()); len(got) != 1 || got[0] != "warn" {
+		t.Errorf("Expected base to receive only warn, got %v", got)
+	}
+	if got := methods(high.Entries()); len(got) != 1 || got[0] != "fatal" {
+		t.Errorf("Expected high to receive only fatal, got %v", got)
+	}
+}
+
+func TestLevelRouterSinkErrorsAndClose(t *testing.T) {
+	errBase, errHigh := errors.New("base failed"), errors.New("high failed")
+	base := &mockSink{writeErr: errBase, closeErr: errBase}
+	high := &mockSink{writeErr: errHigh}
+	r := NewLevelRouterSink(LevelRouterOptions{Base: base, High: high})
+
+	if err := r.Write(entryAt(types.ErrorLevel)); !errors.Is(err, errBase) || !errors.Is(err, errHigh) {
+		t.Errorf("Expected joined errors, got %v", err)
+	}
+	if err := r.Close(); !errors.Is(err, errBase) || !base.Closed() || !high.Closed() {
+		t.Errorf("Expected both sinks closed and the base error, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package sentrysink reports log entries to Sentry. It lives apart from
+// package sinks so that only applications importing it depend on the
+// Sentry SDK.
+package sentrysink
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+const defaultFlushTimeout = 2 * time.Second
+
+// ErrFlushTimeout is returned by Close when events are still pending after
+// the flush timeout.
+var ErrFlushTimeout = errors.New("sentry flush timed out")
+
+var levels = map[types.LogLevel]sentry.Level{
+	types.TraceLevel: sentry.LevelDebug,
+	types.DebugLevel: sentry.LevelDebug,
+	types.InfoLevel:  sentry.LevelInfo,
+	types.WarnLevel:  sentry.LevelWarning,
+	types.ErrorLevel: sentry.LevelError,
+	types.FatalLevel: sentry.LevelFatal,
+}
+
+// Options configures a SentrySink.
+type Options struct {
+	// Hub captures the events. Defaults to sentry.CurrentHub(), set up by
+	// sentry.Init.
+	Hub *sentry.Hub
+	// Breadcrumbs records entries below error as breadcrumbs on the hub
+	// scope, so they are attached to the next event. Off by default, as
+	// a hub shared between requests mixes their breadcrumbs.
+	Breadcrumbs bool
+	// FlushTimeout bounds the flush done by Close. Defaults to two
+	// seconds.
+	FlushTimeout time.Duration
+}
+
+// SentrySink captures error and fatal entries as Sentry events. The
+// message becomes the event message, the correlation ID a correlationId
+// tag, the fields extra data and an attached error the event exception,
+// with its stack trace parsed into frames when it is a Go stack.
+type SentrySink struct {
+	hub          *sentry.Hub
+	breadcrumbs  bool
+	flushTimeout time.Duration
+}
+
+// NewSentrySink returns a SentrySink capturing through options.Hub.
+func NewSentrySink(options Options) *SentrySink {
+	if options.Hub == nil {
+		options.Hub = sentry.CurrentHub()
+	}
+	if options.FlushTimeout <= 0 {
+		options.FlushTimeout = defaultFlushTimeout
+	}
+	return &SentrySink{hub: options.Hub, breadcrumbs: options.Breadcrumbs, flushTimeout: options.FlushTimeout}
+}
+
+// Write captures entry when it is at error level or above, and records it
+// as a breadcrumb otherwise when enabled.
+func (s *SentrySink) Write(entry types.LogEntry) error {
+	if !entry.Level.Enabled(types.ErrorLevel) {
+		if s.breadcrumbs {
+			s.hub.AddBreadcrumb(breadcrumb(entry), nil)
+		}
+		return nil
+	}
+	s.hub.CaptureEvent(event(entry))
+	return nil
+}
+
+// Close flushes the events buffered by the hub's client.
+func (s *SentrySink) Close() error {
+	if !s.hub.Flush(s.flushTimeout) {
+		return ErrFlushTimeout
+	}
+	return nil
+}
+
+func event(entry types.LogEntry) *sentry.Event {
+	e := sentry.NewEvent()
+	e.Level = levels[entry.Level]
+	e.Message = entry.Message
+	if !entry.Timestamp.IsZero() {
+		e.Timestamp = entry.Timestamp
+	}
+	if entry.CorrelationID != "" {
+		e.Tags["correlationId"] = entry.CorrelationID
+	}
+	e.Extra = extra(entry)
+	if entry.Scope.MethodName != "" {
+		e.Extra["scope"] = entry.Scope
+	}
+	if entry.Error != nil {
+		exception := sentry.Exception{Type: entry.Error.Name, Value: entry.Error.Message}
+		if frames := parseStack(entry.Error.Stack); len(frames) > 0 {
+			exception.Stacktrace = &sentry.Stacktrace{Frames: frames}
+		} else if entry.Error.Stack != "" {
+			e.Extra["stack"] = entry.Error.Stack
+		}
+		e.Exception = []sentry.Exception{exception}
+	}
+	return e
+}
+
+func breadcrumb(entry types.LogEntry) *sentry.Breadcrumb {
+	return &sentry.Breadcrumb{
+		Category:  "log",
+		Level:     levels[entry.Level],
+		Message:   entry.Message,
+		Data:      extra(entry),
+		Timestamp: entry.Timestamp,
+	}
+}
+
+// extra returns a copy of the fields of entry, which the event may extend.
+func extra(entry types.LogEntry) map[string]interface{} {
+	out := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		out[k] = v
+	}
+	return out
+}
+
+// parseStack parses a Go stack trace, as printed by runtime/debug.Stack
+// or by github.com/pkg/errors with %+v, into Sentry frames ordered from
+// the outermost call, as Sentry expects. Unrecognized lines are skipped.
+func parseStack(stack string) []sentry.Frame {
+	lines := strings.Split(stack, "\n")
+	var frames []sentry.Frame
+	for i := 0; i+1 < len(lines); i++ {
+		fn, loc := lines[i], lines[i+1]
+		if fn == "" || strings.HasPrefix(fn, "\t") || !strings.HasPrefix(loc, "\t") {
+			continue
+		}
+		i++
+
+		loc = strings.TrimPrefix(loc, "\t")
+		if j := strings.Index(loc, " +0x"); j >= 0 {
+			loc = loc[:j]
+		}
+		frame := sentry.Frame{AbsPath: loc, InApp: true}
+		if j := strings.LastIndexByte(loc, ':'); j >= 0 {
+			if line, err := strconv.Atoi(loc[j+1:]); err == nil {
+				frame.AbsPath, frame.Lineno = loc[:j], line
+			}
+		}
+		frame.Filename = frame.AbsPath[strings.LastIndexByte(frame.AbsPath, '/')+1:]
+
+		fn = strings.TrimPrefix(fn, "created by ")
+		if j := strings.Index(fn, " in goroutine "); j >= 0 {
+			fn = fn[:j]
+		}
+		if strings.HasSuffix(fn, ")") {
+			if j := strings.LastIndexByte(fn, '('); j > 0 {
+				fn = fn[:j]
+			}
+		}
+		frame.Module, frame.Function = splitFunction(fn)
+		frames = append(frames, frame)
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
+
+// splitFunction splits a qualified function name such as
+// github.com/org/pkg.(*T).Method into its package path and function.
+func splitFunction(name string) (module, function string) {
+	start := strings.LastIndexByte(name, '/') + 1
+	if j := strings.IndexByte(name[start:], '.'); j >= 0 {
+		return name[:start+j], name[start+j+1:]
+	}
+	return "", name
+}
@@ -0,0 +1,107 @@
+package sentrysink
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// recordingTransport keeps the events sent by a client.
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool { return true }
+
+func (t *recordingTransport) Configure(sentry.ClientOptions) {}
+
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *recordingTransport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+func newTestHub(t *testing.T) (*sentry.Hub, *recordingTransport) {
+	t.Helper()
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), transport
+}
+
+const goStack = `goroutine 1 [running]:
+github.com/acme/orders.(*Service).Charge(0xc000010000)
+	/src/orders/service.go:42 +0x1d
+main.main()
+	/src/main.go:10 +0x25
+`
+
+func TestSentrySinkCapturesErrors(t *testing.T) {
+	hub, transport := newTestHub(t)
+	s := NewSentrySink(Options{Hub: hub, Breadcrumbs: true})
+
+	_ = s.Write(types.LogEntry{Level: types.InfoLevel, Message: "loading order"})
+	_ = s.Write(types.LogEntry{
+		Level:         types.ErrorLevel,
+		CorrelationID: "cid-1",
+		Error:         &types.LogError{Name: "*errors.errorString", Message: "card declined", Stack: goStack},
+		Message:       "charge failed",
+		Fields:        map[string]interface{}{"orderId": "42"},
+	})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Message != "charge failed" || e.Level != sentry.LevelError || e.Tags["correlationId"] != "cid-1" || e.Extra["orderId"] != "42" {
+		t.Errorf("Expected message, level, tag and extra, got %+v", e)
+	}
+	if len(e.Breadcrumbs) != 1 || e.Breadcrumbs[0].Message != "loading order" {
+		t.Errorf("Expected the info entry as a breadcrumb, got %+v", e.Breadcrumbs)
+	}
+	if len(e.Exception) != 1 || e.Exception[0].Value != "card declined" || e.Exception[0].Stacktrace == nil {
+		t.Fatalf("Expected an exception with a stack trace, got %+v", e.Exception)
+	}
+	frames := e.Exception[0].Stacktrace.Frames
+	if len(frames) != 2 || frames[1].Module != "github.com/acme/orders" || frames[1].Function != "(*Service).Charge" || frames[1].Lineno != 42 {
+		t.Errorf("Expected frames outermost first, got %+v", frames)
+	}
+}
+
+func TestSentrySinkIgnoresLowerLevelsWithoutBreadcrumbs(t *testing.T) {
+	hub, transport := newTestHub(t)
+	s := NewSentrySink(Options{Hub: hub})
+
+	_ = s.Write(types.LogEntry{Level: types.WarnLevel, Message: "slow"})
+	_ = s.Write(types.LogEntry{Level: types.FatalLevel, Message: "down"})
+
+	events := transport.Events()
+	if len(events) != 1 || events[0].Level != sentry.LevelFatal || len(events[0].Breadcrumbs) != 0 {
+		t.Errorf("Expected a single fatal event without breadcrumbs, got %+v", events)
+	}
+}
+
+func TestParseStackKeepsUnknownFormatsAsExtra(t *testing.T) {
+	e := event(types.LogEntry{Level: types.ErrorLevel, Error: &types.LogError{Name: "E", Message: "m", Stack: "not a go stack"}})
+
+	if e.Exception[0].Stacktrace != nil || e.Extra["stack"] != "not a go stack" {
+		t.Errorf("Expected the raw stack as extra data, got %+v", e)
+	}
+}
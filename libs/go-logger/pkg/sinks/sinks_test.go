@@ -0,0 +1,91 @@
+package sinks
+
+import (
+	"sync"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// mockSink records the entries it receives.
+type mockSink struct {
+	mu       sync.Mutex
+	entries  []types.LogEntry
+	closed   bool
+	writeErr error
+	closeErr error
+}
+
+func (m *mockSink) Write(entry types.LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return m.writeErr
+}
+
+func (m *mockSink) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return m.closeErr
+}
+
+func (m *mockSink) Entries() []types.LogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]types.LogEntry(nil), m.entries...)
+}
+
+func (m *mockSink) Closed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+// blockingSink blocks every Write until release is closed, signalling on
+// started when a write begins.
+type blockingSink struct {
+	mockSink
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingSink() *blockingSink {
+	return &blockingSink{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (b *blockingSink) Write(entry types.LogEntry) error {
+	b.started <- struct{}{}
+	<-b.release
+	return b.mockSink.Write(entry)
+}
+
+func entryWithMethod(method string) types.LogEntry {
+	return types.LogEntry{Level: types.InfoLevel, Scope: types.LogScope{MethodName: method}}
+}
+
+func methods(entries []types.LogEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Scope.MethodName
+	}
+	return out
+}
+
+// batchSink records the batches handed to WriteBatch.
+type batchSink struct {
+	mockSink
+	batches [][]types.LogEntry
+}
+
+func (b *batchSink) WriteBatch(entries []types.LogEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batches = append(b.batches, append([]types.LogEntry(nil), entries...))
+	return b.writeErr
+}
+
+func (b *batchSink) Batches() [][]types.LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][]types.LogEntry(nil), b.batches...)
+}
@@ -0,0 +1,48 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// MarshalFunc encodes an entry into a single line, without the trailing
+// newline.
+type MarshalFunc func(entry types.LogEntry) ([]byte, error)
+
+// WriterSink writes every entry to an io.Writer as one line, JSON by
+// default. Writes are serialized so lines never interleave.
+type WriterSink struct {
+	mu      sync.Mutex
+	w       io.Writer
+	marshal MarshalFunc
+}
+
+// NewWriterSink returns a WriterSink writing to w. A nil marshal encodes
+// entries with encoding/json.
+func NewWriterSink(w io.Writer, marshal MarshalFunc) *WriterSink {
+	if marshal == nil {
+		marshal = func(entry types.LogEntry) ([]byte, error) { return json.Marshal(entry) }
+	}
+	return &WriterSink{w: w, marshal: marshal}
+}
+
+func (s *WriterSink) Write(entry types.LogEntry) error {
+	line, err := s.marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(line)
+	return err
+}
+
+// Close is a no-op: the writer belongs to the caller.
+func (s *WriterSink) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestWriterSinkWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf, nil)
+
+	_ = s.Write(entryWithMethod("a"))
+	_ = s.Write(entryWithMethod("b"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	var entry types.LogEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", lines[1], err)
+	}
+	if entry.Scope.MethodName != "b" {
+		t.Errorf("Expected method 'b', got %q", entry.Scope.MethodName)
+	}
+}
+
+func TestWriterSinkCustomMarshal(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf, func(entry types.LogEntry) ([]byte, error) {
+		return []byte(string(entry.Level) + " " + entry.Scope.MethodName), nil
+	})
+
+	_ = s.Write(entryWithMethod("a"))
+
+	if buf.String() != "info a\n" {
+		t.Errorf("Expected 'info a\\n', got %q", buf.String())
+	}
+}
+
+func TestWriterSinkMarshalError(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf, func(types.LogEntry) ([]byte, error) { return nil, errors.New("bad entry") })
+
+	if err := s.Write(entryWithMethod("a")); err == nil || buf.Len() != 0 {
+		t.Errorf("Expected the marshal error and no output, got %v and %q", err, buf.String())
+	}
+}
+
+func TestWriterSinkConcurrentWrites(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = s.Write(entryWithMethod("m"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Fatalf("Expected every line to be valid JSON, got %q", line)
+		}
+	}
+}
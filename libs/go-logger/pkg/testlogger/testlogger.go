@@ -0,0 +1,379 @@
+// Package testlogger provides a types.Logger that records entries in
+// memory so tests can assert on what was logged.
+package testlogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/kv"
+	"github.com/mateusmacedo/boyscout/go-logger/internal/stack"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+// TestLogger records the entries written through the logger returned by
+// New and the loggers derived from it. It is safe for concurrent use.
+type TestLogger struct {
+	mu      sync.Mutex
+	entries []types.LogEntry
+}
+
+// New returns a recorder and a logger writing to it. The logger emits
+// every level by default and never redacts, so fields are recorded as
+// given. Fatal is recorded like any other level and does not exit.
+func New() (*TestLogger, types.Logger) {
+	t := &TestLogger{}
+	return t, &logger{recorder: t, fields: map[string]interface{}{}, level: &levelNode{level: types.TraceLevel, set: true}}
+}
+
+// Entries returns a copy of the recorded entries, oldest first.
+func (t *TestLogger) Entries() []types.LogEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]types.LogEntry(nil), t.entries...)
+}
+
+// LastEntry returns the most recent entry, and false when nothing has
+// been logged.
+func (t *TestLogger) LastEntry() (types.LogEntry, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.entries) == 0 {
+		return types.LogEntry{}, false
+	}
+	return t.entries[len(t.entries)-1], true
+}
+
+// HasEntry reports whether an entry was logged at level with a message
+// containing msg.
+func (t *TestLogger) HasEntry(level types.LogLevel, msg string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.entries {
+		if e.Level == level && strings.Contains(e.Message, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset discards the recorded entries.
+func (t *TestLogger) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = nil
+}
+
+func (t *TestLogger) record(entry types.LogEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, entry)
+}
+
+// Message returns the message of entry.
+//
+// Deprecated: use entry.Message.
+func Message(entry types.LogEntry) string {
+	return entry.Message
+}
+
+// levelNode holds a logger's level. Loggers that have not set their own
+// level follow their parent's.
+type levelNode struct {
+	parent *levelNode
+	level  types.LogLevel
+	set    bool
+}
+
+type logger struct {
+	recorder      *TestLogger
+	fields        map[string]interface{}
+	correlationID string
+	traceID       string
+	spanID        string
+	deadlineCtx   context.Context
+	prefix        string
+	namespace     []string
+	name          string
+	err           *types.LogError
+	stack         string
+	level         *levelNode
+}
+
+func (l *logger) Trace(msg string, fields ...map[string]interface{}) {
+	l.log(types.TraceLevel, msg, fields...)
+}
+
+func (l *logger) Debug(msg string, fields ...map[string]interface{}) {
+	l.log(types.DebugLevel, msg, fields...)
+}
+
+func (l *logger) Info(msg string, fields ...map[string]interface{}) {
+	l.log(types.InfoLevel, msg, fields...)
+}
+
+func (l *logger) Warn(msg string, fields ...map[string]interface{}) {
+	l.log(types.WarnLevel, msg, fields...)
+}
+
+func (l *logger) Error(msg string, fields ...map[string]interface{}) {
+	l.log(types.ErrorLevel, msg, fields...)
+}
+
+func (l *logger) Fatal(msg string, fields ...map[string]interface{}) {
+	l.log(types.FatalLevel, msg, fields...)
+}
+
+func (l *logger) DebugCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	l.withContext(ctx).log(types.DebugLevel, msg, fields...)
+}
+
+func (l *logger) InfoCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	l.withContext(ctx).log(types.InfoLevel, msg, fields...)
+}
+
+func (l *logger) WarnCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	l.withContext(ctx).log(types.WarnLevel, msg, fields...)
+}
+
+func (l *logger) ErrorCtx(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	l.withContext(ctx).log(types.ErrorLevel, msg, fields...)
+}
+
+func (l *logger) WithFields(fields map[string]interface{}) types.Logger {
+	child := l.clone()
+	if len(fields) > 0 {
+		l.addFields(child.fields, fields)
+	}
+	return child
+}
+
+func (l *logger) WithField(key string, value interface{}) types.Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l *logger) With(args ...interface{}) types.Logger {
+	return kv.With(l, args)
+}
+
+func (l *logger) WithContext(ctx context.Context) types.Logger {
+	return l.withContext(ctx)
+}
+
+func (l *logger) withContext(ctx context.Context) *logger {
+	child := l.clone()
+	if id := logctx.GetCorrelationID(ctx); id != "" {
+		child.correlationID = id
+	}
+	if traceID, spanID := logctx.GetTraceContext(ctx); traceID != "" {
+		child.traceID, child.spanID = traceID, spanID
+	}
+	if ctx != nil {
+		if _, ok := ctx.Deadline(); ok {
+			child.deadlineCtx = ctx
+		}
+	}
+	return child
+}
+
+func (l *logger) WithCorrelationID(correlationID string) types.Logger {
+	child := l.clone()
+	child.correlationID = correlationID
+	return child
+}
+
+func (l *logger) WithPrefix(prefix string) types.Logger {
+	child := l.clone()
+	child.prefix = l.prefixKey(prefix)
+	return child
+}
+
+func (l *logger) WithNamespace(name string) types.Logger {
+	child := l.clone()
+	if name != "" {
+		child.namespace = append(l.namespace[:len(l.namespace):len(l.namespace)], name)
+	}
+	return child
+}
+
+func (l *logger) Named(name string) types.Logger {
+	child := l.clone()
+	if name != "" {
+		if l.name != "" {
+			name = l.name + "." + name
+		}
+		child.name = name
+		child.fields["logger"] = name
+	}
+	return child
+}
+
+func (l *logger) WithError(err error) types.Logger {
+	child := l.clone()
+	if err == nil {
+		return child
+	}
+	child.err = &types.LogError{Name: fmt.Sprintf("%T", err), Message: err.Error()}
+	last := child.err
+	for i := 0; i < types.MaxErrorCauses; i++ {
+		if err = errors.Unwrap(err); err == nil {
+			break
+		}
+		last.Cause = &types.LogError{Name: fmt.Sprintf("%T", err), Message: err.Error()}
+		last = last.Cause
+	}
+	return child
+}
+
+func (l *logger) WithStack() types.Logger {
+	child := l.clone()
+	child.stack = stack.Capture(1, 0)
+	return child
+}
+
+// clone returns a copy of l for deriving child loggers.
+func (l *logger) clone() *logger {
+	child := *l
+	child.fields = make(map[string]interface{}, len(l.fields))
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	child.level = &levelNode{parent: l.level}
+	return &child
+}
+
+// addFields adds fields to dst with the logger's prefix, under its
+// namespace. Nested maps are copied before being changed, as they may be
+// shared with other loggers.
+func (l *logger) addFields(dst, fields map[string]interface{}) {
+	for _, name := range l.namespace {
+		inner, _ := dst[name].(map[string]interface{})
+		next := make(map[string]interface{}, len(inner)+len(fields))
+		for k, v := range inner {
+			next[k] = v
+		}
+		dst[name] = next
+		dst = next
+	}
+	for k, v := range fields {
+		dst[l.prefixKey(k)] = v
+	}
+}
+
+func (l *logger) prefixKey(key string) string {
+	if l.prefix == "" {
+		return key
+	}
+	return l.prefix + "." + key
+}
+
+// Close is a no-op; the entries stay available.
+func (l *logger) Close() error {
+	return nil
+}
+
+func (l *logger) SetLevel(level types.LogLevel) {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+	l.level.level = level
+	l.level.set = true
+}
+
+func (l *logger) GetLevel() types.LogLevel {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+	n := l.level
+	for !n.set {
+		n = n.parent
+	}
+	return n.level
+}
+
+func (l *logger) enabled(level types.LogLevel) bool {
+	return level.Enabled(l.GetLevel())
+}
+
+func (l *logger) LogExternalCall(service, operation string, statusCode int, duration time.Duration, err error) {
+	level := types.InfoLevel
+	outcome := "success"
+	switch {
+	case err != nil || statusCode >= 500:
+		level = types.ErrorLevel
+		outcome = "failure"
+	case statusCode >= 400:
+		level = types.WarnLevel
+		outcome = "failure"
+	}
+
+	durationMs := float64(duration) / float64(time.Millisecond)
+	fields := map[string]interface{}{
+		"external.service":    service,
+		"external.operation":  operation,
+		"external.statusCode": statusCode,
+		"durationMs":          durationMs,
+		"outcome":             outcome,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	l.log(level, fmt.Sprintf("%s.%s %s in %.1fms", service, operation, outcome, durationMs), fields)
+}
+
+func (l *logger) LogFlagEvaluation(flag string, value interface{}, reason string) {
+	l.log(types.DebugLevel, "feature flag evaluated", map[string]interface{}{
+		"flag.name":   flag,
+		"flag.value":  value,
+		"flag.reason": reason,
+	})
+}
+
+func (l *logger) log(level types.LogLevel, msg string, fields ...map[string]interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+
+	all := make(map[string]interface{}, len(l.fields)+4)
+	for k, v := range l.fields {
+		all[k] = v
+	}
+	for _, f := range fields {
+		l.addFields(all, f)
+	}
+	if l.correlationID != "" {
+		all["correlationId"] = l.correlationID
+	}
+	if l.traceID != "" {
+		all["trace_id"] = l.traceID
+	}
+	if l.spanID != "" {
+		all["span_id"] = l.spanID
+	}
+	if l.stack != "" {
+		all["stack"] = l.stack
+	}
+	if l.deadlineCtx != nil {
+		deadline, _ := l.deadlineCtx.Deadline()
+		all["deadlineMs"] = time.Until(deadline).Milliseconds()
+		if l.deadlineCtx.Err() != nil {
+			all["ctxCancelled"] = true
+		}
+	}
+	entry := types.LogEntry{
+		Timestamp:     time.Now(),
+		Level:         level,
+		Message:       msg,
+		CorrelationID: l.correlationID,
+		Fields:        all,
+	}
+	if l.err != nil {
+		logErr := *l.err
+		entry.Error = &logErr
+	}
+	l.recorder.record(entry)
+}
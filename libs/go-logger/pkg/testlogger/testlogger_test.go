@@ -0,0 +1,138 @@
+package testlogger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	logctx "github.com/mateusmacedo/boyscout/go-logger/internal/context"
+	"github.com/mateusmacedo/boyscout/go-logger/pkg/types"
+)
+
+func TestRecordsMessageLevelAndMergedFields(t *testing.T) {
+	rec, log := New()
+
+	log.WithFields(map[string]interface{}{"service": "api"}).
+		WithPrefix("db").
+		Warn("slow query", map[string]interface{}{"ms": 120})
+
+	entry, ok := rec.LastEntry()
+	if !ok {
+		t.Fatal("Expected an entry")
+	}
+	if entry.Level != types.WarnLevel || entry.Message != "slow query" {
+		t.Errorf("Expected warn \"slow query\", got %s %q", entry.Level, entry.Message)
+	}
+	if entry.Fields["service"] != "api" || entry.Fields["db.ms"] != 120 {
+		t.Errorf("Expected merged fields, got %v", entry.Fields)
+	}
+	if !rec.HasEntry(types.WarnLevel, "slow") || rec.HasEntry(types.ErrorLevel, "slow") {
+		t.Error("Expected HasEntry to match on level and message substring")
+	}
+}
+
+func TestRecordsCorrelationAndError(t *testing.T) {
+	rec, log := New()
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-1")
+
+	log.WithContext(ctx).WithError(errors.New("boom")).Error("failed")
+
+	entry, _ := rec.LastEntry()
+	if entry.CorrelationID != "cid-1" || entry.Fields["correlationId"] != "cid-1" {
+		t.Errorf("Expected correlation ID cid-1, got %+v", entry)
+	}
+	if entry.Error == nil || entry.Error.Message != "boom" {
+		t.Errorf("Expected error boom, got %+v", entry.Error)
+	}
+}
+
+func TestInfoCtx(t *testing.T) {
+	rec, log := New()
+	ctx := logctx.WithCorrelationID(context.Background(), "cid-2")
+
+	log.InfoCtx(ctx, "handled")
+	log.Info("plain")
+
+	got := rec.Entries()
+	if len(got) != 2 || got[0].CorrelationID != "cid-2" || got[1].CorrelationID != "" {
+		t.Errorf("Expected the correlation ID on the first entry only, got %v", got)
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	rec, log := New()
+	log.SetLevel(types.InfoLevel)
+	child := log.WithFields(nil)
+
+	child.Debug("hidden")
+	child.LogFlagEvaluation("beta", true, "default")
+	child.Info("shown")
+
+	if got := rec.Entries(); len(got) != 1 || got[0].Message != "shown" {
+		t.Errorf("Expected only the info entry, got %v", got)
+	}
+
+	child.SetLevel(types.DebugLevel)
+	if log.GetLevel() != types.InfoLevel {
+		t.Errorf("Expected parent level to be unchanged, got %s", log.GetLevel())
+	}
+}
+
+func TestLastEntryAndReset(t *testing.T) {
+	rec, log := New()
+	if _, ok := rec.LastEntry(); ok {
+		t.Error("Expected no entry before logging")
+	}
+
+	log.Info("a")
+	rec.Reset()
+
+	if got := len(rec.Entries()); got != 0 {
+		t.Errorf("Expected no entries after Reset, got %d", got)
+	}
+}
+
+func TestWithNamespace(t *testing.T) {
+	rec, log := New()
+
+	log.WithNamespace("db").WithFields(map[string]interface{}{"rows": 10}).Info("query", map[string]interface{}{"ms": 5})
+
+	entry, _ := rec.LastEntry()
+	if db, ok := entry.Fields["db"].(map[string]interface{}); !ok || db["rows"] != 10 || db["ms"] != 5 {
+		t.Errorf("Expected fields nested under db, got %v", entry.Fields)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	rec, log := New()
+
+	log.Named("db").Named("query").Info("select")
+
+	entry, _ := rec.LastEntry()
+	if entry.Fields["logger"] != "db.query" {
+		t.Errorf("Expected logger 'db.query', got %v", entry.Fields["logger"])
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	rec, log := New()
+
+	log.WithStack().Warn("unexpected")
+
+	entry, _ := rec.LastEntry()
+	if stack, _ := entry.Fields["stack"].(string); !strings.Contains(stack, "testlogger.TestWithStack") {
+		t.Errorf("Expected the stack of the caller, got %q", stack)
+	}
+}
+
+func TestNoOpDerivationsDerive(t *testing.T) {
+	_, log := New()
+
+	for _, child := range []types.Logger{log.WithError(nil), log.WithNamespace(""), log.With(), log.WithFields(nil)} {
+		child.SetLevel(types.ErrorLevel)
+		if log.GetLevel() != types.TraceLevel {
+			t.Fatalf("Expected the parent level to be unaffected, got '%s'", log.GetLevel())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+// ECSFieldKeyMap returns a FieldKeyMap renaming the standard keys to their
+// Elastic Common Schema names. durationMs is left as it is, since ECS
+// event.duration is in nanoseconds.
+func ECSFieldKeyMap() map[string]string {
+	return map[string]string{
+		"timestamp":       "@timestamp",
+		"level":           "log.level",
+		"logger":          "log.logger",
+		"correlationId":   "labels.correlation_id",
+		"trace_id":        "trace.id",
+		"span_id":         "span.id",
+		"service":         "service.name",
+		"environment":     "service.environment",
+		"version":         "service.version",
+		"hostname":        "host.hostname",
+		"pid":             "process.pid",
+		"http.method":     "http.request.method",
+		"http.path":       "url.path",
+		"http.statusCode": "http.response.status_code",
+	}
+}
+
+// GELFFieldKeyMap returns a FieldKeyMap for Graylog's GELF: the message
+// becomes short_message and the other standard keys additional fields,
+// prefixed with an underscore.
+func GELFFieldKeyMap() map[string]string {
+	return map[string]string{
+		"message":       "short_message",
+		"hostname":      "host",
+		"timestamp":     "_timestamp",
+		"level":         "_level",
+		"logger":        "_logger",
+		"correlationId": "_correlation_id",
+		"trace_id":      "_trace_id",
+		"span_id":       "_span_id",
+		"service":       "_service",
+		"environment":   "_environment",
+		"version":       "_service_version",
+		"durationMs":    "_duration_ms",
+		"pid":           "_pid",
+	}
+}
@@ -0,0 +1,374 @@
+// Package types holds the public contracts shared by the logger, its
+// adapters and user supplied extensions.
+package types
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// LogLevel is the severity of a log line.
+type LogLevel string
+
+const (
+	TraceLevel LogLevel = "trace"
+	DebugLevel LogLevel = "debug"
+	InfoLevel  LogLevel = "info"
+	WarnLevel  LogLevel = "warn"
+	ErrorLevel LogLevel = "error"
+	FatalLevel LogLevel = "fatal"
+)
+
+// Severity ranks l from 0 for trace to 5 for fatal. Unknown levels rank
+// as info, the level a logger falls back to for them.
+func (l LogLevel) Severity() int {
+	switch l {
+	case TraceLevel:
+		return 0
+	case DebugLevel:
+		return 1
+	case WarnLevel:
+		return 3
+	case ErrorLevel:
+		return 4
+	case FatalLevel:
+		return 5
+	default:
+		return 2
+	}
+}
+
+// Enabled reports whether lines at l are emitted when min is the minimum
+// level, i.e. whether l is at least as severe as min.
+func (l LogLevel) Enabled(min LogLevel) bool {
+	return l.Severity() >= min.Severity()
+}
+
+// Logger is the structured logger exposed to applications.
+type Logger interface {
+	Trace(msg string, fields ...map[string]interface{})
+	Debug(msg string, fields ...map[string]interface{})
+	Info(msg string, fields ...map[string]interface{})
+	Warn(msg string, fields ...map[string]interface{})
+	Error(msg string, fields ...map[string]interface{})
+	// Fatal logs msg, closes the logger as Close does so buffered or
+	// asynchronous sinks deliver the line, then exits with status 1.
+	Fatal(msg string, fields ...map[string]interface{})
+
+	// DebugCtx, InfoCtx, WarnCtx and ErrorCtx log like their plain
+	// counterparts, adding the correlation ID, trace context and deadline
+	// stored in ctx to that one line, as WithContext(ctx) would, without
+	// deriving a logger.
+	DebugCtx(ctx context.Context, msg string, fields ...map[string]interface{})
+	InfoCtx(ctx context.Context, msg string, fields ...map[string]interface{})
+	WarnCtx(ctx context.Context, msg string, fields ...map[string]interface{})
+	ErrorCtx(ctx context.Context, msg string, fields ...map[string]interface{})
+
+	// WithFields returns a child logger that adds fields to every line.
+	WithFields(fields map[string]interface{}) Logger
+	// WithField returns a child logger that adds a single field to every
+	// line, without building a map at the call site.
+	WithField(key string, value interface{}) Logger
+	// With returns a child logger that adds fields given as alternating
+	// keys and values, like slog: With("orderId", 42, "status", "paid").
+	// A trailing key without a value is dropped and reported with a
+	// warning.
+	With(args ...interface{}) Logger
+	// WithContext returns a child logger carrying the correlation ID and
+	// trace context stored in ctx. When ctx has a deadline, every line also
+	// reports deadlineMs, the milliseconds left until it, and ctxCancelled
+	// once ctx is done.
+	WithContext(ctx context.Context) Logger
+	// WithCorrelationID returns a child logger carrying the given correlation ID.
+	WithCorrelationID(correlationID string) Logger
+	// WithPrefix returns a child logger that prefixes the keys of fields
+	// added afterwards with "prefix.", keeping a flat dotted key space
+	// (e.g. cache.hits) instead of nesting.
+	WithPrefix(prefix string) Logger
+	// WithNamespace returns a child logger that nests the fields added
+	// afterwards under name, like slog groups: WithNamespace("db") then
+	// WithFields({"rows": 10}) logs {"db":{"rows":10}}. Namespaces stack.
+	WithNamespace(name string) Logger
+	// Named returns a child logger for a subsystem. Its lines carry a
+	// logger field with the dotted chain of names, e.g. Named("db") then
+	// Named("query") logs logger: "db.query". Like every derived logger it
+	// follows its parent's level until SetLevel is called on it, after
+	// which changes to the parent no longer affect it.
+	Named(name string) Logger
+	// WithError returns a child logger that attaches err as a structured
+	// LogError (type name, message and stack when available) to every line.
+	WithError(err error) Logger
+	// WithStack returns a child logger that adds the stack of the code
+	// calling WithStack, as a stack field of readable frames, to every
+	// line. Use it to trace unexpected but non-fatal code paths.
+	WithStack() Logger
+
+	// SetLevel changes the minimum level emitted by this logger and by the
+	// loggers derived from it that have not set their own level. Parents
+	// and siblings are not affected.
+	SetLevel(level LogLevel)
+	// GetLevel returns the minimum level currently emitted.
+	GetLevel() LogLevel
+
+	// LogExternalCall logs a call to a third-party dependency using the
+	// standardized external.* fields. The level is derived from the status
+	// code and error: 5xx or a non-nil error logs at error, 4xx at warn and
+	// anything else at info.
+	LogExternalCall(service, operation string, statusCode int, duration time.Duration, err error)
+	// Close flushes and closes the sink and output shared by this logger
+	// and every logger derived from it, so buffered lines are not lost on
+	// exit. Only the first call has an effect. Loggers without a sink or a
+	// flushable output return nil.
+	Close() error
+
+	// LogFlagEvaluation logs a feature flag decision at debug with the
+	// flag.name, flag.value and flag.reason fields. It costs nothing when
+	// debug is disabled.
+	LogFlagEvaluation(flag string, value interface{}, reason string)
+}
+
+// LogEntry is the structured record handed to sinks.
+type LogEntry struct {
+	Timestamp     time.Time     `json:"timestamp"`
+	Level         LogLevel      `json:"level"`
+	Message       string        `json:"message,omitempty"`
+	Scope         LogScope      `json:"scope"`
+	Outcome       string        `json:"outcome"`
+	Args          []interface{} `json:"args,omitempty"`
+	Result        interface{}   `json:"result,omitempty"`
+	Error         *LogError     `json:"error,omitempty"`
+	CorrelationID string        `json:"correlationId,omitempty"`
+	DurationMs    float64       `json:"durationMs"`
+	// Sequence numbers the entry when LogOptions.Sequence or
+	// LogMethodOptions.Sequence is set, so consumers can order entries
+	// across sinks and spot the ones that went missing.
+	Sequence uint64 `json:"sequence,omitempty"`
+	// Fields holds the structured fields of the line, already redacted.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// LogScope identifies the code that produced an entry.
+type LogScope struct {
+	ClassName  string `json:"className,omitempty"`
+	MethodName string `json:"methodName"`
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line,omitempty"`
+}
+
+// Sequence hands out increasing entry sequence numbers, starting at 1. The
+// zero value is ready to use and it is safe for concurrent use. Share one
+// Sequence between the loggers and decorators writing to the same sink so
+// that sink sees a single sequence.
+type Sequence struct {
+	n atomic.Uint64
+}
+
+// Next returns the next sequence number.
+func (s *Sequence) Next() uint64 {
+	return s.n.Add(1)
+}
+
+// LogError describes an error attached to an entry.
+type LogError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Stack   string `json:"stack,omitempty"`
+	// Cause describes the error wrapped by this one, as returned by
+	// errors.Unwrap, so the whole chain built with fmt.Errorf and %w is
+	// logged. Chains are cut after MaxErrorCauses causes.
+	Cause *LogError `json:"cause,omitempty"`
+}
+
+// MaxErrorCauses bounds the wrapped errors recorded in a LogError chain.
+const MaxErrorCauses = 10
+
+// Sink receives log entries, e.g. to ship them to a backend. Write is
+// called concurrently when the logger is shared between goroutines.
+type Sink interface {
+	Write(entry LogEntry) error
+	// Close flushes pending entries and releases resources.
+	Close() error
+}
+
+// SinkOptions configures buffering for sinks that write asynchronously.
+type SinkOptions struct {
+	// EnableBackpressure makes writers block while the buffer is full
+	// instead of dropping the oldest entry.
+	EnableBackpressure bool
+	// BufferSize is the number of entries buffered before a flush.
+	BufferSize int
+	// FlushInterval is the maximum time an entry waits before being flushed.
+	FlushInterval time.Duration
+}
+
+// Formatter renders an entry as a single line, without the trailing
+// newline.
+type Formatter interface {
+	Format(entry LogEntry) ([]byte, error)
+}
+
+// FormatterFunc adapts a function to the Formatter interface.
+type FormatterFunc func(entry LogEntry) ([]byte, error)
+
+// Format calls f(entry).
+func (f FormatterFunc) Format(entry LogEntry) ([]byte, error) {
+	return f(entry)
+}
+
+// Redactor masks sensitive data before it is logged.
+type Redactor interface {
+	Redact(value interface{}) interface{}
+}
+
+// Backend selects how a logger renders its lines.
+type Backend string
+
+const (
+	// LogrusBackend formats lines with logrus. It supports Formatter and
+	// SigningKey.
+	LogrusBackend Backend = "logrus"
+	// JSONBackend encodes lines directly with encoding/json, allocating
+	// less than LogrusBackend. Lines have the same shape as the default
+	// logrus JSON; Formatter and SortFields are ignored. It supports
+	// JSONFormat and LogfmtFormat; TextFormat uses logrus, and so does
+	// SigningKey, which NewLoggerChecked rejects with this backend.
+	JSONBackend Backend = "json"
+)
+
+// Format selects the encoding of the lines written to Output.
+type Format string
+
+const (
+	// JSONFormat writes a JSON object per line. It is the default.
+	JSONFormat Format = "json"
+	// TextFormat writes the lines of logrus' TextFormatter.
+	TextFormat Format = "text"
+	// LogfmtFormat writes space separated key=value pairs: the timestamp,
+	// level and message first, then the fields sorted by key, with nested
+	// objects such as the error flattened into dotted keys. Values with
+	// spaces, quotes or control characters are quoted.
+	LogfmtFormat Format = "logfmt"
+)
+
+// RateLimit caps how often the same line is emitted. Lines are keyed by
+// level and message; beyond MaxPerInterval lines per Interval they are
+// suppressed, and a single "N messages suppressed" line is written at the
+// end of the interval. Fatal lines are never suppressed.
+type RateLimit struct {
+	MaxPerInterval int
+	Interval       time.Duration
+}
+
+// LogOptions configures a Logger.
+type LogOptions struct {
+	// Level is the minimum level emitted. Defaults to info.
+	Level LogLevel
+	// Service, Environment and Version are attached to every line when set.
+	Service     string
+	Environment string
+	Version     string
+
+	// Sequence, when set, numbers every line written by this logger and
+	// its children, in LogEntry.Sequence and the sequence field. Lines
+	// dropped by rate limiting are not numbered; lines dropped by hooks or
+	// a full async sink leave gaps. Nil, the default, skips the counter.
+	Sequence *Sequence
+
+	// IncludeRuntimeInfo adds the hostname, pid and goVersion fields to
+	// every line. They are read once, when the logger is created.
+	IncludeRuntimeInfo bool
+	// StaticFields are added to every line, e.g. Kubernetes pod and node
+	// names read from the environment. Like the runtime info they are
+	// trusted configuration: they are not redacted nor affected by
+	// WithPrefix and WithNamespace, and fields logged with the same key
+	// take precedence.
+	StaticFields map[string]interface{}
+	// FieldKeyMap renames keys of the written lines, e.g. "timestamp" to
+	// "@timestamp", to match the schema a log pipeline expects. It covers
+	// the timestamp, level and message keys and the fields, including
+	// runtime info and static fields; redaction still sees the original
+	// keys. Sinks and Formatters receive the renamed Fields. See
+	// ECSFieldKeyMap and GELFFieldKeyMap.
+	FieldKeyMap map[string]string
+
+	// Redactor masks sensitive field values. Defaults to the default
+	// redactor rules; when Environment is "development" the default only
+	// annotates suspected PII (e.g. "[PII:email]") instead of masking it.
+	// Keep a reference to a *redactor.Redactor given here to change its
+	// rules at runtime with Reconfigure.
+	Redactor Redactor
+	// DisableRedaction logs fields, error messages and stacks exactly as
+	// given, skipping the redaction pass and its cost. Only use it for
+	// loggers fed with data known to be free of secrets and PII, such as
+	// internal telemetry: nothing masks a value that slips in later. It
+	// takes precedence over Redactor, and is equivalent to setting
+	// Redactor to redactor.NopRedactor().
+	DisableRedaction bool
+	// MaxStringLength truncates string field values longer than this many
+	// bytes, nested ones included, appending "…(truncated N bytes)". It
+	// applies after redaction, so masks are never cut, and not to the
+	// message, runtime info and static fields. Zero means no limit.
+	MaxStringLength int
+	// RedactionSummary adds a redactionSummary field with the number of
+	// keys and characters masked whenever redaction occurred.
+	RedactionSummary bool
+
+	// SigningKey enables tamper-evident output: every line gets a
+	// signature field holding an HMAC-SHA256 keyed with SigningKey and
+	// chained with the previous line's signature, so modified or deleted
+	// lines can be detected with VerifySignedLines.
+	SigningKey []byte
+
+	// SortFields writes the keys of every line in a stable order:
+	// timestamp, level, message and service first, then the other fields
+	// alphabetically. It is always enabled when Environment is
+	// "production". Sinks receive fields as a map and are not affected.
+	SortFields bool
+
+	// IncludeCaller records the function, file and line of the code that
+	// called the logger in the entry scope. It is off by default because
+	// walking the stack on every line is costly.
+	IncludeCaller bool
+	// MaxStackFrames caps the frames WithStack captures. Defaults to 32.
+	MaxStackFrames int
+
+	// RateLimit suppresses repeated lines. Disabled when zero.
+	RateLimit RateLimit
+	// Now returns the time stamped on each line and used to compute
+	// deadlineMs. Defaults to time.Now; tests can inject a fake clock for
+	// deterministic output. Rate limiting windows keep using the real
+	// clock.
+	Now func() time.Time
+
+	// Output receives the lines of this logger. Defaults to os.Stdout.
+	Output io.Writer
+	// Format is the encoding of the lines written to Output. Defaults to
+	// JSONFormat. SortFields only applies to JSONFormat, and SigningKey
+	// requires it.
+	Format Format
+	// Backend renders the lines written to Output. Defaults to
+	// LogrusBackend.
+	Backend Backend
+	// Formatter renders the lines written to Output instead of the
+	// default JSON, taking precedence over SortFields. SigningKey requires
+	// it to produce JSON objects.
+	Formatter Formatter
+
+	// Hooks run in order on every entry that passed level filtering and
+	// rate limiting, after its fields were assembled and redacted and
+	// before it is written. A hook may change the entry, e.g. to enrich or
+	// sanitize it, and returning false drops it without running the
+	// remaining hooks.
+	// Values added by hooks are not redacted.
+	Hooks []func(entry *LogEntry) bool
+
+	// Sink receives every entry instead of Output when set. SigningKey
+	// only applies to Output.
+	Sink Sink
+	// OnSinkError is called with the entry and the error whenever Sink
+	// fails to write it, e.g. to count failures or fall back to a local
+	// file. Defaults to a one-line diagnostic on stderr.
+	OnSinkError func(entry LogEntry, err error)
+}
@@ -0,0 +1,24 @@
+package types
+
+import "testing"
+
+func TestLogLevelSeverity(t *testing.T) {
+	levels := []LogLevel{TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel}
+	for i, level := range levels {
+		if got := level.Severity(); got != i {
+			t.Errorf("Expected %s to have severity %d, got %d", level, i, got)
+		}
+	}
+	if got := LogLevel("verbose").Severity(); got != InfoLevel.Severity() {
+		t.Errorf("Expected unknown levels to rank as info, got %d", got)
+	}
+}
+
+func TestLogLevelEnabled(t *testing.T) {
+	if !ErrorLevel.Enabled(WarnLevel) || !WarnLevel.Enabled(WarnLevel) {
+		t.Error("Expected levels at or above the minimum to be enabled")
+	}
+	if DebugLevel.Enabled(InfoLevel) {
+		t.Error("Expected debug to be disabled at info")
+	}
+}